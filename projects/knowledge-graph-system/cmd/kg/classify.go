@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/importer"
+	"github.com/spf13/cobra"
+)
+
+// newClassifyCmd builds `kg classify <path>`
+func newClassifyCmd() *cobra.Command {
+	opts := importer.DefaultImportOptions()
+
+	cmd := &cobra.Command{
+		Use:   "classify <path>",
+		Short: "Show how files would be classified, and which rule decided",
+		Long: `Classify a file, or the importable files of a directory, without importing
+anything. For each file prints the visibility and source class it would get,
+the classification rule that fired and what matched, and any personal data or
+secrets found in the content (which keep a file from being public).
+
+Rules come from --policy, KG_CLASSIFICATION_POLICY or the organization's policy
+file (~/.config/kg/policies/<KG_ORG>.yaml), falling back to the built-in rules.`,
+		Example: `  kg classify docs/
+  kg classify notes/meeting.md --policy acme-policy.yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := filepath.Abs(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid path: %w", err)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+
+			policyFile, err := configureClassification()
+			if err != nil {
+				return err
+			}
+
+			// A single file is classified whatever its type
+			sources := []importer.ImportSource{{FilePath: path}}
+			if info.IsDir() {
+				opts.RootDir = path
+				if sources, err = importer.Discover(opts); err != nil {
+					return err
+				}
+			}
+
+			results := make([]classificationJSON, 0, len(sources))
+			for i := range sources {
+				result, err := importer.ExplainClassification(&sources[i], opts.Visibility, nil)
+				if err != nil {
+					return fmt.Errorf("failed to classify %s: %w", sources[i].FilePath, err)
+				}
+				results = append(results, newClassificationJSON(sources[i], result))
+			}
+
+			if jsonOutput {
+				return printJSON(results)
+			}
+
+			if policyFile == "" {
+				policyFile = "built-in rules"
+			}
+			fmt.Printf("Policy: %s\n\n", policyFile)
+
+			counts := make(map[string]int)
+			for _, r := range results {
+				fmt.Printf("%s\n", r.File)
+				fmt.Printf("   %s (%s) by rule %s", r.Visibility, r.SourceClass, r.Rule)
+				if r.Matched != "" {
+					fmt.Printf(": %s", r.Matched)
+				}
+				fmt.Println()
+				if r.Explanation != "" {
+					fmt.Printf("   %s\n", r.Explanation)
+				}
+				if len(r.PII) > 0 {
+					found := make([]string, 0, len(r.PII))
+					for _, f := range r.PII {
+						found = append(found, fmt.Sprintf("%d %s", f.Count, f.Kind))
+					}
+					fmt.Printf("   Sensitive data: %s\n", strings.Join(found, ", "))
+				}
+				counts[r.Visibility]++
+			}
+
+			visibilities := make([]string, 0, len(counts))
+			for v := range counts {
+				visibilities = append(visibilities, v)
+			}
+			sort.Strings(visibilities)
+			fmt.Printf("\n%d files:", len(results))
+			for _, v := range visibilities {
+				fmt.Printf(" %d %s", counts[v], v)
+			}
+			fmt.Println()
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.FileTypes, "types", opts.FileTypes, "File types to classify in a directory (see kg import formats)")
+	cmd.Flags().StringSliceVar(&opts.ExcludePattern, "exclude", opts.ExcludePattern, "Exclude patterns (glob)")
+	cmd.Flags().StringVar(&opts.Visibility, "visibility", opts.Visibility, "Visibility when no rule matches (auto, public, anonymized, org-private, individual)")
+
+	return cmd
+}
+
+type classificationJSON struct {
+	File        string   `json:"file"`
+	FileType    string   `json:"file_type,omitempty"`
+	Visibility  string   `json:"visibility"`
+	SourceClass string   `json:"source_class"`
+	Rule        string   `json:"rule"`
+	Matched     string   `json:"matched,omitempty"`
+	Explanation string   `json:"explanation,omitempty"`
+	SourceURLs  []string `json:"source_urls,omitempty"`
+
+	ContainsPII bool                  `json:"contains_pii"`
+	PII         []importer.PIIFinding `json:"pii,omitempty"`
+}
+
+func newClassificationJSON(source importer.ImportSource, result importer.ClassificationResult) classificationJSON {
+	out := classificationJSON{
+		File:        source.FilePath,
+		FileType:    source.FileType,
+		Visibility:  source.Visibility,
+		SourceClass: source.SourceClass,
+		Rule:        result.Rule,
+		Matched:     result.Matched,
+		Explanation: result.Explanation,
+		ContainsPII: source.ContainsPII,
+		PII:         result.PII,
+	}
+	if urls, ok := source.Metadata["source_urls"].([]string); ok {
+		out.SourceURLs = urls
+	}
+	return out
+}
+
+// configureClassification applies the git remote settings from the environment
+// and loads the classification policy, returning the policy file used ("" for
+// the built-in rules)
+func configureClassification() (string, error) {
+	importer.SetGitRemotePolicy(gitRemotePolicyFromEnv())
+
+	path := classificationPolicyPath()
+	if path == "" {
+		return "", nil
+	}
+
+	policy, err := importer.LoadClassificationPolicy(path)
+	if err != nil {
+		return "", err
+	}
+	org := getEnv("KG_ORG", "personal")
+	if policy.Organization != "" && policy.Organization != org {
+		return "", fmt.Errorf("classification policy %s is for organization %q, not %q (set KG_ORG)", path, policy.Organization, org)
+	}
+
+	importer.SetClassificationPolicy(policy)
+	return path, nil
+}
+
+// classificationPolicyPath returns the policy file from --policy or
+// KG_CLASSIFICATION_POLICY, else the organization's file under the user config
+// directory if it exists
+func classificationPolicyPath() string {
+	if policyPath != "" {
+		return policyPath
+	}
+	if path := os.Getenv("KG_CLASSIFICATION_POLICY"); path != "" {
+		return path
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	org := getEnv("KG_ORG", "personal")
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		path := filepath.Join(dir, "kg", "policies", org+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/evaluation"
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// newEvalCmd builds `kg eval <queries.yaml>`
+func newEvalCmd() *cobra.Command {
+	var ranking types.RankingOptions
+	var boosts, strategies []string
+	k := 10
+
+	cmd := &cobra.Command{
+		Use:   "eval <queries.yaml>",
+		Short: "Compare search ranking strategies on a labeled query set",
+		Long: `Run every query of a labeled query set with each ranking strategy and report
+recall@K, mean reciprocal rank (MRR), nDCG@K and latency per strategy.
+
+The query set lists, for each query, the IDs of the blocks a good search
+returns:
+
+  queries:
+    - query: how do we rotate database credentials
+      relevant:
+        - 3f2b9c9e-8d1a-4c55-9a61-0f3e2d7b1c44`,
+		Example: `  kg eval queries.yaml
+  kg eval queries.yaml --strategy rrf --strategy linear --k 5 --half-life 90`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			set, err := evaluation.LoadQuerySet(args[0])
+			if err != nil {
+				return err
+			}
+			if ranking.ProjectBoosts, err = parseProjectBoosts(boosts); err != nil {
+				return err
+			}
+
+			var compared []evaluation.Strategy
+			for _, name := range strategies {
+				strategy := ranking
+				strategy.Strategy = strings.TrimSpace(name)
+				if err := strategy.Validate(); err != nil {
+					return err
+				}
+				compared = append(compared, evaluation.Strategy{Name: strategy.Strategy, Ranking: strategy})
+			}
+
+			kg, err := openDB()
+			if err != nil {
+				return err
+			}
+			defer kg.Close()
+
+			ctx := context.Background()
+			var base types.SearchOptions
+			if base.Caller, err = currentCaller(ctx, kg); err != nil {
+				return err
+			}
+
+			reports, err := evaluation.Evaluate(ctx, kg, set, compared, base, k)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(reports)
+			}
+
+			fmt.Printf("%d queries, top %d results\n\n", len(set.Queries), k)
+			fmt.Printf("%-10s %9s %7s %7s %9s\n", "STRATEGY", "RECALL@K", "MRR", "NDCG@K", "LATENCY")
+			for _, report := range reports {
+				fmt.Printf("%-10s %9.3f %7.3f %7.3f %9s\n", report.Strategy, report.Recall, report.MRR, report.NDCG,
+					report.MeanLatency.Round(time.Millisecond))
+			}
+
+			if verbose {
+				for _, report := range reports {
+					fmt.Printf("\n%s:\n", report.Strategy)
+					for _, q := range report.Queries {
+						fmt.Printf("  %.3f recall  %.3f rr  %.3f ndcg  %s\n", q.Recall, q.Reciprocal, q.NDCG, q.Query)
+					}
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&strategies, "strategy", []string{types.RankingRRF, types.RankingLinear}, "Ranking strategy to evaluate (repeatable)")
+	cmd.Flags().IntVar(&k, "k", k, "Number of results scored per query")
+	cmd.Flags().Float64Var(&ranking.RRFK, "rrf-k", types.DefaultRRFK, "Rank offset for reciprocal rank fusion")
+	cmd.Flags().Float64Var(&ranking.RecencyHalfLifeDays, "half-life", 0, "Halve the relevance of blocks every this many days since creation (0 = no decay)")
+	cmd.Flags().StringArrayVar(&boosts, "boost-project", nil, "Multiply the relevance of a project's blocks: <project-id>=<factor> (repeatable)")
+
+	return cmd
+}
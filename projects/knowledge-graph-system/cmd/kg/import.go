@@ -0,0 +1,405 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/importer"
+	"github.com/TheGenXCoder/knowledge-graph/internal/pipeline"
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// newImportCmd builds `kg import <dir>` and its report subcommands
+func newImportCmd() *cobra.Command {
+	opts := importer.DefaultImportOptions()
+	var inference inferenceFlags
+
+	cmd := &cobra.Command{
+		Use:   "import <directory>",
+		Short: "Import conversation logs, specs and docs from a directory",
+		Example: `  kg import conversation-logs/ --dry-run
+  kg import . --types specs,docs --exclude "drafts/*"
+  kg import external-docs/ --visibility public
+  kg import client-notes/ --visibility anonymized`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootDir, err := filepath.Abs(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid directory: %w", err)
+			}
+
+			switch opts.Visibility {
+			case "auto", "public", "anonymized", "org-private", "individual":
+			default:
+				return fmt.Errorf("invalid visibility %q (expected auto, public, anonymized, org-private or individual)", opts.Visibility)
+			}
+
+			opts.RootDir = rootDir
+			opts.Owner = currentUser()
+			opts.Verbose = verbose
+			opts.ShowProgress = !jsonOutput
+			if opts.Inference, err = inference.options(cmd); err != nil {
+				return err
+			}
+
+			if _, err := configureClassification(); err != nil {
+				return err
+			}
+
+			kg, err := openDB()
+			if err != nil {
+				return err
+			}
+			defer kg.Close()
+
+			// Ctrl-C stops the worker pools; unfinished batches are retried next run
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			report, err := importer.RunImport(ctx, kg, opts)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(newImportReportJSON(report, opts.DryRun))
+			}
+
+			if len(report.Errors) > 0 && verbose {
+				fmt.Println("\nErrors:")
+				for _, e := range report.Errors {
+					fmt.Printf("  [%s] %s: %s: %v\n", e.Stage, e.Source.FilePath, e.Message, e.Error)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Preview import without making changes")
+	cmd.Flags().BoolVar(&opts.Resume, "resume", false, "Skip files already imported and recover batches left in-progress")
+	cmd.Flags().StringSliceVar(&opts.FileTypes, "types", opts.FileTypes, "File types to import (logs, specs, docs, working, chatgpt, transcripts, git, all; see kg import formats)")
+	cmd.Flags().StringSliceVar(&opts.ExcludePattern, "exclude", opts.ExcludePattern, "Exclude patterns (glob)")
+	cmd.Flags().StringVar(&opts.Visibility, "visibility", opts.Visibility, "Override visibility (auto, public, anonymized, org-private, individual)")
+	cmd.Flags().BoolVar(&opts.RedactPII, "redact-pii", false, "Replace detected personal data and secrets with [REDACTED:<kind>] before embedding")
+	cmd.Flags().BoolVar(&opts.Redact, "redact", false, "Apply the classification policy's redaction rules to blocks imported as public")
+	cmd.Flags().IntVar(&opts.ParseWorkers, "parse-workers", opts.ParseWorkers, "Concurrent file parsers")
+	cmd.Flags().IntVar(&opts.ChunkWorkers, "chunk-workers", opts.ChunkWorkers, "Concurrent chunking/dedup workers")
+	cmd.Flags().IntVar(&opts.EmbedWorkers, "embed-workers", opts.EmbedWorkers, "Concurrent embedding requests")
+	cmd.Flags().IntVar(&opts.BatchSize, "batch-size", opts.BatchSize, "Texts per embedding request")
+	cmd.Flags().IntVar(&opts.PreviewCount, "preview", opts.PreviewCount, "Number of sample blocks shown in dry-run")
+	inference.register(cmd)
+
+	cmd.AddCommand(newImportStatsCmd())
+	cmd.AddCommand(newImportHistoryCmd())
+	cmd.AddCommand(newImportFormatsCmd())
+
+	return cmd
+}
+
+// newImportStatsCmd builds `kg import stats`
+func newImportStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show import statistics",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kg, err := openDB()
+			if err != nil {
+				return err
+			}
+			defer kg.Close()
+
+			stats, err := pipeline.NewPipeline(kg, nil).GetImportStats(context.Background())
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(stats)
+			}
+
+			fmt.Println("Import Statistics:")
+			fmt.Println()
+			fmt.Printf("Total imports: %d\n", stats.TotalImports)
+			fmt.Printf("Total blocks: %d\n", stats.TotalBlocks)
+			if !stats.LastImport.IsZero() {
+				fmt.Printf("Last import: %s\n", stats.LastImport.Format("2006-01-02 15:04"))
+			}
+
+			if len(stats.ByType) > 0 {
+				fmt.Println("\nBy type:")
+				for importType, count := range stats.ByType {
+					fmt.Printf("  %s: %d blocks\n", importType, count)
+				}
+			}
+
+			if len(stats.ByVisibility) > 0 {
+				fmt.Println("\nBy visibility:")
+				for visibility, count := range stats.ByVisibility {
+					fmt.Printf("  %s: %d blocks\n", visibility, count)
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// newImportFormatsCmd builds `kg import formats`
+func newImportFormatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "formats",
+		Short: "List registered source formats and their file patterns",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			formats := importer.Formats()
+
+			if jsonOutput {
+				out := make([]formatJSON, 0, len(formats))
+				for _, f := range formats {
+					out = append(out, formatJSON{
+						FileType:    f.FileType,
+						Description: f.Description,
+						Types:       f.Types,
+						Patterns:    f.Patterns,
+						OptIn:       f.OptIn,
+					})
+				}
+				return printJSON(out)
+			}
+
+			fmt.Printf("Registered formats (%d):\n\n", len(formats))
+			for _, f := range formats {
+				fmt.Printf("%s\n", f.FileType)
+				if f.Description != "" {
+					fmt.Printf("   %s\n", f.Description)
+				}
+				fmt.Printf("   Patterns: %s\n", strings.Join(f.Patterns, ", "))
+				types := strings.Join(append([]string{f.FileType}, f.Types...), ", ")
+				if f.OptIn {
+					types += " (not included in all)"
+				}
+				fmt.Printf("   --types: %s\n", types)
+				fmt.Println()
+			}
+
+			return nil
+		},
+	}
+}
+
+type formatJSON struct {
+	FileType    string   `json:"file_type"`
+	Description string   `json:"description,omitempty"`
+	Types       []string `json:"types"`
+	Patterns    []string `json:"patterns"`
+	OptIn       bool     `json:"opt_in,omitempty"`
+}
+
+// newImportHistoryCmd builds `kg import history`
+func newImportHistoryCmd() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show recent imports",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kg, err := openDB()
+			if err != nil {
+				return err
+			}
+			defer kg.Close()
+
+			history, err := pipeline.NewPipeline(kg, nil).GetImportHistory(context.Background(), limit)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(history)
+			}
+
+			if len(history) == 0 {
+				fmt.Println("No imports yet.")
+				return nil
+			}
+
+			fmt.Printf("Recent imports (showing last %d):\n\n", len(history))
+			for i, entry := range history {
+				fmt.Printf("%d. %s\n", i+1, filepath.Base(entry.SourceFile))
+				fmt.Printf("   Imported: %s\n", entry.ImportedAt.Format("2006-01-02 15:04"))
+				fmt.Printf("   Type: %s\n", entry.ImportType)
+				fmt.Printf("   Blocks: %d\n", entry.BlockCount)
+				fmt.Printf("   Status: %s\n", entry.Status)
+				fmt.Printf("   Visibility: %s\n", entry.Visibility)
+				if entry.SourceClassification != "" {
+					fmt.Printf("   Classification: %s\n", entry.SourceClassification)
+				}
+				fmt.Println()
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 10, "Number of imports to show")
+
+	return cmd
+}
+
+// importReportJSON is the --json representation of an ImportReport
+type importReportJSON struct {
+	DryRun        bool              `json:"dry_run"`
+	StartedAt     time.Time         `json:"started_at"`
+	CompletedAt   time.Time         `json:"completed_at"`
+	SourcesFound  int               `json:"sources_found"`
+	SourcesParsed int               `json:"sources_parsed"`
+	BlocksCreated int               `json:"blocks_created"`
+	Inserted      int               `json:"inserted"`
+	Updated       int               `json:"updated"`
+	Appended      int               `json:"appended"`
+	Replaced      int               `json:"replaced"`
+	Linked        int               `json:"linked"`
+	Inferred      int               `json:"inferred"`
+	Skipped       int               `json:"skipped"`
+	Failed        int               `json:"failed"`
+	Decisions     []decisionJSON    `json:"decisions"`
+	Errors        []importErrorJSON `json:"errors"`
+
+	SourcesSkipped int             `json:"sources_skipped,omitempty"`
+	Recovered      []recoveredJSON `json:"recovered,omitempty"`
+}
+
+// inferenceFlags are the relationship inference flags of import and watch.
+// Flags left unset keep the KG_INFER_* configuration.
+type inferenceFlags struct {
+	signals     []string
+	similarity  float64
+	maxSimilar  int
+	fileOverlap float64
+}
+
+func (f *inferenceFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&f.signals, "infer", nil, "Relationship inference signals for new blocks (similarity, shared-files, continuation, references; all or none)")
+	cmd.Flags().Float64Var(&f.similarity, "infer-similarity", 0, "Minimum embedding similarity to relate blocks (default 0.85)")
+	cmd.Flags().IntVar(&f.maxSimilar, "infer-max-similar", 0, "Similar blocks related to each new block (default 5)")
+	cmd.Flags().Float64Var(&f.fileOverlap, "infer-file-overlap", 0, "Minimum share of modified files two sessions must have in common (default 0.3)")
+}
+
+// options applies the flags that were set to the KG_INFER_* configuration
+func (f *inferenceFlags) options(cmd *cobra.Command) (types.InferenceOptions, error) {
+	opts, err := inferenceFromEnv()
+	if err != nil {
+		return opts, err
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("infer") {
+		opts.Disabled, opts.Signals = parseInferenceSignals(f.signals)
+	}
+	if flags.Changed("infer-similarity") {
+		opts.SimilarityThreshold = f.similarity
+	}
+	if flags.Changed("infer-max-similar") {
+		opts.MaxSimilar = f.maxSimilar
+	}
+	if flags.Changed("infer-file-overlap") {
+		opts.FileOverlapThreshold = f.fileOverlap
+	}
+	return opts, opts.Validate()
+}
+
+type recoveredJSON struct {
+	SourceFile      string `json:"source_file"`
+	BatchID         string `json:"batch_id"`
+	Action          string `json:"action"`
+	BlocksKept      int    `json:"blocks_kept"`
+	BlocksAdded     int    `json:"blocks_added"`
+	BlocksDiscarded int    `json:"blocks_discarded"`
+	Reason          string `json:"reason"`
+}
+
+type decisionJSON struct {
+	Action     string `json:"action"`
+	Topic      string `json:"topic"`
+	SourceFile string `json:"source_file"`
+	Visibility string `json:"visibility,omitempty"`
+	Exchanges  int    `json:"exchanges"`
+	Reason     string `json:"reason"`
+}
+
+type importErrorJSON struct {
+	SourceFile string `json:"source_file"`
+	Stage      string `json:"stage"`
+	Message    string `json:"message"`
+	Error      string `json:"error,omitempty"`
+}
+
+func newImportReportJSON(report *importer.ImportReport, dryRun bool) importReportJSON {
+	out := importReportJSON{
+		DryRun:        dryRun,
+		StartedAt:     report.StartedAt,
+		CompletedAt:   report.CompletedAt,
+		SourcesFound:  report.SourcesFound,
+		SourcesParsed: report.SourcesParsed,
+		BlocksCreated: report.BlocksCreated,
+		Inserted:      report.Inserted,
+		Updated:       report.Updated,
+		Appended:      report.Appended,
+		Replaced:      report.Replaced,
+		Linked:        report.Linked,
+		Inferred:      report.Inferred,
+		Skipped:       report.Skipped,
+		Failed:        report.Failed,
+		Decisions:     make([]decisionJSON, 0, len(report.Decisions)),
+		Errors:        make([]importErrorJSON, 0, len(report.Errors)),
+
+		SourcesSkipped: report.SourcesSkipped,
+	}
+
+	for _, r := range report.Recovered {
+		out.Recovered = append(out.Recovered, recoveredJSON{
+			SourceFile:      r.SourceFile,
+			BatchID:         r.BatchID.String(),
+			Action:          r.Action,
+			BlocksKept:      r.BlocksKept,
+			BlocksAdded:     r.BlocksAdded,
+			BlocksDiscarded: r.BlocksDiscarded,
+			Reason:          r.Reason,
+		})
+	}
+
+	for _, d := range report.Decisions {
+		out.Decisions = append(out.Decisions, decisionJSON{
+			Action:     d.Action,
+			Topic:      d.PreBlock.Topic,
+			SourceFile: d.PreBlock.SourceFile,
+			Visibility: d.PreBlock.Visibility,
+			Exchanges:  len(d.PreBlock.Exchanges),
+			Reason:     d.Reason,
+		})
+	}
+
+	for _, e := range report.Errors {
+		entry := importErrorJSON{
+			SourceFile: e.Source.FilePath,
+			Stage:      e.Stage,
+			Message:    e.Message,
+		}
+		if e.Error != nil {
+			entry.Error = e.Error.Error()
+		}
+		out.Errors = append(out.Errors, entry)
+	}
+
+	return out
+}
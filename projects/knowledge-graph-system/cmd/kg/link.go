@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// newLinkCmd builds `kg link <from-block-id> <to-block-id>`
+func newLinkCmd() *cobra.Command {
+	rel := types.Relationship{RelationshipType: types.RelationshipRelatedTo, Confidence: 1}
+
+	cmd := &cobra.Command{
+		Use:   "link <from-block-id> <to-block-id>",
+		Short: "Relate two blocks with a typed edge",
+		Long: `Add a typed relationship from one block to another, or update the confidence
+of an existing one. Relationships appear in kg context for both blocks.
+
+Types: ` + strings.Join(types.RelationshipTypes, ", "),
+		Example: `  kg link <commit-block> <session-block> --type implements
+  kg link <new-decision> <old-decision> --type supersedes --confidence 0.8`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if rel.FromBlockID, rel.ToBlockID, err = parseBlockPair(args); err != nil {
+				return err
+			}
+
+			kg, err := openDB()
+			if err != nil {
+				return err
+			}
+			defer kg.Close()
+
+			ctx := context.Background()
+			caller, err := currentCaller(ctx, kg)
+			if err != nil {
+				return err
+			}
+
+			if err := kg.AddRelationship(ctx, rel, caller); err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(rel)
+			}
+			fmt.Printf("Linked %s -%s-> %s (confidence %.2f)\n", rel.FromBlockID, rel.RelationshipType, rel.ToBlockID, rel.Confidence)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rel.RelationshipType, "type", rel.RelationshipType, "Relationship type: "+strings.Join(types.RelationshipTypes, ", "))
+	cmd.Flags().Float64Var(&rel.Confidence, "confidence", rel.Confidence, "Confidence in the relationship (0-1)")
+
+	return cmd
+}
+
+// newUnlinkCmd builds `kg unlink <from-block-id> <to-block-id>`
+func newUnlinkCmd() *cobra.Command {
+	relationshipType := types.RelationshipRelatedTo
+
+	cmd := &cobra.Command{
+		Use:   "unlink <from-block-id> <to-block-id>",
+		Short: "Remove a typed edge between two blocks",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fromID, toID, err := parseBlockPair(args)
+			if err != nil {
+				return err
+			}
+
+			kg, err := openDB()
+			if err != nil {
+				return err
+			}
+			defer kg.Close()
+
+			ctx := context.Background()
+			caller, err := currentCaller(ctx, kg)
+			if err != nil {
+				return err
+			}
+
+			if err := kg.RemoveRelationship(ctx, fromID, toID, relationshipType, caller); err != nil {
+				return err
+			}
+
+			fmt.Printf("Unlinked %s -%s-> %s\n", fromID, relationshipType, toID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&relationshipType, "type", relationshipType, "Relationship type: "+strings.Join(types.RelationshipTypes, ", "))
+
+	return cmd
+}
+
+// parseBlockPair parses the from and to block IDs of link and unlink
+func parseBlockPair(args []string) (uuid.UUID, uuid.UUID, error) {
+	fromID, err := uuid.Parse(args[0])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("invalid from block id: %w", err)
+	}
+	toID, err := uuid.Parse(args[1])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("invalid to block id: %w", err)
+	}
+	return fromID, toID, nil
+}
+
+// formatEdge describes a relationship from the point of view of the block it
+// was loaded for
+func formatEdge(edge types.Edge) string {
+	arrow := fmt.Sprintf("-%s->", edge.RelationshipType)
+	if edge.Direction == types.DirectionIncoming {
+		arrow = fmt.Sprintf("<-%s-", edge.RelationshipType)
+	}
+	return fmt.Sprintf("%s %s  %s (confidence %.2f)", arrow, edge.Block.ID, edge.Block.Topic, edge.Confidence)
+}
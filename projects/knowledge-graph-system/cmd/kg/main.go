@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/db"
+	"github.com/TheGenXCoder/knowledge-graph/internal/embeddings"
+	"github.com/TheGenXCoder/knowledge-graph/internal/importer"
+	"github.com/TheGenXCoder/knowledge-graph/internal/tagging"
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// Global flags shared by all subcommands
+var (
+	jsonOutput bool
+	verbose    bool
+	policyPath string
+)
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:           "kg",
+		Short:         "Knowledge graph CLI - import, search and explore conversation blocks",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// Pipeline stages log liberally; keep them quiet unless asked
+			if !verbose {
+				log.SetOutput(io.Discard)
+			}
+		},
+	}
+
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output results as JSON")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Detailed logging")
+	rootCmd.PersistentFlags().StringVar(&policyPath, "policy", "", "Classification policy file (default: KG_CLASSIFICATION_POLICY or the KG_ORG policy)")
+
+	rootCmd.AddCommand(newImportCmd())
+	rootCmd.AddCommand(newWatchCmd())
+	rootCmd.AddCommand(newClassifyCmd())
+	rootCmd.AddCommand(newSearchCmd())
+	rootCmd.AddCommand(newShowCmd())
+	rootCmd.AddCommand(newContextCmd())
+	rootCmd.AddCommand(newTraverseCmd())
+	rootCmd.AddCommand(newLinkCmd())
+	rootCmd.AddCommand(newUnlinkCmd())
+	rootCmd.AddCommand(newTagsCmd())
+	rootCmd.AddCommand(newEvalCmd())
+
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// openDB connects to the knowledge graph using KG_* environment configuration
+func openDB() (*db.PostgresDB, error) {
+	embedder := embeddings.NewOllamaEmbedder(
+		os.Getenv("KG_OLLAMA_URL"),
+		os.Getenv("KG_OLLAMA_MODEL"),
+	)
+
+	connStr := getEnv("KG_DB_URL", "host=localhost port=5432 dbname=knowledge_graph sslmode=disable")
+	database, err := db.NewPostgresDB(connStr, embedder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to knowledge graph: %w", err)
+	}
+
+	inference, err := inferenceFromEnv()
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+	database.SetInferenceOptions(inference)
+	database.SetTagExtractor(tagging.NewExtractor(os.Getenv("KG_OLLAMA_URL"), os.Getenv("KG_TAG_MODEL"), database))
+
+	return database, nil
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// truncate shortens s to at most maxLen runes, ending with an ellipsis when cut
+func truncate(s string, maxLen int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	if maxLen <= 3 {
+		return string(runes[:max(maxLen, 0)])
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
+
+// currentUser returns KG_USER, defaulting to the login name
+func currentUser() string {
+	if name := os.Getenv("KG_USER"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// currentCaller identifies the CLI user for visibility checks: currentUser in
+// the KG_ORG organization, a member of the KG_TEAMS teams (comma-separated)
+func currentCaller(ctx context.Context, kg *db.PostgresDB) (types.Caller, error) {
+	return kg.ResolveCaller(ctx, currentUser(), getEnv("KG_ORG", "personal"), envList("KG_TEAMS"))
+}
+
+// gitRemotePolicyFromEnv builds the policy that decides which git checkouts are
+// public from KG_PUBLIC_GIT_HOSTS, KG_PUBLIC_GIT_ORGS, KG_PUBLIC_GIT_REPOS,
+// KG_PRIVATE_GIT_HOSTS and KG_PRIVATE_GIT_ORGS (comma-separated; public hosts
+// default to the big public forges, and nothing is public unless listed)
+func gitRemotePolicyFromEnv() importer.GitRemotePolicy {
+	policy := importer.DefaultGitRemotePolicy
+	if hosts := envList("KG_PUBLIC_GIT_HOSTS"); hosts != nil {
+		policy.PublicHosts = hosts
+	}
+	policy.PublicOrgs = envList("KG_PUBLIC_GIT_ORGS")
+	policy.PublicRepos = envList("KG_PUBLIC_GIT_REPOS")
+	policy.PrivateHosts = envList("KG_PRIVATE_GIT_HOSTS")
+	policy.PrivateOrgs = envList("KG_PRIVATE_GIT_ORGS")
+	return policy
+}
+
+// inferenceFromEnv reads how relationships are inferred for new blocks from
+// KG_INFER (signals, comma-separated; "none" disables inference),
+// KG_INFER_SIMILARITY, KG_INFER_MAX_SIMILAR and KG_INFER_FILE_OVERLAP
+func inferenceFromEnv() (types.InferenceOptions, error) {
+	var opts types.InferenceOptions
+	opts.Disabled, opts.Signals = parseInferenceSignals(envList("KG_INFER"))
+
+	var err error
+	if value := os.Getenv("KG_INFER_SIMILARITY"); value != "" {
+		if opts.SimilarityThreshold, err = strconv.ParseFloat(value, 64); err != nil {
+			return opts, fmt.Errorf("invalid KG_INFER_SIMILARITY %q: %w", value, err)
+		}
+	}
+	if value := os.Getenv("KG_INFER_MAX_SIMILAR"); value != "" {
+		if opts.MaxSimilar, err = strconv.Atoi(value); err != nil {
+			return opts, fmt.Errorf("invalid KG_INFER_MAX_SIMILAR %q: %w", value, err)
+		}
+	}
+	if value := os.Getenv("KG_INFER_FILE_OVERLAP"); value != "" {
+		if opts.FileOverlapThreshold, err = strconv.ParseFloat(value, 64); err != nil {
+			return opts, fmt.Errorf("invalid KG_INFER_FILE_OVERLAP %q: %w", value, err)
+		}
+	}
+
+	if err := opts.Validate(); err != nil {
+		return opts, fmt.Errorf("invalid KG_INFER_* configuration: %w", err)
+	}
+	return opts, nil
+}
+
+// parseInferenceSignals turns a list of inference signals into options:
+// "none" disables inference and "all" (like an empty list) uses every signal
+func parseInferenceSignals(list []string) (disabled bool, signals []string) {
+	for _, signal := range list {
+		switch signal = strings.TrimSpace(signal); signal {
+		case "none":
+			return true, nil
+		case "all", "":
+		default:
+			signals = append(signals, signal)
+		}
+	}
+	return false, signals
+}
+
+// envList splits a comma-separated environment variable, returning nil if it is unset
+func envList(key string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
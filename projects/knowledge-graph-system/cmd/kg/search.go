@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// newSearchCmd builds `kg search <query>`
+func newSearchCmd() *cobra.Command {
+	opts := types.SearchOptions{Limit: 10}
+	var boosts []string
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Semantic + keyword search across the knowledge graph",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := strings.Join(args, " ")
+
+			kg, err := openDB()
+			if err != nil {
+				return err
+			}
+			defer kg.Close()
+
+			ctx := context.Background()
+			if opts.Caller, err = currentCaller(ctx, kg); err != nil {
+				return err
+			}
+			if opts.Ranking.ProjectBoosts, err = parseProjectBoosts(boosts); err != nil {
+				return err
+			}
+
+			results, err := kg.Search(ctx, query, opts)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(results)
+			}
+
+			if len(results.Results) == 0 {
+				fmt.Printf("No results for %q\n", query)
+				return nil
+			}
+
+			for i, result := range results.Results {
+				printSearchResult(i+1, result)
+			}
+			fmt.Printf("Found %d results (%s)\n", results.TotalFound, results.SearchTime.Round(time.Millisecond))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.Limit, "limit", opts.Limit, "Maximum number of results")
+	cmd.Flags().Float64Var(&opts.MinRelevance, "min-relevance", 0, "Minimum relevance score (0-1)")
+	cmd.Flags().BoolVar(&opts.IncludeNPlus, "related", false, "Include N+1 related blocks")
+	cmd.Flags().StringArrayVar(&opts.Tags, "tag", nil, "Only return blocks with this tag, an alias of it or a narrower tag (repeatable)")
+	addRankingFlags(cmd, &opts.Ranking, &boosts)
+
+	return cmd
+}
+
+// addRankingFlags adds the flags tuning how search results are ranked
+func addRankingFlags(cmd *cobra.Command, ranking *types.RankingOptions, boosts *[]string) {
+	cmd.Flags().StringVar(&ranking.Strategy, "ranking", types.RankingRRF, "How signals are fused: rrf (reciprocal rank fusion) or linear (weighted scores)")
+	cmd.Flags().Float64Var(&ranking.RRFK, "rrf-k", types.DefaultRRFK, "Rank offset for reciprocal rank fusion")
+	cmd.Flags().Float64Var(&ranking.RecencyHalfLifeDays, "half-life", 0, "Halve the relevance of blocks every this many days since creation (0 = no decay)")
+	cmd.Flags().StringArrayVar(boosts, "boost-project", nil, "Multiply the relevance of a project's blocks: <project-id>=<factor> (repeatable)")
+}
+
+// parseProjectBoosts parses --boost-project values
+func parseProjectBoosts(values []string) (map[uuid.UUID]float64, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	boosts := make(map[uuid.UUID]float64, len(values))
+	for _, value := range values {
+		id, factor, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --boost-project %q: want <project-id>=<factor>", value)
+		}
+		projectID, err := uuid.Parse(strings.TrimSpace(id))
+		if err != nil {
+			return nil, fmt.Errorf("invalid --boost-project %q: %w", value, err)
+		}
+		boost, err := strconv.ParseFloat(strings.TrimSpace(factor), 64)
+		if err != nil || boost <= 0 {
+			return nil, fmt.Errorf("invalid --boost-project %q: factor must be a positive number", value)
+		}
+		boosts[projectID] = boost
+	}
+	return boosts, nil
+}
+
+// newShowCmd builds `kg show <block-id>`
+func newShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <block-id>",
+		Short: "Show a block with all of its exchanges",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			blockID, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid block id: %w", err)
+			}
+
+			kg, err := openDB()
+			if err != nil {
+				return err
+			}
+			defer kg.Close()
+
+			ctx := context.Background()
+			caller, err := currentCaller(ctx, kg)
+			if err != nil {
+				return err
+			}
+
+			block, err := kg.GetBlock(ctx, blockID, caller)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(block)
+			}
+
+			printBlock(block)
+			return nil
+		},
+	}
+}
+
+// newContextCmd builds `kg context <block-id>`
+func newContextCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "context <block-id>",
+		Short: "Show a block plus everything one hop away (N+1 context)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			blockID, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid block id: %w", err)
+			}
+
+			kg, err := openDB()
+			if err != nil {
+				return err
+			}
+			defer kg.Close()
+
+			ctx := context.Background()
+			caller, err := currentCaller(ctx, kg)
+			if err != nil {
+				return err
+			}
+
+			bundle, err := kg.GetContextNPlusOne(ctx, blockID, caller)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(bundle)
+			}
+
+			printBlock(bundle.PrimaryBlock)
+
+			fmt.Printf("\nRelationships (%d):\n", len(bundle.Edges))
+			for _, edge := range bundle.Edges {
+				fmt.Printf("  %s\n", formatEdge(edge))
+			}
+
+			fmt.Printf("\nRelated blocks (%d):\n", len(bundle.RelatedBlocks))
+			for _, related := range bundle.RelatedBlocks {
+				fmt.Printf("  %s  %s\n", related.ID, related.Topic)
+			}
+
+			return nil
+		},
+	}
+}
+
+// newTraverseCmd builds `kg traverse <block-id>`
+func newTraverseCmd() *cobra.Command {
+	var opts types.TraversalOptions
+	var project string
+
+	cmd := &cobra.Command{
+		Use:   "traverse <block-id>",
+		Short: "Show everything within a few relationship hops of a block (N+k context)",
+		Long: `Walk the relationship graph from a block and list every block reached, nearest
+first, with the chain of relationships that reached it. Cycles are not followed.
+The filters choose which reached blocks are listed; the walk still passes
+through the others.`,
+		Example: `  kg traverse <decision-block> --hops 3
+  kg traverse <block-id> --type supersedes --type derived-from --direction outgoing
+  kg traverse <block-id> --tag postgres --visibility public`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			blockID, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid block id: %w", err)
+			}
+			if project != "" {
+				projectID, err := uuid.Parse(project)
+				if err != nil {
+					return fmt.Errorf("invalid project id: %w", err)
+				}
+				opts.ProjectID = &projectID
+			}
+
+			kg, err := openDB()
+			if err != nil {
+				return err
+			}
+			defer kg.Close()
+
+			ctx := context.Background()
+			if opts.Caller, err = currentCaller(ctx, kg); err != nil {
+				return err
+			}
+
+			result, err := kg.GetContextNPlusK(ctx, blockID, opts)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(result)
+			}
+
+			fmt.Printf("%s  %s\n", result.Root.ID, result.Root.Topic)
+			for _, node := range result.Nodes {
+				fmt.Printf("\n%d hop(s), confidence %.2f: %s  %s\n", node.Depth, node.Confidence, node.Block.ID, node.Block.Topic)
+				for _, rel := range node.Path {
+					fmt.Printf("   %s -%s-> %s\n", rel.FromBlockID, rel.RelationshipType, rel.ToBlockID)
+				}
+			}
+			fmt.Printf("\nReached %d blocks", len(result.Nodes))
+			if result.Truncated {
+				fmt.Print(" (node budget reached, raise --max-nodes for more)")
+			}
+			fmt.Println()
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.MaxHops, "hops", 2, "How many relationships away to go (at most 5)")
+	cmd.Flags().IntVar(&opts.MaxNodes, "max-nodes", 50, "Maximum number of blocks listed")
+	cmd.Flags().StringVar(&opts.Direction, "direction", types.DirectionBoth, "Which way to follow relationships: both, outgoing or incoming")
+	cmd.Flags().StringArrayVar(&opts.RelationshipTypes, "type", nil, "Only follow this relationship type (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.Tags, "tag", nil, "Only list blocks with this tag, an alias of it or a narrower tag (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.Visibility, "visibility", nil, "Only list blocks with this visibility (repeatable)")
+	cmd.Flags().StringVar(&project, "project", "", "Only list blocks in this project (ID)")
+
+	return cmd
+}
+
+// printSearchResult prints one search hit in the compact list format
+func printSearchResult(rank int, result types.SearchResult) {
+	block := result.Block
+
+	marker := ""
+	if block.SourceAttribution != "" {
+		marker = " ⁱ"
+	}
+
+	fmt.Printf("%d. %s (relevance: %.2f)%s\n", rank, block.Topic, result.Relevance, marker)
+	fmt.Printf("   ID: %s\n", block.ID)
+	if block.SourceAttribution != "" {
+		fmt.Printf("   %s\n", block.SourceAttribution)
+	}
+	fmt.Printf("   Created: %s\n", block.CreatedAt.Format("2006-01-02"))
+	fmt.Printf("   Exchanges: %d\n", len(block.Exchanges))
+	for _, match := range result.Matches {
+		snippet := match.AnswerSnippet
+		if strings.Contains(match.QuestionSnippet, "**") || !strings.Contains(snippet, "**") {
+			snippet = match.QuestionSnippet
+		}
+		snippet = strings.Join(strings.Fields(snippet), " ")
+		fmt.Printf("   Match (exchange %d): %s\n", match.Sequence+1, truncate(snippet, 160))
+	}
+	if len(result.Matches) == 0 && len(block.Exchanges) > 0 {
+		fmt.Printf("   Preview: %s\n", truncate(block.Exchanges[0].Question, 100))
+	}
+	for _, related := range result.Related {
+		fmt.Printf("   → %s (%s)\n", related.Topic, related.ID)
+	}
+	fmt.Println()
+}
+
+// printBlock prints a block header followed by its exchanges
+func printBlock(block *types.Block) {
+	tagNames := make([]string, len(block.Tags))
+	for i, tag := range block.Tags {
+		tagNames[i] = tag.Name
+	}
+
+	fmt.Printf("Topic: %s\n", block.Topic)
+	fmt.Printf("Date: %s\n", block.StartedAt.Format("2006-01-02 15:04:05"))
+	if len(tagNames) > 0 {
+		fmt.Printf("Tags: %s\n", strings.Join(tagNames, ", "))
+	}
+	if block.Visibility != "" {
+		fmt.Printf("Visibility: %s\n", block.Visibility)
+	}
+	if block.SourceFile != "" {
+		fmt.Printf("Source: %s\n", block.SourceFile)
+	}
+	if block.SourceAttribution != "" {
+		fmt.Printf("%s\n", block.SourceAttribution)
+	}
+	fmt.Printf("ID: %s\n", block.ID)
+
+	fmt.Println()
+	fmt.Println(strings.Repeat("─", 40))
+
+	for _, ex := range block.Exchanges {
+		fmt.Println()
+		fmt.Printf("Q: %s\n", ex.Question)
+		fmt.Printf("A: %s\n", ex.Answer)
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/db"
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// newTagsCmd builds `kg tags` and its subcommands
+func newTagsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tags",
+		Short: "List tags and manage the tag taxonomy",
+		Long: `Tags are extracted from blocks automatically, so the same topic can end up
+under several names (postgres, postgresql, pg). The taxonomy fixes that:
+
+  aliases    other names that resolve to a tag, when tagging blocks and when
+             filtering by tag
+  hierarchy  a tag may narrow a broader parent tag; filtering by the parent
+             includes its narrower tags
+  merge      folds tags into one, moving their blocks and keeping their names
+             as aliases
+
+kg search --tag and kg traverse --tag expand through aliases and narrower tags.`,
+		Example: `  kg tags list --prefix post
+  kg tags merge postgresql pg --into postgres
+  kg tags parent pgvector postgres
+  kg search "index tuning" --tag postgres`,
+	}
+
+	cmd.AddCommand(newTagsListCmd())
+	cmd.AddCommand(newTagsShowCmd())
+	cmd.AddCommand(newTagsRenameCmd())
+	cmd.AddCommand(newTagsMergeCmd())
+	cmd.AddCommand(newTagsAliasCmd())
+	cmd.AddCommand(newTagsUnaliasCmd())
+	cmd.AddCommand(newTagsParentCmd())
+
+	return cmd
+}
+
+// newTagsListCmd builds `kg tags list`
+func newTagsListCmd() *cobra.Command {
+	var opts types.TagListOptions
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List tags with usage counts, most used first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDB(func(ctx context.Context, kg *db.PostgresDB) error {
+				var err error
+				if opts.Caller, err = currentCaller(ctx, kg); err != nil {
+					return err
+				}
+
+				tags, err := kg.ListTags(ctx, opts)
+				if err != nil {
+					return err
+				}
+
+				if jsonOutput {
+					return printJSON(tags)
+				}
+
+				if len(tags) == 0 {
+					fmt.Println("No tags")
+					return nil
+				}
+				for _, tag := range tags {
+					fmt.Printf("%6d  %s", tag.UsageCount, tag.Name)
+					if tag.Parent != "" {
+						fmt.Printf("  (under %s)", tag.Parent)
+					}
+					if len(tag.Aliases) > 0 {
+						fmt.Printf("  aka %s", strings.Join(tag.Aliases, ", "))
+					}
+					fmt.Println()
+				}
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Prefix, "prefix", "", "Only list tags starting with this")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 100, "Maximum number of tags")
+
+	return cmd
+}
+
+// newTagsShowCmd builds `kg tags show <tag>`
+func newTagsShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <tag>",
+		Short: "Show a tag (by name or alias) with its parent, children and aliases",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDB(func(ctx context.Context, kg *db.PostgresDB) error {
+				caller, err := currentCaller(ctx, kg)
+				if err != nil {
+					return err
+				}
+
+				tag, err := kg.GetTag(ctx, args[0], caller)
+				if err != nil {
+					return err
+				}
+
+				if jsonOutput {
+					return printJSON(tag)
+				}
+
+				fmt.Printf("Tag: %s\n", tag.Name)
+				fmt.Printf("ID: %s\n", tag.ID)
+				fmt.Printf("Blocks: %d\n", tag.UsageCount)
+				if tag.Parent != "" {
+					fmt.Printf("Parent: %s\n", tag.Parent)
+				}
+				if len(tag.Children) > 0 {
+					fmt.Printf("Children: %s\n", strings.Join(tag.Children, ", "))
+				}
+				if len(tag.Aliases) > 0 {
+					fmt.Printf("Aliases: %s\n", strings.Join(tag.Aliases, ", "))
+				}
+				return nil
+			})
+		},
+	}
+}
+
+// newTagsRenameCmd builds `kg tags rename <tag> <new-name>`
+func newTagsRenameCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename <tag> <new-name>",
+		Short: "Rename a tag, keeping the old name as an alias",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDB(func(ctx context.Context, kg *db.PostgresDB) error {
+				caller, err := currentCaller(ctx, kg)
+				if err != nil {
+					return err
+				}
+				if err := kg.RenameTag(ctx, args[0], args[1], caller); err != nil {
+					return err
+				}
+				fmt.Printf("Renamed %s to %s\n", args[0], args[1])
+				return nil
+			})
+		},
+	}
+}
+
+// newTagsMergeCmd builds `kg tags merge <tag>... --into <target>`
+func newTagsMergeCmd() *cobra.Command {
+	var target string
+
+	cmd := &cobra.Command{
+		Use:   "merge <tag>... --into <target>",
+		Short: "Fold tags into one, moving their blocks and keeping their names as aliases",
+		Long: `Merge tags into a target tag (created if missing). Blocks tagged with a merged
+tag get the target instead, the merged tags' aliases and narrower tags move to
+the target, and their names become aliases of it.`,
+		Example: `  kg tags merge postgresql pg --into postgres`,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDB(func(ctx context.Context, kg *db.PostgresDB) error {
+				caller, err := currentCaller(ctx, kg)
+				if err != nil {
+					return err
+				}
+				result, err := kg.MergeTags(ctx, args, target, caller)
+				if err != nil {
+					return err
+				}
+
+				if jsonOutput {
+					return printJSON(result)
+				}
+				if len(result.Merged) == 0 {
+					fmt.Printf("Nothing to merge: already %s\n", result.Target.Name)
+					return nil
+				}
+				fmt.Printf("Merged %s into %s (%d block tags moved)\n", strings.Join(result.Merged, ", "), result.Target.Name, result.BlocksRelinked)
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&target, "into", "", "Tag to merge into")
+	cmd.MarkFlagRequired("into")
+
+	return cmd
+}
+
+// newTagsAliasCmd builds `kg tags alias <alias> <tag>`
+func newTagsAliasCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "alias <alias> <tag>",
+		Short: "Make another name resolve to a tag",
+		Long: `Make alias resolve to a tag: blocks tagged with the alias get the tag, and
+filtering by the alias finds the tag's blocks. To fold an existing tag into
+another, use kg tags merge instead.`,
+		Example: `  kg tags alias k8s kubernetes`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDB(func(ctx context.Context, kg *db.PostgresDB) error {
+				caller, err := currentCaller(ctx, kg)
+				if err != nil {
+					return err
+				}
+				if err := kg.AddTagAlias(ctx, args[0], args[1], caller); err != nil {
+					return err
+				}
+				fmt.Printf("%s now resolves to %s\n", args[0], args[1])
+				return nil
+			})
+		},
+	}
+}
+
+// newTagsUnaliasCmd builds `kg tags unalias <alias>`
+func newTagsUnaliasCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unalias <alias>",
+		Short: "Remove an alias",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDB(func(ctx context.Context, kg *db.PostgresDB) error {
+				caller, err := currentCaller(ctx, kg)
+				if err != nil {
+					return err
+				}
+				if err := kg.RemoveTagAlias(ctx, args[0], caller); err != nil {
+					return err
+				}
+				fmt.Printf("Removed alias %s\n", args[0])
+				return nil
+			})
+		},
+	}
+}
+
+// newTagsParentCmd builds `kg tags parent <tag> [parent]`
+func newTagsParentCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "parent <tag> [parent]",
+		Short: "Place a tag under a broader tag, or make it a root without one",
+		Example: `  kg tags parent pgvector postgres
+  kg tags parent pgvector`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			parent := ""
+			if len(args) == 2 {
+				parent = args[1]
+			}
+
+			return withDB(func(ctx context.Context, kg *db.PostgresDB) error {
+				caller, err := currentCaller(ctx, kg)
+				if err != nil {
+					return err
+				}
+				if err := kg.SetTagParent(ctx, args[0], parent, caller); err != nil {
+					return err
+				}
+				if parent == "" {
+					fmt.Printf("%s is now a root tag\n", args[0])
+				} else {
+					fmt.Printf("%s is now under %s\n", args[0], parent)
+				}
+				return nil
+			})
+		},
+	}
+}
+
+// withDB opens the database for the duration of fn
+func withDB(fn func(ctx context.Context, kg *db.PostgresDB) error) error {
+	kg, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer kg.Close()
+
+	return fn(context.Background(), kg)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/importer"
+	"github.com/spf13/cobra"
+)
+
+// newWatchCmd builds `kg watch <dir>`
+func newWatchCmd() *cobra.Command {
+	opts := importer.DefaultWatchOptions()
+	var inference inferenceFlags
+
+	cmd := &cobra.Command{
+		Use:   "watch <directory>",
+		Short: "Import files continuously as they are written",
+		Long: `Watch a directory and import matching files as they change.
+
+Uses inotify on Linux and falls back to polling elsewhere (or with --poll).
+New milestones in an imported session log are appended as further chunks, but
+edits to its last imported milestone are not, so a session log is held back
+until it has a "Session Summary" / "**Outcome:**" section or has been idle for
+--finalize-after.`,
+		Example: `  kg watch conversation-logs/
+  kg watch . --types logs --finalize-after 30m`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootDir, err := filepath.Abs(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid directory: %w", err)
+			}
+
+			switch opts.Import.Visibility {
+			case "auto", "public", "anonymized", "org-private", "individual":
+			default:
+				return fmt.Errorf("invalid visibility %q (expected auto, public, anonymized, org-private or individual)", opts.Import.Visibility)
+			}
+
+			opts.Import.RootDir = rootDir
+			opts.Import.Owner = currentUser()
+			opts.Import.Verbose = verbose
+			if opts.Import.Inference, err = inference.options(cmd); err != nil {
+				return err
+			}
+
+			if _, err := configureClassification(); err != nil {
+				return err
+			}
+
+			opts.OnPending = func(source importer.ImportSource) {
+				if !jsonOutput {
+					fmt.Printf("%s  %s still growing, waiting for it to finish\n",
+						time.Now().Format("15:04:05"), filepath.Base(source.FilePath))
+				}
+			}
+			opts.OnReport = func(report *importer.ImportReport) {
+				if jsonOutput {
+					printJSON(newImportReportJSON(report, false))
+					return
+				}
+				fmt.Printf("%s  %d files: %d new, %d updated, %d appended, %d skipped, %d failed blocks\n",
+					time.Now().Format("15:04:05"), report.SourcesFound,
+					report.Inserted, report.Updated, report.Appended, report.Skipped, report.Failed)
+				if verbose {
+					for _, e := range report.Errors {
+						fmt.Printf("  [%s] %s: %s: %v\n", e.Stage, e.Source.FilePath, e.Message, e.Error)
+					}
+				}
+			}
+
+			kg, err := openDB()
+			if err != nil {
+				return err
+			}
+			defer kg.Close()
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if !jsonOutput {
+				fmt.Printf("Watching %s (Ctrl-C to stop)\n", rootDir)
+			}
+
+			return importer.Watch(ctx, kg, opts)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.Import.FileTypes, "types", opts.Import.FileTypes, "File types to import (logs, specs, docs, working, chatgpt, transcripts, all; see kg import formats)")
+	cmd.Flags().StringSliceVar(&opts.Import.ExcludePattern, "exclude", opts.Import.ExcludePattern, "Exclude patterns (glob)")
+	cmd.Flags().StringVar(&opts.Import.Visibility, "visibility", opts.Import.Visibility, "Override visibility (auto, public, anonymized, org-private, individual)")
+	cmd.Flags().BoolVar(&opts.Import.RedactPII, "redact-pii", false, "Replace detected personal data and secrets with [REDACTED:<kind>] before embedding")
+	cmd.Flags().BoolVar(&opts.Import.Redact, "redact", false, "Apply the classification policy's redaction rules to blocks imported as public")
+	cmd.Flags().DurationVar(&opts.Debounce, "debounce", opts.Debounce, "Quiet period before a changed file is imported")
+	cmd.Flags().DurationVar(&opts.FinalizeAfter, "finalize-after", opts.FinalizeAfter, "Idle time after which a session log counts as finished")
+	cmd.Flags().DurationVar(&opts.PollInterval, "poll-interval", opts.PollInterval, "Scan interval when polling")
+	cmd.Flags().BoolVar(&opts.ForcePolling, "poll", false, "Poll instead of using inotify")
+	inference.register(cmd)
+
+	return cmd
+}
@@ -3,14 +3,23 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/TheGenXCoder/knowledge-graph/internal/core"
+	"github.com/TheGenXCoder/knowledge-graph/internal/db"
+	"github.com/TheGenXCoder/knowledge-graph/internal/embeddings"
+	"github.com/TheGenXCoder/knowledge-graph/internal/tagging"
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"github.com/rs/cors"
@@ -18,13 +27,24 @@ import (
 
 // Config holds the application configuration
 type Config struct {
-	Port      string
-	DBHost    string
-	DBName    string
-	DBUser    string
-	DBPassword string
-	RedisHost string
-	JWTSecret string
+	Port        string
+	DBHost      string
+	DBPort      string
+	DBName      string
+	DBUser      string
+	DBPassword  string
+	DBSSLMode   string
+	RedisHost   string
+	JWTSecret   string
+	OllamaHost  string
+	OllamaModel string
+	TagModel    string // Ollama chat model for tagging saved blocks ("none": keywords only)
+}
+
+// DatabaseURL builds the PostgreSQL connection string from the config
+func (c *Config) DatabaseURL() string {
+	return fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=%s",
+		c.DBHost, c.DBPort, c.DBName, c.DBUser, c.DBPassword, c.DBSSLMode)
 }
 
 // Server represents our API server
@@ -32,8 +52,16 @@ type Server struct {
 	config   *Config
 	router   *mux.Router
 	upgrader websocket.Upgrader
+	kg       core.KnowledgeGraph
+	embedder core.Embedder
 }
 
+// maxRequestBody caps JSON request bodies (1MB)
+const maxRequestBody = 1 << 20
+
+// maxSearchLimit caps the number of results a single search may request
+const maxSearchLimit = 100
+
 // HealthResponse represents the health check response
 type HealthResponse struct {
 	Status    string    `json:"status"`
@@ -41,11 +69,49 @@ type HealthResponse struct {
 	Version   string    `json:"version"`
 }
 
+// ErrorResponse is the body returned for every 4xx/5xx response
+type ErrorResponse struct {
+	Error  string `json:"error"`
+	Status int    `json:"status"`
+}
+
+// SearchRequest is the body of POST /api/v1/search
+type SearchRequest struct {
+	Query string `json:"query"`
+	types.SearchOptions
+}
+
+// KnowledgeAddRequest is the body of POST /api/v1/knowledge/add
+type KnowledgeAddRequest struct {
+	ProjectID        *uuid.UUID             `json:"project_id,omitempty"`
+	ProjectName      string                 `json:"project_name,omitempty"`
+	ProjectDirectory string                 `json:"project_directory,omitempty"`
+	Topic            string                 `json:"topic"`
+	Exchanges        []types.Exchange       `json:"exchanges"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// RelationshipRequest is the body of POST /api/v1/knowledge/{id}/relationships,
+// relating the block {id} to another
+type RelationshipRequest struct {
+	ToBlockID        uuid.UUID `json:"to_block_id"`
+	RelationshipType string    `json:"relationship_type"`
+	Confidence       float64   `json:"confidence,omitempty"` // Default 1
+}
+
+// EmbedRequest is the body of POST /api/v1/embed (either text or texts)
+type EmbedRequest struct {
+	Text  string   `json:"text,omitempty"`
+	Texts []string `json:"texts,omitempty"`
+}
+
 // NewServer creates a new server instance
-func NewServer(config *Config) *Server {
+func NewServer(config *Config, kg core.KnowledgeGraph, embedder core.Embedder) *Server {
 	return &Server{
-		config: config,
-		router: mux.NewRouter(),
+		config:   config,
+		kg:       kg,
+		embedder: embedder,
+		router:   mux.NewRouter(),
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				// Allow connections from any origin in development
@@ -70,6 +136,10 @@ func (s *Server) setupRoutes() {
 	api.HandleFunc("/embed", s.handleEmbed).Methods("POST")
 	api.HandleFunc("/knowledge/add", s.handleKnowledgeAdd).Methods("POST")
 	api.HandleFunc("/knowledge/{id}", s.handleKnowledgeGet).Methods("GET")
+	api.HandleFunc("/knowledge/{id}/context", s.handleKnowledgeContext).Methods("GET")
+	api.HandleFunc("/knowledge/{id}/graph", s.handleKnowledgeGraph).Methods("GET")
+	api.HandleFunc("/knowledge/{id}/relationships", s.handleRelationshipAdd).Methods("POST")
+	api.HandleFunc("/knowledge/{id}/relationships/{to}/{type}", s.handleRelationshipRemove).Methods("DELETE")
 
 	// Neovim plugin specific endpoints
 	api.HandleFunc("/nvim/complete", s.handleNvimComplete).Methods("POST")
@@ -97,12 +167,44 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 // handleSearch handles search requests
 func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement search logic with pgvector
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"results": []interface{}{},
-		"query":   r.URL.Query().Get("q"),
-	})
+	var req SearchRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Query == "" {
+		writeError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+	if req.Limit < 0 || req.Limit > maxSearchLimit {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 0 and %d", maxSearchLimit))
+		return
+	}
+	if req.MinRelevance < 0 || req.MinRelevance > 1 {
+		writeError(w, http.StatusBadRequest, "min_relevance must be between 0 and 1")
+		return
+	}
+	if err := req.Ranking.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+	req.Caller = caller
+
+	results, err := s.kg.Search(r.Context(), req.Query, req.SearchOptions)
+	if err != nil {
+		log.Printf("Search failed: %v", err)
+		writeError(w, http.StatusInternalServerError, "search failed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, results)
 }
 
 // handleChat handles chat requests
@@ -117,35 +219,282 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 
 // handleEmbed handles embedding requests
 func (s *Server) handleEmbed(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement embedding logic with Ollama
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"embeddings": []float64{},
-		"model":      "nomic-embed-text",
+	var req EmbedRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	texts := req.Texts
+	if req.Text != "" {
+		texts = append([]string{req.Text}, texts...)
+	}
+	if len(texts) == 0 {
+		writeError(w, http.StatusBadRequest, "text or texts is required")
+		return
+	}
+
+	vectors, err := s.embedder.EmbedBatch(r.Context(), texts)
+	if err != nil {
+		log.Printf("Embedding failed: %v", err)
+		writeError(w, http.StatusBadGateway, "embedding service unavailable")
+		return
+	}
+
+	dimensions := 0
+	if len(vectors) > 0 {
+		dimensions = len(vectors[0])
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"embeddings": vectors,
+		"model":      s.config.OllamaModel,
+		"dimensions": dimensions,
 	})
 }
 
-// handleKnowledgeAdd handles adding knowledge to the graph
+// handleKnowledgeAdd handles adding knowledge to the graph. The block belongs to
+// the caller's organization and user (see writerFromRequest).
 func (s *Server) handleKnowledgeAdd(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement knowledge addition logic
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status": "success",
-		"id":     "placeholder-id",
+	caller, ok := writerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req KnowledgeAddRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req.Topic = strings.TrimSpace(req.Topic)
+	if req.Topic == "" {
+		writeError(w, http.StatusBadRequest, "topic is required")
+		return
+	}
+	if len(req.Exchanges) == 0 {
+		writeError(w, http.StatusBadRequest, "at least one exchange is required")
+		return
+	}
+	for i, ex := range req.Exchanges {
+		if ex.Question == "" || ex.Answer == "" {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("question and answer are required in exchange %d", i))
+			return
+		}
+	}
+
+	// Resolve project: explicit ID wins, otherwise get-or-create by directory
+	var projectID uuid.UUID
+	switch {
+	case req.ProjectID != nil:
+		project, err := s.kg.GetProject(r.Context(), *req.ProjectID)
+		if err != nil {
+			writeKnowledgeError(w, err)
+			return
+		}
+		projectID = project.ID
+	case req.ProjectDirectory != "":
+		name := req.ProjectName
+		if name == "" {
+			name = req.ProjectDirectory
+		}
+		project, err := s.kg.GetOrCreateProject(r.Context(), name, req.ProjectDirectory)
+		if err != nil {
+			log.Printf("Project lookup failed: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to resolve project")
+			return
+		}
+		projectID = project.ID
+	default:
+		writeError(w, http.StatusBadRequest, "project_id or project_directory is required")
+		return
+	}
+
+	now := time.Now()
+	block := &types.Block{
+		ProjectID:     projectID,
+		Topic:         req.Topic,
+		StartedAt:     now,
+		CompletedAt:   &now,
+		ExchangeCount: len(req.Exchanges),
+		Exchanges:     req.Exchanges,
+		Metadata:      req.Metadata,
+
+		OrganizationID: caller.OrganizationID,
+		OwnerID:        caller.UserID,
+	}
+	if block.Metadata == nil {
+		block.Metadata = make(map[string]interface{})
+	}
+	if first := req.Exchanges[0].Timestamp; !first.IsZero() {
+		block.StartedAt = first
+	}
+
+	if err := s.kg.SaveBlock(r.Context(), block); err != nil {
+		log.Printf("SaveBlock failed: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to save block")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":     "success",
+		"id":         block.ID,
+		"project_id": block.ProjectID,
+		"exchanges":  len(block.Exchanges),
 	})
 }
 
 // handleKnowledgeGet retrieves knowledge from the graph
 func (s *Server) handleKnowledgeGet(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
+	id, ok := parseBlockID(w, r)
+	if !ok {
+		return
+	}
 
-	// TODO: Implement knowledge retrieval logic
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"id":      id,
-		"content": "placeholder content",
-	})
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	block, err := s.kg.GetBlock(r.Context(), id, caller)
+	if err != nil {
+		writeKnowledgeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, block)
+}
+
+// handleKnowledgeContext retrieves a block with its N+1 context
+func (s *Server) handleKnowledgeContext(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseBlockID(w, r)
+	if !ok {
+		return
+	}
+
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	bundle, err := s.kg.GetContextNPlusOne(r.Context(), id, caller)
+	if err != nil {
+		writeKnowledgeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, bundle)
+}
+
+// handleKnowledgeGraph walks the relationship graph from a block (N+k context).
+// Query parameters: hops, nodes, direction, and comma-separated type, tag and
+// visibility filters plus project (ID).
+func (s *Server) handleKnowledgeGraph(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseBlockID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	opts := types.TraversalOptions{
+		Direction:         query.Get("direction"),
+		RelationshipTypes: splitList(query.Get("type")),
+		Tags:              splitList(query.Get("tag")),
+		Visibility:        splitList(query.Get("visibility")),
+	}
+	for param, target := range map[string]*int{"hops": &opts.MaxHops, "nodes": &opts.MaxNodes} {
+		if value := query.Get(param); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("%s must be a number", param))
+				return
+			}
+			*target = n
+		}
+	}
+	if project := query.Get("project"); project != "" {
+		projectID, err := uuid.Parse(project)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid project id")
+			return
+		}
+		opts.ProjectID = &projectID
+	}
+	if err := opts.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+	opts.Caller = caller
+
+	result, err := s.kg.GetContextNPlusK(r.Context(), id, opts)
+	if err != nil {
+		writeKnowledgeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleRelationshipAdd relates a block to another with a typed edge
+func (s *Server) handleRelationshipAdd(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseBlockID(w, r)
+	if !ok {
+		return
+	}
+
+	var req RelationshipRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	caller, ok := writerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	rel := types.Relationship{
+		FromBlockID:      id,
+		ToBlockID:        req.ToBlockID,
+		RelationshipType: req.RelationshipType,
+		Confidence:       req.Confidence,
+	}
+	if err := s.kg.AddRelationship(r.Context(), rel, caller); err != nil {
+		writeKnowledgeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"status": "success"})
+}
+
+// handleRelationshipRemove removes a typed edge from a block to another
+func (s *Server) handleRelationshipRemove(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseBlockID(w, r)
+	if !ok {
+		return
+	}
+	toID, err := uuid.Parse(mux.Vars(r)["to"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid block id")
+		return
+	}
+
+	caller, ok := writerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.kg.RemoveRelationship(r.Context(), id, toID, mux.Vars(r)["type"], caller); err != nil {
+		writeKnowledgeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "success"})
 }
 
 // handleNvimComplete handles Neovim completion requests
@@ -162,7 +511,7 @@ func (s *Server) handleNvimEdit(w http.ResponseWriter, r *http.Request) {
 	// TODO: Implement Neovim edit logic
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"diff": "",
+		"diff":   "",
 		"status": "success",
 	})
 }
@@ -253,25 +602,134 @@ func (s *Server) Run() error {
 func main() {
 	// Load configuration from environment
 	config := &Config{
-		Port:       getEnv("PORT", "8080"),
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBName:     getEnv("DB_NAME", "catalyst9"),
-		DBUser:     getEnv("DB_USER", "catalyst9"),
-		DBPassword: getEnv("DB_PASSWORD", ""),
-		RedisHost:  getEnv("REDIS_HOST", "localhost:6379"),
-		JWTSecret:  getEnv("JWT_SECRET", "development_secret"),
+		Port:        getEnv("PORT", "8080"),
+		DBHost:      getEnv("DB_HOST", "localhost"),
+		DBPort:      getEnv("DB_PORT", "5432"),
+		DBName:      getEnv("DB_NAME", "catalyst9"),
+		DBUser:      getEnv("DB_USER", "catalyst9"),
+		DBPassword:  getEnv("DB_PASSWORD", ""),
+		DBSSLMode:   getEnv("DB_SSLMODE", "disable"),
+		RedisHost:   getEnv("REDIS_HOST", "localhost:6379"),
+		JWTSecret:   getEnv("JWT_SECRET", "development_secret"),
+		OllamaHost:  getEnv("OLLAMA_HOST", "http://localhost:11434"),
+		OllamaModel: getEnv("OLLAMA_MODEL", "nomic-embed-text"),
+		TagModel:    getEnv("OLLAMA_TAG_MODEL", "llama3.2"),
+	}
+
+	// Connect knowledge graph
+	embedder := embeddings.NewOllamaEmbedder(config.OllamaHost, config.OllamaModel)
+	kg, err := db.NewPostgresDB(config.DatabaseURL(), embedder)
+	if err != nil {
+		log.Fatalf("Knowledge graph unavailable: %v", err)
 	}
+	defer kg.Close()
+	kg.SetTagExtractor(tagging.NewExtractor(config.OllamaHost, config.TagModel, kg))
 
 	// Create and run server
-	server := NewServer(config)
+	server := NewServer(config, kg, embedder)
 	if err := server.Run(); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
 
+// decodeJSON decodes a size-limited JSON request body into v
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+// parseBlockID extracts the {id} path variable, writing a 400 if it is not a UUID
+func parseBlockID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid block id")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// callerFromRequest reads the caller's identity from the X-KG-User, X-KG-Org
+// (organization ID) and X-KG-Teams (comma-separated) headers. Requests without
+// them only see public blocks. The headers are trusted as sent, so the server
+// must sit behind a proxy that authenticates users and sets them.
+func callerFromRequest(w http.ResponseWriter, r *http.Request) (types.Caller, bool) {
+	caller := types.Caller{UserID: strings.TrimSpace(r.Header.Get("X-KG-User"))}
+
+	if org := strings.TrimSpace(r.Header.Get("X-KG-Org")); org != "" {
+		orgID, err := uuid.Parse(org)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid X-KG-Org header: expected an organization id")
+			return types.Caller{}, false
+		}
+		caller.OrganizationID = &orgID
+	}
+
+	caller.Teams = splitList(r.Header.Get("X-KG-Teams"))
+
+	return caller, true
+}
+
+// writerFromRequest identifies the caller of a write. Writes need both a user and
+// an organization, so anonymous requests cannot create blocks no one owns.
+func writerFromRequest(w http.ResponseWriter, r *http.Request) (types.Caller, bool) {
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return types.Caller{}, false
+	}
+	if caller.UserID == "" || caller.OrganizationID == nil {
+		writeError(w, http.StatusUnauthorized, "writes require the X-KG-User and X-KG-Org headers")
+		return types.Caller{}, false
+	}
+	return caller, true
+}
+
+// splitList splits a comma-separated value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// writeKnowledgeError maps knowledge graph errors to HTTP status codes
+func writeKnowledgeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, core.ErrBlockNotFound) || errors.Is(err, core.ErrRelationshipNotFound) || errors.Is(err, core.ErrProjectNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, core.ErrInvalidRelationship) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	log.Printf("Knowledge graph error: %v", err)
+	writeError(w, http.StatusInternalServerError, "internal server error")
+}
+
+// writeJSON writes v as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
+
+// writeError writes an ErrorResponse with the given status
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Error: message, Status: status})
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
@@ -2,11 +2,32 @@ package core
 
 import (
 	"context"
+	"errors"
 
 	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
 	"github.com/google/uuid"
 )
 
+// ErrBlockNotFound is returned (wrapped) when a requested block does not exist
+var ErrBlockNotFound = errors.New("block not found")
+
+// ErrProjectNotFound is returned (wrapped) when a requested project does not exist
+var ErrProjectNotFound = errors.New("project not found")
+
+// ErrRelationshipNotFound is returned (wrapped) when a relationship to remove does not exist
+var ErrRelationshipNotFound = errors.New("relationship not found")
+
+// ErrInvalidRelationship is returned (wrapped) for a relationship with an
+// unknown type, a confidence outside 0-1 or the same block at both ends
+var ErrInvalidRelationship = errors.New("invalid relationship")
+
+// ErrTagNotFound is returned (wrapped) when a tag or tag alias does not exist
+var ErrTagNotFound = errors.New("tag not found")
+
+// ErrInvalidTag is returned (wrapped) for a tag operation that would break the
+// taxonomy: an empty name, a name already in use, or a cycle in the hierarchy
+var ErrInvalidTag = errors.New("invalid tag")
+
 // KnowledgeGraph is the core interface - stable, never changes
 // Adapters (MCP, Gemini, etc.) interact through this interface
 type KnowledgeGraph interface {
@@ -18,17 +39,34 @@ type KnowledgeGraph interface {
 	// Extracts tags and infers relationships automatically
 	SaveBlock(ctx context.Context, block *types.Block) error
 
-	// GetBlock retrieves a block by ID
-	GetBlock(ctx context.Context, id uuid.UUID) (*types.Block, error)
+	// GetBlock retrieves a block by ID if the caller may see it
+	GetBlock(ctx context.Context, id uuid.UUID, caller types.Caller) (*types.Block, error)
 
 	// GetContextNPlusOne retrieves a block plus everything one hop away
-	// Returns N+1 context bundle: block + related blocks via tags/relationships
-	GetContextNPlusOne(ctx context.Context, blockID uuid.UUID) (*types.ContextBundle, error)
+	// Returns N+1 context bundle: block + related blocks via tags, and the
+	// relationship edges to and from it with the blocks at their other end
+	// Only blocks the caller may see are included
+	GetContextNPlusOne(ctx context.Context, blockID uuid.UUID, caller types.Caller) (*types.ContextBundle, error)
+
+	// GetContextNPlusK walks the relationship graph up to opts.MaxHops from a block
+	// Returns every block reached (within the node budget) with the path to it;
+	// cycle-safe, and only blocks the caller in opts may see are visited
+	GetContextNPlusK(ctx context.Context, blockID uuid.UUID, opts types.TraversalOptions) (*types.TraversalResult, error)
+
+	// AddRelationship links two blocks with a typed edge (see types.RelationshipTypes),
+	// updating the confidence of an existing edge. Both blocks must be visible to the caller.
+	AddRelationship(ctx context.Context, rel types.Relationship, caller types.Caller) error
+
+	// RemoveRelationship deletes a typed edge between two blocks the caller may see
+	RemoveRelationship(ctx context.Context, fromID, toID uuid.UUID, relationshipType string, caller types.Caller) error
 
 	// SearchProject searches within a specific project
 	// Useful for multi-project isolation
 	SearchProject(ctx context.Context, projectID uuid.UUID, query string, opts types.SearchOptions) (*types.SearchResults, error)
 
+	// GetProject retrieves a project by ID
+	GetProject(ctx context.Context, id uuid.UUID) (*types.Project, error)
+
 	// GetOrCreateProject gets existing or creates new project
 	GetOrCreateProject(ctx context.Context, name string, directory string) (*types.Project, error)
 
@@ -38,6 +76,35 @@ type KnowledgeGraph interface {
 	// ExtractTags uses local LLM to extract semantic tags from content
 	ExtractTags(ctx context.Context, content string) ([]string, error)
 
+	// ListTags lists the tags of blocks the caller may see with their parent,
+	// children, aliases and usage counts, most used first
+	ListTags(ctx context.Context, opts types.TagListOptions) ([]types.TagInfo, error)
+
+	// GetTag retrieves a tag by name or alias, if it is on a block the caller may see
+	GetTag(ctx context.Context, name string, caller types.Caller) (*types.TagInfo, error)
+
+	// RenameTag renames a tag, keeping the old name as an alias. Tags the
+	// caller may not see are not found, and tags also used outside the caller's
+	// organization cannot be changed; the same holds for the methods below.
+	RenameTag(ctx context.Context, name, newName string, caller types.Caller) error
+
+	// MergeTags moves the blocks, aliases and children of sources to target
+	// (created if missing), deletes sources and keeps their names as aliases
+	MergeTags(ctx context.Context, sources []string, target string, caller types.Caller) (*types.TagMergeResult, error)
+
+	// AddTagAlias makes alias resolve to a tag, both when tagging blocks and when filtering by tag
+	AddTagAlias(ctx context.Context, alias, tag string, caller types.Caller) error
+
+	// RemoveTagAlias deletes an alias
+	RemoveTagAlias(ctx context.Context, alias string, caller types.Caller) error
+
+	// SetTagParent makes parent the broader tag of tag ("" makes it a root)
+	SetTagParent(ctx context.Context, tag, parent string, caller types.Caller) error
+
+	// ExpandTags resolves tag names through aliases and adds every narrower
+	// tag, for filtering blocks by tag
+	ExpandTags(ctx context.Context, names []string) ([]string, error)
+
 	// Close closes the knowledge graph connection
 	Close() error
 }
@@ -51,3 +118,9 @@ type Embedder interface {
 	// EmbedBatch generates embeddings for multiple texts efficiently
 	EmbedBatch(ctx context.Context, texts []string) ([][]float64, error)
 }
+
+// TagExtractor proposes tags for content
+type TagExtractor interface {
+	// ExtractTags returns normalized tags, most confident first
+	ExtractTags(ctx context.Context, content string) ([]types.ExtractedTag, error)
+}
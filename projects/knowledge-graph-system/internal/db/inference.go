@@ -0,0 +1,462 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Inference defaults
+const (
+	defaultSimilarityThreshold  = 0.85
+	defaultMaxSimilar           = 5
+	defaultFileOverlapThreshold = 0.3
+)
+
+// blockIDPattern matches a block ID mentioned in text
+var blockIDPattern = regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)
+
+// inferenceBlock is what the inference signals need to know about a block
+type inferenceBlock struct {
+	ID             uuid.UUID
+	SourceFile     string
+	OrganizationID *uuid.UUID
+	Visibility     string
+	SessionID      string
+	ChunkNumber    int
+	Files          []string // Files the block's session modified
+	Text           string   // Topic, questions and answers (new blocks only)
+}
+
+// normalizeInference validates opts and applies the inference defaults
+func normalizeInference(opts types.InferenceOptions) (types.InferenceOptions, error) {
+	if err := opts.Validate(); err != nil {
+		return opts, err
+	}
+	if opts.SimilarityThreshold == 0 {
+		opts.SimilarityThreshold = defaultSimilarityThreshold
+	}
+	if opts.MaxSimilar == 0 {
+		opts.MaxSimilar = defaultMaxSimilar
+	}
+	if opts.FileOverlapThreshold == 0 {
+		opts.FileOverlapThreshold = defaultFileOverlapThreshold
+	}
+	return opts, nil
+}
+
+// SetInferenceOptions configures the relationships SaveBlock infers for new blocks
+func (p *PostgresDB) SetInferenceOptions(opts types.InferenceOptions) {
+	p.inference = opts
+}
+
+// InferRelationships relates each of the given blocks to existing blocks of the
+// same organization, using the signals enabled in opts:
+//
+//   - similarity: related-to the most similar blocks from other sources,
+//     with the cosine similarity as confidence
+//   - shared-files: related-to other sessions that modified the same files,
+//     with the share of files in common as confidence
+//   - continuation: the next chunk of a session continues the previous one
+//   - references: a block references the blocks whose IDs it mentions
+//
+// It returns the number of relationships saved.
+func (p *PostgresDB) InferRelationships(ctx context.Context, blockIDs []uuid.UUID, opts types.InferenceOptions) (int, error) {
+	opts, err := normalizeInference(opts)
+	if err != nil {
+		return 0, err
+	}
+	if opts.Disabled || len(blockIDs) == 0 {
+		return 0, nil
+	}
+
+	blocks, err := p.loadInferenceBlocks(ctx, true, `b.id = ANY($1::uuid[])`, pq.Array(uuidStrings(blockIDs)))
+	if err != nil {
+		return 0, err
+	}
+
+	var inferred []types.Relationship
+
+	if sessions := sessionIDs(blocks); opts.Uses(types.InferContinuation) && len(sessions) > 0 {
+		candidates, err := p.loadInferenceBlocks(ctx, false, `b.metadata->>'session_id' = ANY($1::text[])`, pq.Array(sessions))
+		if err != nil {
+			return 0, err
+		}
+		inferred = append(inferred, continuationLinks(blocks, candidates)...)
+	}
+
+	if files := modifiedFiles(blocks); opts.Uses(types.InferSharedFiles) && len(files) > 0 {
+		candidates, err := p.loadInferenceBlocks(ctx, false, `b.metadata->'files_modified' ?| $1::text[]`, pq.Array(files))
+		if err != nil {
+			return 0, err
+		}
+		inferred = append(inferred, sharedFileLinks(blocks, candidates, opts.FileOverlapThreshold)...)
+	}
+
+	if mentioned := mentionedBlockIDs(blocks); opts.Uses(types.InferReferences) && len(mentioned) > 0 {
+		candidates, err := p.loadInferenceBlocks(ctx, false, `b.id = ANY($1::uuid[])`, pq.Array(uuidStrings(mentioned)))
+		if err != nil {
+			return 0, err
+		}
+		inferred = append(inferred, referenceLinks(blocks, candidates)...)
+	}
+
+	if opts.Uses(types.InferSimilarity) {
+		similar, err := p.similarLinks(ctx, blockIDs, opts)
+		if err != nil {
+			return 0, err
+		}
+		inferred = append(inferred, similar...)
+	}
+
+	inferred = mergeRelationships(inferred)
+	if len(inferred) == 0 {
+		return 0, nil
+	}
+	if err := p.SaveRelationships(ctx, inferred); err != nil {
+		return 0, err
+	}
+	return len(inferred), nil
+}
+
+// loadInferenceBlocks loads the blocks matching where (over alias b, with one
+// parameter); withText also loads their text for reference detection
+func (p *PostgresDB) loadInferenceBlocks(ctx context.Context, withText bool, where string, arg interface{}) ([]inferenceBlock, error) {
+	text := `''`
+	if withText {
+		text = `b.topic || ' ' || COALESCE((
+			SELECT string_agg(e.question || ' ' || e.answer, ' ') FROM exchanges e WHERE e.block_id = b.id
+		), '')`
+	}
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT b.id, COALESCE(b.source_file, ''), b.organization_id, COALESCE(b.visibility, 'org-private'),
+		       COALESCE(b.metadata->>'session_id', ''),
+		       CASE WHEN jsonb_typeof(b.metadata->'chunk_number') = 'number'
+		            THEN (b.metadata->>'chunk_number')::numeric::int ELSE 0 END,
+		       CASE WHEN jsonb_typeof(b.metadata->'files_modified') = 'array'
+		            THEN b.metadata->'files_modified' ELSE '[]'::jsonb END,
+		       `+text+`
+		FROM blocks b
+		WHERE `+where, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query blocks for inference: %w", err)
+	}
+	defer rows.Close()
+
+	var blocks []inferenceBlock
+	for rows.Next() {
+		var b inferenceBlock
+		var files []byte
+		if err := rows.Scan(&b.ID, &b.SourceFile, &b.OrganizationID, &b.Visibility,
+			&b.SessionID, &b.ChunkNumber, &files, &b.Text); err != nil {
+			return nil, fmt.Errorf("failed to scan block for inference: %w", err)
+		}
+		if err := json.Unmarshal(files, &b.Files); err != nil {
+			return nil, fmt.Errorf("failed to decode files of block %s: %w", b.ID, err)
+		}
+		blocks = append(blocks, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read blocks for inference: %w", err)
+	}
+
+	return blocks, nil
+}
+
+// similarLinks relates each block to its nearest neighbours from other sources
+// whose embedding similarity reaches opts.SimilarityThreshold
+func (p *PostgresDB) similarLinks(ctx context.Context, blockIDs []uuid.UUID, opts types.InferenceOptions) ([]types.Relationship, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT n.id, s.id, s.similarity
+		FROM blocks n
+		CROSS JOIN LATERAL (
+			SELECT o.id, 1 - (o.embedding <=> n.embedding) AS similarity
+			FROM blocks o
+			WHERE o.id <> n.id
+			  AND o.embedding IS NOT NULL
+			  AND o.organization_id IS NOT DISTINCT FROM n.organization_id
+			  AND (COALESCE(n.source_file, '') = '' OR o.source_file IS DISTINCT FROM n.source_file)
+			ORDER BY o.embedding <=> n.embedding
+			LIMIT $3
+		) s
+		WHERE n.id = ANY($1::uuid[])
+		  AND n.embedding IS NOT NULL
+		  AND s.similarity >= $2
+	`, pq.Array(uuidStrings(blockIDs)), opts.SimilarityThreshold, opts.MaxSimilar)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query similar blocks: %w", err)
+	}
+	defer rows.Close()
+
+	var relationships []types.Relationship
+	for rows.Next() {
+		rel := types.Relationship{RelationshipType: types.RelationshipRelatedTo}
+		if err := rows.Scan(&rel.FromBlockID, &rel.ToBlockID, &rel.Confidence); err != nil {
+			return nil, fmt.Errorf("failed to scan similar block: %w", err)
+		}
+		rel.Confidence = min(rel.Confidence, 1)
+		relationships = append(relationships, rel)
+	}
+
+	return relationships, rows.Err()
+}
+
+// continuationLinks relates each session chunk to the chunks before and after
+// it among candidates: the later chunk continues the earlier one. Chunks must
+// share session, organization and visibility, so anonymized copies form their
+// own chain.
+func continuationLinks(blocks, candidates []inferenceBlock) []types.Relationship {
+	type chunkKey struct {
+		session, visibility string
+		org                 uuid.UUID
+		chunk               int
+	}
+	keyOf := func(b inferenceBlock, chunk int) chunkKey {
+		key := chunkKey{session: b.SessionID, visibility: b.Visibility, chunk: chunk}
+		if b.OrganizationID != nil {
+			key.org = *b.OrganizationID
+		}
+		return key
+	}
+
+	chunks := make(map[chunkKey]uuid.UUID, len(candidates))
+	for _, c := range candidates {
+		if c.SessionID != "" && c.ChunkNumber > 0 {
+			chunks[keyOf(c, c.ChunkNumber)] = c.ID
+		}
+	}
+
+	var relationships []types.Relationship
+	for _, b := range blocks {
+		if b.SessionID == "" || b.ChunkNumber == 0 {
+			continue
+		}
+		if prev, ok := chunks[keyOf(b, b.ChunkNumber-1)]; ok && prev != b.ID {
+			relationships = append(relationships, continuation(b.ID, prev))
+		}
+		if next, ok := chunks[keyOf(b, b.ChunkNumber+1)]; ok && next != b.ID {
+			relationships = append(relationships, continuation(next, b.ID))
+		}
+	}
+	return relationships
+}
+
+// continuation is a "continues" relationship from the later chunk to the earlier one
+func continuation(later, earlier uuid.UUID) types.Relationship {
+	return types.Relationship{
+		FromBlockID:      later,
+		ToBlockID:        earlier,
+		RelationshipType: types.RelationshipContinues,
+		Confidence:       1,
+	}
+}
+
+// sharedFileLinks relates sessions whose modified files overlap by at least
+// threshold (Jaccard: files in common over files in either). Every chunk of a
+// session lists the same files, so each session is represented by its first
+// chunk and gets one edge per related session.
+func sharedFileLinks(blocks, candidates []inferenceBlock, threshold float64) []types.Relationship {
+	var relationships []types.Relationship
+	for _, b := range sessionRepresentatives(blocks) {
+		for _, c := range sessionRepresentatives(candidates) {
+			if c.ID == b.ID || sessionKey(c) == sessionKey(b) || !sameScope(b, c) {
+				continue
+			}
+			overlap := fileOverlap(b.Files, c.Files)
+			if overlap < threshold {
+				continue
+			}
+			relationships = append(relationships, types.Relationship{
+				FromBlockID:      b.ID,
+				ToBlockID:        c.ID,
+				RelationshipType: types.RelationshipRelatedTo,
+				Confidence:       overlap,
+			})
+		}
+	}
+	return relationships
+}
+
+// sessionRepresentatives returns the lowest-numbered chunk of each session
+// among blocks that list modified files, in order of first appearance
+func sessionRepresentatives(blocks []inferenceBlock) []inferenceBlock {
+	index := make(map[string]int)
+	var reps []inferenceBlock
+	for _, b := range blocks {
+		if len(b.Files) == 0 {
+			continue
+		}
+		key := sessionKey(b)
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(reps)
+			reps = append(reps, b)
+			continue
+		}
+		if b.ChunkNumber < reps[i].ChunkNumber {
+			reps[i] = b
+		}
+	}
+	return reps
+}
+
+// sessionKey identifies the session a block belongs to: its source file and
+// visibility, or the block itself when it was not imported from a file
+func sessionKey(b inferenceBlock) string {
+	if b.SourceFile == "" {
+		return b.ID.String()
+	}
+	return b.SourceFile + "\x00" + b.Visibility
+}
+
+// sameScope reports whether two blocks share organization and visibility
+func sameScope(a, b inferenceBlock) bool {
+	return a.Visibility == b.Visibility && sameOrganization(a, b)
+}
+
+// sameOrganization reports whether two blocks belong to the same organization
+func sameOrganization(a, b inferenceBlock) bool {
+	if a.OrganizationID == nil || b.OrganizationID == nil {
+		return a.OrganizationID == nil && b.OrganizationID == nil
+	}
+	return *a.OrganizationID == *b.OrganizationID
+}
+
+// fileOverlap is the Jaccard index of two file lists, comparing cleaned paths
+func fileOverlap(a, b []string) float64 {
+	set := make(map[string]bool, len(a))
+	for _, f := range a {
+		set[cleanFilePath(f)] = true
+	}
+
+	union := len(set)
+	common := 0
+	seen := make(map[string]bool, len(b))
+	for _, f := range b {
+		f = cleanFilePath(f)
+		if seen[f] {
+			continue
+		}
+		seen[f] = true
+		if set[f] {
+			common++
+		} else {
+			union++
+		}
+	}
+
+	if union == 0 {
+		return 0
+	}
+	return float64(common) / float64(union)
+}
+
+// cleanFilePath normalizes a file path for comparison
+func cleanFilePath(f string) string {
+	return path.Clean(strings.TrimPrefix(strings.TrimSpace(f), "./"))
+}
+
+// referenceLinks relates each block to the candidates whose IDs its text
+// mentions, when both belong to the same organization
+func referenceLinks(blocks, candidates []inferenceBlock) []types.Relationship {
+	byID := make(map[uuid.UUID]inferenceBlock, len(candidates))
+	for _, c := range candidates {
+		byID[c.ID] = c
+	}
+
+	var relationships []types.Relationship
+	for _, b := range blocks {
+		for _, id := range mentionedBlockIDs([]inferenceBlock{b}) {
+			c, ok := byID[id]
+			if !ok || c.ID == b.ID || !sameOrganization(b, c) {
+				continue
+			}
+			relationships = append(relationships, types.Relationship{
+				FromBlockID:      b.ID,
+				ToBlockID:        c.ID,
+				RelationshipType: types.RelationshipReferences,
+				Confidence:       1,
+			})
+		}
+	}
+	return relationships
+}
+
+// sessionIDs returns the distinct session IDs of the blocks
+func sessionIDs(blocks []inferenceBlock) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, b := range blocks {
+		if b.SessionID != "" && !seen[b.SessionID] {
+			seen[b.SessionID] = true
+			ids = append(ids, b.SessionID)
+		}
+	}
+	return ids
+}
+
+// modifiedFiles returns the distinct files the blocks' sessions modified
+func modifiedFiles(blocks []inferenceBlock) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, b := range blocks {
+		for _, f := range b.Files {
+			if !seen[f] {
+				seen[f] = true
+				files = append(files, f)
+			}
+		}
+	}
+	return files
+}
+
+// mentionedBlockIDs returns the distinct block IDs mentioned in the blocks' text
+func mentionedBlockIDs(blocks []inferenceBlock) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool)
+	var ids []uuid.UUID
+	for _, b := range blocks {
+		for _, match := range blockIDPattern.FindAllString(b.Text, -1) {
+			id, err := uuid.Parse(match)
+			if err != nil || seen[id] {
+				continue
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// mergeRelationships drops duplicate relationships, keeping the highest
+// confidence. related-to is symmetric, so it is merged in either direction.
+func mergeRelationships(relationships []types.Relationship) []types.Relationship {
+	type edgeKey struct {
+		from, to uuid.UUID
+		relType  string
+	}
+
+	index := make(map[edgeKey]int)
+	var merged []types.Relationship
+	for _, rel := range relationships {
+		key := edgeKey{rel.FromBlockID, rel.ToBlockID, rel.RelationshipType}
+		if rel.RelationshipType == types.RelationshipRelatedTo && key.to.String() < key.from.String() {
+			key.from, key.to = key.to, key.from
+		}
+
+		if i, ok := index[key]; ok {
+			merged[i].Confidence = max(merged[i].Confidence, rel.Confidence)
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, rel)
+	}
+	return merged
+}
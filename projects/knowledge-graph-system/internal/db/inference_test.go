@@ -0,0 +1,137 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeInference(t *testing.T) {
+	opts, err := normalizeInference(types.InferenceOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, defaultSimilarityThreshold, opts.SimilarityThreshold)
+	assert.Equal(t, defaultMaxSimilar, opts.MaxSimilar)
+	assert.Equal(t, defaultFileOverlapThreshold, opts.FileOverlapThreshold)
+
+	opts, err = normalizeInference(types.InferenceOptions{SimilarityThreshold: 0.7, MaxSimilar: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 0.7, opts.SimilarityThreshold)
+	assert.Equal(t, 2, opts.MaxSimilar)
+
+	_, err = normalizeInference(types.InferenceOptions{Signals: []string{"telepathy"}})
+	assert.Error(t, err)
+}
+
+func TestContinuationLinks(t *testing.T) {
+	org := uuid.New()
+	chunk := func(session, visibility string, n int) inferenceBlock {
+		return inferenceBlock{ID: uuid.New(), OrganizationID: &org, Visibility: visibility, SessionID: session, ChunkNumber: n}
+	}
+
+	first := chunk("s1", "org-private", 1)
+	second := chunk("s1", "org-private", 2)
+	third := chunk("s1", "org-private", 3)
+	anonymized := chunk("s1", "anonymized", 1)
+	other := chunk("s2", "org-private", 1)
+
+	t.Run("new chunk continues the previous one", func(t *testing.T) {
+		rels := continuationLinks([]inferenceBlock{third}, []inferenceBlock{first, second, third, anonymized, other})
+		require.Len(t, rels, 1)
+		assert.Equal(t, third.ID, rels[0].FromBlockID)
+		assert.Equal(t, second.ID, rels[0].ToBlockID)
+		assert.Equal(t, types.RelationshipContinues, rels[0].RelationshipType)
+		assert.Equal(t, 1.0, rels[0].Confidence)
+	})
+
+	t.Run("a whole new session forms a chain", func(t *testing.T) {
+		all := []inferenceBlock{first, second, third}
+		rels := mergeRelationships(continuationLinks(all, all))
+		assert.Len(t, rels, 2)
+	})
+
+	t.Run("anonymized copies are not linked to the originals", func(t *testing.T) {
+		copyTwo := chunk("s1", "anonymized", 2)
+		rels := continuationLinks([]inferenceBlock{copyTwo}, []inferenceBlock{first, second, anonymized, copyTwo})
+		require.Len(t, rels, 1)
+		assert.Equal(t, anonymized.ID, rels[0].ToBlockID)
+	})
+
+	t.Run("blocks outside a session are ignored", func(t *testing.T) {
+		assert.Empty(t, continuationLinks([]inferenceBlock{{ID: uuid.New()}}, []inferenceBlock{first}))
+	})
+}
+
+func TestSharedFileLinks(t *testing.T) {
+	org := uuid.New()
+	session := func(file string, chunk int, files ...string) inferenceBlock {
+		return inferenceBlock{ID: uuid.New(), SourceFile: file, OrganizationID: &org, Visibility: "org-private", ChunkNumber: chunk, Files: files}
+	}
+
+	newFirst := session("new.md", 1, "internal/db/search.go", "./schema.sql")
+	newSecond := session("new.md", 2, "internal/db/search.go", "./schema.sql")
+	related := session("old.md", 1, "internal/db/search.go", "schema.sql", "README.md")
+	relatedLater := session("old.md", 2, "internal/db/search.go", "schema.sql", "README.md")
+	unrelated := session("other.md", 1, "cmd/kg/main.go")
+
+	rels := sharedFileLinks([]inferenceBlock{newSecond, newFirst},
+		[]inferenceBlock{newFirst, newSecond, relatedLater, related, unrelated}, 0.3)
+	require.Len(t, rels, 1, "one edge per pair of sessions, between their first chunks")
+	assert.Equal(t, newFirst.ID, rels[0].FromBlockID)
+	assert.Equal(t, related.ID, rels[0].ToBlockID)
+	assert.Equal(t, types.RelationshipRelatedTo, rels[0].RelationshipType)
+	assert.InDelta(t, 2.0/3.0, rels[0].Confidence, 1e-9)
+
+	assert.Empty(t, sharedFileLinks([]inferenceBlock{newFirst}, []inferenceBlock{related}, 0.9), "below the threshold")
+
+	otherOrg := uuid.New()
+	foreign := related
+	foreign.ID, foreign.SourceFile, foreign.OrganizationID = uuid.New(), "foreign.md", &otherOrg
+	assert.Empty(t, sharedFileLinks([]inferenceBlock{newFirst}, []inferenceBlock{foreign}, 0.3), "other organizations are never linked")
+}
+
+func TestFileOverlap(t *testing.T) {
+	assert.Equal(t, 1.0, fileOverlap([]string{"a.go", "./b.go"}, []string{"b.go", "a.go"}))
+	assert.Equal(t, 0.5, fileOverlap([]string{"a.go", "b.go"}, []string{"a.go"}))
+	assert.Equal(t, 0.0, fileOverlap([]string{"a.go"}, []string{"c.go"}))
+	assert.Equal(t, 0.0, fileOverlap(nil, nil))
+}
+
+func TestReferenceLinks(t *testing.T) {
+	org := uuid.New()
+	target := inferenceBlock{ID: uuid.New(), OrganizationID: &org}
+	otherOrg := uuid.New()
+	foreign := inferenceBlock{ID: uuid.New(), OrganizationID: &otherOrg}
+	missing := uuid.New()
+
+	block := inferenceBlock{
+		ID:             uuid.New(),
+		OrganizationID: &org,
+		Text:           "Builds on " + target.ID.String() + " (see also " + target.ID.String() + ", " + foreign.ID.String() + " and " + missing.String() + ")",
+	}
+
+	mentioned := mentionedBlockIDs([]inferenceBlock{block})
+	assert.Equal(t, []uuid.UUID{target.ID, foreign.ID, missing}, mentioned)
+
+	rels := referenceLinks([]inferenceBlock{block}, []inferenceBlock{target, foreign})
+	require.Len(t, rels, 1)
+	assert.Equal(t, block.ID, rels[0].FromBlockID)
+	assert.Equal(t, target.ID, rels[0].ToBlockID)
+	assert.Equal(t, types.RelationshipReferences, rels[0].RelationshipType)
+}
+
+func TestMergeRelationships(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	rels := mergeRelationships([]types.Relationship{
+		{FromBlockID: a, ToBlockID: b, RelationshipType: types.RelationshipRelatedTo, Confidence: 0.4},
+		{FromBlockID: b, ToBlockID: a, RelationshipType: types.RelationshipRelatedTo, Confidence: 0.9},
+		{FromBlockID: a, ToBlockID: b, RelationshipType: types.RelationshipReferences, Confidence: 1},
+		{FromBlockID: b, ToBlockID: a, RelationshipType: types.RelationshipReferences, Confidence: 1},
+	})
+
+	require.Len(t, rels, 3, "related-to is merged in either direction, references are not")
+	assert.Equal(t, a, rels[0].FromBlockID)
+	assert.Equal(t, 0.9, rels[0].Confidence)
+}
@@ -5,18 +5,21 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/TheGenXCoder/knowledge-graph/internal/core"
 	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
 	"github.com/google/uuid"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/pgvector/pgvector-go"
 )
 
 type PostgresDB struct {
-	db       *sql.DB
-	embedder core.Embedder
+	db        *sql.DB
+	embedder  core.Embedder
+	inference types.InferenceOptions // Relationships SaveBlock infers (zero value: all signals)
+	tagger    core.TagExtractor      // Tags blocks saved with SaveBlock (nil: no tags)
 }
 
 // NewPostgresDB creates a new PostgreSQL knowledge graph
@@ -42,10 +45,29 @@ func NewPostgresDB(connStr string, embedder core.Embedder) (*PostgresDB, error)
 	}, nil
 }
 
-// Search performs hybrid semantic + keyword search
+// Embedder returns the embedder used for blocks, exchanges and queries
+func (p *PostgresDB) Embedder() core.Embedder {
+	return p.embedder
+}
+
+// Search performs hybrid semantic + keyword search over blocks and their
+// exchanges. Exchange hits count toward their parent block, and each result
+// lists the exchanges that matched with highlighted snippets. The signals are
+// fused into one relevance as configured by opts.Ranking.
 func (p *PostgresDB) Search(ctx context.Context, query string, opts types.SearchOptions) (*types.SearchResults, error) {
 	start := time.Now()
 
+	if err := opts.Ranking.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Filter by the requested tags, their aliases and narrower tags
+	tags, err := p.ExpandTags(ctx, opts.Tags)
+	if err != nil {
+		return nil, err
+	}
+	opts.Tags = tags
+
 	// Generate embedding for query
 	embedding, err := p.embedder.Embed(ctx, query)
 	if err != nil {
@@ -57,137 +79,35 @@ func (p *PostgresDB) Search(ctx context.Context, query string, opts types.Search
 		opts.Limit = 10
 	}
 
-	// Build query with optional project filter
-	querySQL := `
-		WITH vector_search AS (
-			SELECT
-				b.id,
-				b.project_id,
-				b.topic,
-				b.started_at,
-				b.completed_at,
-				b.exchange_count,
-				b.metadata,
-				b.created_at,
-				b.updated_at,
-				b.visibility,
-				b.organization_id,
-				b.source_url,
-				b.source_attribution,
-				b.source_file,
-				b.source_type,
-				1 - (b.embedding <=> $1) AS similarity
-			FROM blocks b
-			WHERE ($2::uuid IS NULL OR b.project_id = $2)
-			  AND b.completed_at IS NOT NULL
-			ORDER BY b.embedding <=> $1
-			LIMIT $3
-		),
-		keyword_search AS (
-			SELECT
-				b.id,
-				ts_rank(to_tsvector('english', b.topic), plainto_tsquery($4)) AS rank
-			FROM blocks b
-			WHERE ($2::uuid IS NULL OR b.project_id = $2)
-			  AND to_tsvector('english', b.topic) @@ plainto_tsquery($4)
-			  AND b.completed_at IS NOT NULL
-			ORDER BY rank DESC
-			LIMIT $3
-		)
-		SELECT DISTINCT
-			v.id,
-			v.project_id,
-			v.topic,
-			v.started_at,
-			v.completed_at,
-			v.exchange_count,
-			v.metadata,
-			v.created_at,
-			v.updated_at,
-			v.visibility,
-			v.organization_id,
-			v.source_url,
-			v.source_attribution,
-			v.source_file,
-			v.source_type,
-			COALESCE(v.similarity, 0) + COALESCE(k.rank, 0) AS combined_score
-		FROM vector_search v
-		LEFT JOIN keyword_search k ON v.id = k.id
-		ORDER BY combined_score DESC
-		LIMIT $3
-	`
-
-	var projectID *uuid.UUID
-	if opts.ProjectID != nil {
-		projectID = opts.ProjectID
-	}
-
-	rows, err := p.db.QueryContext(ctx, querySQL,
-		pgvector.NewVector(toFloat32(embedding)),
-		projectID,
-		opts.Limit,
-		query,
-	)
+	hits, err := p.searchCandidates(ctx, query, embedding, opts, opts.Limit*candidatesPerResult)
 	if err != nil {
-		return nil, fmt.Errorf("search query failed: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
+	ranked := rankBlocks(aggregateHits(hits), opts, time.Now())
 
-	var results []types.SearchResult
-	for rows.Next() {
-		var block types.Block
-		var metadataJSON []byte
-		var relevance float64
-		var visibility, sourceURL, sourceAttribution, sourceFile, sourceType sql.NullString
-		var orgID *uuid.UUID
-
-		err := rows.Scan(
-			&block.ID,
-			&block.ProjectID,
-			&block.Topic,
-			&block.StartedAt,
-			&block.CompletedAt,
-			&block.ExchangeCount,
-			&metadataJSON,
-			&block.CreatedAt,
-			&block.UpdatedAt,
-			&visibility,
-			&orgID,
-			&sourceURL,
-			&sourceAttribution,
-			&sourceFile,
-			&sourceType,
-			&relevance,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan block: %w", err)
+	blockIDs := make([]uuid.UUID, len(ranked))
+	var exchangeIDs []uuid.UUID
+	for i, h := range ranked {
+		blockIDs[i] = h.blockID
+		for _, m := range h.matches {
+			exchangeIDs = append(exchangeIDs, m.ExchangeID)
 		}
+	}
 
-		// Set nullable fields
-		if visibility.Valid {
-			block.Visibility = visibility.String
-		}
-		if orgID != nil {
-			block.OrganizationID = orgID
-		}
-		if sourceURL.Valid {
-			block.SourceURL = sourceURL.String
-		}
-		if sourceAttribution.Valid {
-			block.SourceAttribution = sourceAttribution.String
-		}
-		if sourceFile.Valid {
-			block.SourceFile = sourceFile.String
-		}
-		if sourceType.Valid {
-			block.SourceType = sourceType.String
-		}
+	blocks, err := p.getBlocks(ctx, blockIDs, opts.Caller)
+	if err != nil {
+		return nil, err
+	}
+	snippets, err := p.exchangeSnippets(ctx, exchangeIDs, query)
+	if err != nil {
+		return nil, err
+	}
 
-		// Parse metadata
-		if len(metadataJSON) > 0 {
-			if err := json.Unmarshal(metadataJSON, &block.Metadata); err != nil {
-				block.Metadata = make(map[string]interface{})
-			}
+	var results []types.SearchResult
+	for _, h := range ranked {
+		block, ok := blocks[h.blockID]
+		if !ok {
+			continue
 		}
 
 		// Load exchanges for this block
@@ -204,14 +124,27 @@ func (p *PostgresDB) Search(ctx context.Context, query string, opts types.Search
 		}
 		block.Tags = tags
 
+		for i := range h.matches {
+			m := &h.matches[i]
+			for _, ex := range exchanges {
+				if ex.ID == m.ExchangeID {
+					m.Sequence = ex.Sequence
+				}
+			}
+			snippet := snippets[m.ExchangeID]
+			m.QuestionSnippet, m.AnswerSnippet = snippet[0], snippet[1]
+		}
+
 		result := types.SearchResult{
-			Block:     &block,
-			Relevance: relevance,
+			Block:     block,
+			Relevance: h.relevance,
+			Scores:    h.scores(),
+			Matches:   h.matches,
 		}
 
 		// Load N+1 related blocks if requested
 		if opts.IncludeNPlus {
-			related, err := p.getRelatedBlocks(ctx, block.ID)
+			related, err := p.getRelatedBlocks(ctx, block.ID, opts.Caller)
 			if err != nil {
 				return nil, fmt.Errorf("failed to load related blocks: %w", err)
 			}
@@ -230,7 +163,9 @@ func (p *PostgresDB) Search(ctx context.Context, query string, opts types.Search
 	}, nil
 }
 
-// SaveBlock saves a conversation block
+// SaveBlock saves a conversation block, tags it and relates it to existing blocks.
+// A block without a visibility is org-private, and one without an organization
+// belongs to the personal organization, as for the column defaults.
 func (p *PostgresDB) SaveBlock(ctx context.Context, block *types.Block) error {
 	// Generate embedding for topic
 	topicText := block.Topic
@@ -254,12 +189,24 @@ func (p *PostgresDB) SaveBlock(ctx context.Context, block *types.Block) error {
 	block.ID = uuid.New()
 	block.CreatedAt = time.Now()
 	block.UpdatedAt = time.Now()
+	if block.Visibility == "" {
+		block.Visibility = "org-private"
+	}
 
-	_, err = p.db.ExecContext(ctx, `
-		INSERT INTO blocks (id, project_id, topic, started_at, completed_at, exchange_count, embedding, metadata, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	err = p.db.QueryRowContext(ctx, `
+		INSERT INTO blocks (
+			id, project_id, topic, started_at, completed_at, exchange_count,
+			embedding, metadata, created_at, updated_at,
+			visibility, organization_id, owner_id, team
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11,
+			COALESCE($12, (SELECT id FROM organizations WHERE name = 'personal')),
+			NULLIF($13, ''), NULLIF($14, '')
+		)
+		RETURNING organization_id
 	`, block.ID, block.ProjectID, block.Topic, block.StartedAt, block.CompletedAt,
-		block.ExchangeCount, pgvector.NewVector(toFloat32(embedding)), metadataJSON, block.CreatedAt, block.UpdatedAt)
+		block.ExchangeCount, pgvector.NewVector(toFloat32(embedding)), metadataJSON, block.CreatedAt, block.UpdatedAt,
+		block.Visibility, block.OrganizationID, block.OwnerID, block.Team).Scan(&block.OrganizationID)
 
 	if err != nil {
 		return fmt.Errorf("failed to insert block: %w", err)
@@ -276,88 +223,50 @@ func (p *PostgresDB) SaveBlock(ctx context.Context, block *types.Block) error {
 
 	// Extract and save tags
 	if len(block.Exchanges) > 0 {
-		// Combine all exchanges for tag extraction
-		var content string
+		// Combine the topic and all exchanges for tag extraction
+		content := block.Topic + "\n"
 		for _, ex := range block.Exchanges {
 			content += ex.Question + " " + ex.Answer + " "
 		}
 
-		tags, err := p.ExtractTags(ctx, content)
+		tags, err := p.extractTags(ctx, content)
 		if err != nil {
 			// Don't fail the whole operation if tag extraction fails
-			fmt.Printf("Warning: failed to extract tags: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Warning: failed to extract tags: %v\n", err)
 		} else {
-			if err := p.saveBlockTags(ctx, block.ID, tags); err != nil {
+			if block.Tags, err = p.saveBlockTags(ctx, block.ID, tags); err != nil {
 				return fmt.Errorf("failed to save tags: %w", err)
 			}
 		}
 	}
 
+	// Infer relationships to existing blocks (best effort, like tagging)
+	if _, err := p.InferRelationships(ctx, []uuid.UUID{block.ID}, p.inference); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to infer relationships: %v\n", err)
+	}
+
 	return nil
 }
 
-// GetBlock retrieves a block by ID
-func (p *PostgresDB) GetBlock(ctx context.Context, id uuid.UUID) (*types.Block, error) {
-	var block types.Block
-	var metadataJSON []byte
-	var visibility, sourceURL, sourceAttribution, sourceFile, sourceType sql.NullString
-	var orgID *uuid.UUID
-
-	err := p.db.QueryRowContext(ctx, `
-		SELECT id, project_id, topic, started_at, completed_at, exchange_count, metadata, created_at, updated_at,
-		       visibility, organization_id, source_url, source_attribution, source_file, source_type
-		FROM blocks
-		WHERE id = $1
-	`, id).Scan(
-		&block.ID,
-		&block.ProjectID,
-		&block.Topic,
-		&block.StartedAt,
-		&block.CompletedAt,
-		&block.ExchangeCount,
-		&metadataJSON,
-		&block.CreatedAt,
-		&block.UpdatedAt,
-		&visibility,
-		&orgID,
-		&sourceURL,
-		&sourceAttribution,
-		&sourceFile,
-		&sourceType,
-	)
+// GetBlock retrieves a block by ID. A block the caller may not see is reported
+// as not found, so its existence is not revealed.
+func (p *PostgresDB) GetBlock(ctx context.Context, id uuid.UUID, caller types.Caller) (*types.Block, error) {
+	visible, visibleArgs := visibilityFilter(2, caller)
+	block, err := scanBlock(p.db.QueryRowContext(ctx, `
+		SELECT `+blockColumns+`
+		FROM blocks b
+		WHERE b.id = $1
+		  AND `+visible, append([]interface{}{id}, visibleArgs...)...))
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("block not found: %s", id)
+		return nil, fmt.Errorf("%w: %s", core.ErrBlockNotFound, id)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query block: %w", err)
 	}
 
-	// Set nullable fields
-	if visibility.Valid {
-		block.Visibility = visibility.String
-	}
-	if orgID != nil {
-		block.OrganizationID = orgID
-	}
-	if sourceURL.Valid {
-		block.SourceURL = sourceURL.String
-	}
-	if sourceAttribution.Valid {
-		block.SourceAttribution = sourceAttribution.String
-	}
-	if sourceFile.Valid {
-		block.SourceFile = sourceFile.String
-	}
-	if sourceType.Valid {
-		block.SourceType = sourceType.String
-	}
-
-	// Parse metadata
-	if len(metadataJSON) > 0 {
-		if err := json.Unmarshal(metadataJSON, &block.Metadata); err != nil {
-			block.Metadata = make(map[string]interface{})
-		}
+	if !caller.CanView(block) {
+		return nil, fmt.Errorf("%w: %s", core.ErrBlockNotFound, id)
 	}
 
 	// Load exchanges
@@ -374,26 +283,36 @@ func (p *PostgresDB) GetBlock(ctx context.Context, id uuid.UUID) (*types.Block,
 	}
 	block.Tags = tags
 
-	return &block, nil
+	return block, nil
 }
 
-// GetContextNPlusOne gets N+1 context bundle
-func (p *PostgresDB) GetContextNPlusOne(ctx context.Context, blockID uuid.UUID) (*types.ContextBundle, error) {
+// GetContextNPlusOne gets N+1 context bundle of blocks the caller may see
+func (p *PostgresDB) GetContextNPlusOne(ctx context.Context, blockID uuid.UUID, caller types.Caller) (*types.ContextBundle, error) {
 	// Get primary block
-	block, err := p.GetBlock(ctx, blockID)
+	block, err := p.GetBlock(ctx, blockID, caller)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get related blocks (one hop away)
-	related, err := p.getRelatedBlocks(ctx, blockID)
+	related, err := p.getRelatedBlocks(ctx, blockID, caller)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get related blocks: %w", err)
 	}
 
+	// Get relationship edges (one hop away)
+	edges, err := p.getEdges(ctx, blockID, caller)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relationships: %w", err)
+	}
+	for _, edge := range edges {
+		block.Relationships = append(block.Relationships, edge.Relationship)
+	}
+
 	return &types.ContextBundle{
 		PrimaryBlock:  block,
 		RelatedBlocks: related,
+		Edges:         edges,
 		Tags:          block.Tags,
 	}, nil
 }
@@ -404,6 +323,25 @@ func (p *PostgresDB) SearchProject(ctx context.Context, projectID uuid.UUID, que
 	return p.Search(ctx, query, opts)
 }
 
+// GetProject retrieves a project by ID
+func (p *PostgresDB) GetProject(ctx context.Context, id uuid.UUID) (*types.Project, error) {
+	var project types.Project
+	err := p.db.QueryRowContext(ctx, `
+		SELECT id, name, directory_path, created_at, updated_at
+		FROM projects
+		WHERE id = $1
+	`, id).Scan(&project.ID, &project.Name, &project.DirectoryPath, &project.CreatedAt, &project.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", core.ErrProjectNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project: %w", err)
+	}
+
+	return &project, nil
+}
+
 // GetOrCreateProject gets or creates a project
 func (p *PostgresDB) GetOrCreateProject(ctx context.Context, name string, directory string) (*types.Project, error) {
 	// Try to get existing
@@ -470,13 +408,19 @@ func (p *PostgresDB) SaveExchange(ctx context.Context, exchange *types.Exchange)
 	return nil
 }
 
-// ExtractTags uses Ollama to extract tags (Week 1: simple keyword extraction)
+// ExtractTags extracts semantic tags from content with the configured tag
+// extractor (see SetTagExtractor), most confident first
 func (p *PostgresDB) ExtractTags(ctx context.Context, content string) ([]string, error) {
-	// Week 1: Simple keyword extraction (no LLM call to keep it fast)
-	// Week 2: Use Ollama for better tag extraction
+	tags, err := p.extractTags(ctx, content)
+	if err != nil {
+		return nil, err
+	}
 
-	// For now, return empty - we'll implement proper tag extraction next
-	return []string{}, nil
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names, nil
 }
 
 // Close closes the database connection
@@ -496,6 +440,59 @@ func (p *PostgresDB) QueryRow(ctx context.Context, query string, args ...interfa
 
 // Helper methods
 
+// blockColumns are the block columns read by scanBlock, from blocks aliased b
+const blockColumns = `b.id, b.project_id, b.topic, b.started_at, b.completed_at, b.exchange_count, b.metadata, b.created_at, b.updated_at,
+		       b.visibility, b.organization_id, b.source_url, b.source_attribution, b.source_file, b.source_type,
+		       b.owner_id, b.team`
+
+// scanBlock scans a row of blockColumns
+func scanBlock(row interface{ Scan(...interface{}) error }) (*types.Block, error) {
+	var block types.Block
+	var metadataJSON []byte
+	var visibility, sourceURL, sourceAttribution, sourceFile, sourceType, ownerID, team sql.NullString
+
+	err := row.Scan(
+		&block.ID,
+		&block.ProjectID,
+		&block.Topic,
+		&block.StartedAt,
+		&block.CompletedAt,
+		&block.ExchangeCount,
+		&metadataJSON,
+		&block.CreatedAt,
+		&block.UpdatedAt,
+		&visibility,
+		&block.OrganizationID,
+		&sourceURL,
+		&sourceAttribution,
+		&sourceFile,
+		&sourceType,
+		&ownerID,
+		&team,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set nullable fields
+	block.Visibility = visibility.String
+	block.SourceURL = sourceURL.String
+	block.SourceAttribution = sourceAttribution.String
+	block.SourceFile = sourceFile.String
+	block.SourceType = sourceType.String
+	block.OwnerID = ownerID.String
+	block.Team = team.String
+
+	// Parse metadata
+	if len(metadataJSON) > 0 {
+		if err := json.Unmarshal(metadataJSON, &block.Metadata); err != nil {
+			block.Metadata = make(map[string]interface{})
+		}
+	}
+
+	return &block, nil
+}
+
 func (p *PostgresDB) getBlockExchanges(ctx context.Context, blockID uuid.UUID) ([]types.Exchange, error) {
 	rows, err := p.db.QueryContext(ctx, `
 		SELECT id, block_id, sequence, question, answer, timestamp, model_used
@@ -552,20 +549,28 @@ func (p *PostgresDB) getBlockTags(ctx context.Context, blockID uuid.UUID) ([]typ
 	return tags, nil
 }
 
-func (p *PostgresDB) getRelatedBlocks(ctx context.Context, blockID uuid.UUID) ([]*types.Block, error) {
-	// Get blocks related via tags (N+1: one hop away)
+// tagNeighborLimit caps the blocks returned as related through shared tags
+const tagNeighborLimit = 5
+
+func (p *PostgresDB) getRelatedBlocks(ctx context.Context, blockID uuid.UUID, caller types.Caller) ([]*types.Block, error) {
+	// Get blocks related via tags (N+1: one hop away) that the caller may see,
+	// those sharing the most tags first
+	visible, visibleArgs := visibilityFilter(3, caller)
 	rows, err := p.db.QueryContext(ctx, `
-		SELECT DISTINCT b.id, b.project_id, b.topic, b.started_at, b.completed_at,
-		       b.exchange_count, b.metadata, b.created_at, b.updated_at
+		SELECT `+blockColumns+`
 		FROM blocks b
-		JOIN block_tags bt ON b.id = bt.block_id
-		WHERE bt.tag_id IN (
-			SELECT tag_id FROM block_tags WHERE block_id = $1
-		)
-		AND b.id != $1
-		AND b.completed_at IS NOT NULL
-		LIMIT 5
-	`, blockID)
+		JOIN (
+			SELECT bt.block_id, COUNT(*) AS shared
+			FROM block_tags bt
+			WHERE bt.tag_id IN (SELECT tag_id FROM block_tags WHERE block_id = $1)
+			  AND bt.block_id != $1
+			GROUP BY bt.block_id
+		) s ON s.block_id = b.id
+		WHERE b.completed_at IS NOT NULL
+		  AND `+visible+`
+		ORDER BY s.shared DESC, b.created_at DESC
+		LIMIT $2
+	`, append([]interface{}{blockID, tagNeighborLimit}, visibleArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -573,88 +578,73 @@ func (p *PostgresDB) getRelatedBlocks(ctx context.Context, blockID uuid.UUID) ([
 
 	var blocks []*types.Block
 	for rows.Next() {
-		var block types.Block
-		var metadataJSON []byte
-
-		err := rows.Scan(
-			&block.ID,
-			&block.ProjectID,
-			&block.Topic,
-			&block.StartedAt,
-			&block.CompletedAt,
-			&block.ExchangeCount,
-			&metadataJSON,
-			&block.CreatedAt,
-			&block.UpdatedAt,
-		)
+		block, err := scanBlock(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		if len(metadataJSON) > 0 {
-			json.Unmarshal(metadataJSON, &block.Metadata)
-		}
-
-		blocks = append(blocks, &block)
+		blocks = append(blocks, block)
 	}
 
-	return blocks, nil
+	return visibleBlocks(caller, blocks), rows.Err()
 }
 
-func (p *PostgresDB) saveBlockTags(ctx context.Context, blockID uuid.UUID, tagNames []string) error {
-	for _, tagName := range tagNames {
+// saveBlockTags links a block to its extracted tags, creating missing tags, and
+// returns the tags linked. Tags named by an alias link the tag it resolves to.
+func (p *PostgresDB) saveBlockTags(ctx context.Context, blockID uuid.UUID, extracted []types.ExtractedTag) ([]types.Tag, error) {
+	var tags []types.Tag
+	linked := make(map[uuid.UUID]bool)
+	for _, extractedTag := range extracted {
 		// Get or create tag
-		var tagID uuid.UUID
-		err := p.db.QueryRowContext(ctx, `
-			INSERT INTO tags (id, name, created_at)
-			VALUES ($1, $2, $3)
-			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
-			RETURNING id
-		`, uuid.New(), tagName, time.Now()).Scan(&tagID)
-
+		tag, err := getOrCreateTag(ctx, p.db, extractedTag.Name)
 		if err != nil {
-			// Try to get existing
-			err = p.db.QueryRowContext(ctx, `SELECT id FROM tags WHERE name = $1`, tagName).Scan(&tagID)
-			if err != nil {
-				return fmt.Errorf("failed to get/create tag %s: %w", tagName, err)
-			}
+			return nil, err
 		}
 
-		// Link tag to block
+		// Link tag to block with the extractor's confidence
 		_, err = p.db.ExecContext(ctx, `
 			INSERT INTO block_tags (block_id, tag_id, confidence, created_at)
 			VALUES ($1, $2, $3, $4)
-			ON CONFLICT (block_id, tag_id) DO NOTHING
-		`, blockID, tagID, 1.0, time.Now())
+			ON CONFLICT (block_id, tag_id) DO UPDATE SET confidence = GREATEST(block_tags.confidence, EXCLUDED.confidence)
+		`, blockID, tag.ID, extractedTag.Confidence, time.Now())
 
 		if err != nil {
-			return fmt.Errorf("failed to link tag %s to block: %w", tagName, err)
+			return nil, fmt.Errorf("failed to link tag %s to block: %w", tag.Name, err)
+		}
+		if !linked[tag.ID] {
+			linked[tag.ID] = true
+			tags = append(tags, tag)
 		}
 	}
 
-	return nil
+	return tags, nil
+}
+
+// importablePreBlock is the accessor set ImportBlock needs from an importer PreBlock
+// (declared here to avoid an import cycle with the importer packages)
+type importablePreBlock interface {
+	GetTopic() string
+	GetExchanges() []interface{}
+	GetMetadata() map[string]interface{}
+	GetTags() []string
+	GetProjectPath() string
+	GetSourceFile() string
+	GetSourceType() string
+	GetSourceHash() string
+	GetStartedAt() time.Time
+	GetCompletedAt() *time.Time
+	GetVisibility() string
+	GetOrganizationID() *uuid.UUID
+	GetSourceURL() string
+	GetSourceAttribution() string
+	GetOwnerID() string
 }
 
 // ImportBlock imports a PreBlock into the database with full transaction support
-// This is used by the importer to insert blocks with source tracking and import history
+// This is used by the importer to insert blocks with source tracking and import history.
+// The batch stays in-progress until CompleteImportBatch or FailImportBatch is called.
 func (p *PostgresDB) ImportBlock(ctx context.Context, preBlock interface{}, batchID uuid.UUID) (*types.Block, error) {
 	// Type assertion to get the actual PreBlock
-	pb, ok := preBlock.(interface {
-		GetTopic() string
-		GetExchanges() []interface{}
-		GetMetadata() map[string]interface{}
-		GetTags() []string
-		GetProjectPath() string
-		GetSourceFile() string
-		GetSourceType() string
-		GetSourceHash() string
-		GetStartedAt() time.Time
-		GetCompletedAt() *time.Time
-		GetVisibility() string
-		GetOrganizationID() *uuid.UUID
-		GetSourceURL() string
-		GetSourceAttribution() string
-	})
+	pb, ok := preBlock.(importablePreBlock)
 	if !ok {
 		return nil, fmt.Errorf("invalid PreBlock type")
 	}
@@ -666,6 +656,149 @@ func (p *PostgresDB) ImportBlock(ctx context.Context, preBlock interface{}, batc
 	}
 	defer tx.Rollback()
 
+	block, err := p.importBlockTx(ctx, tx, pb, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Count the block against its import batch
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE import_history
+		SET block_count = block_count + 1,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, batchID); err != nil {
+		return nil, fmt.Errorf("failed to update import history: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return block, nil
+}
+
+// ReplaceSourceBlocks atomically swaps every block imported from sourceFile for the
+// given PreBlocks. Tags and relationships of old blocks carry over to the new block
+// with the same topic; old blocks are then deleted and the batch marked completed.
+func (p *PostgresDB) ReplaceSourceBlocks(ctx context.Context, sourceFile string, preBlocks []interface{}, batchID uuid.UUID) (*ReplaceResult, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Step 1: Lock the stale blocks for this source
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, topic
+		FROM blocks
+		WHERE source_file = $1
+		FOR UPDATE
+	`, sourceFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query existing blocks: %w", err)
+	}
+
+	oldTopics := make(map[uuid.UUID]string)
+	var oldIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		var topic string
+		if err := rows.Scan(&id, &topic); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan existing block: %w", err)
+		}
+		oldTopics[id] = topic
+		oldIDs = append(oldIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read existing blocks: %w", err)
+	}
+
+	// Step 2: Insert the new blocks
+	result := &ReplaceResult{Replaced: len(oldIDs)}
+	newByTopic := make(map[string]uuid.UUID)
+	for i, preBlock := range preBlocks {
+		pb, ok := preBlock.(importablePreBlock)
+		if !ok {
+			return nil, fmt.Errorf("invalid PreBlock type at index %d", i)
+		}
+
+		block, err := p.importBlockTx(ctx, tx, pb, batchID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import block %d: %w", i, err)
+		}
+		result.Blocks = append(result.Blocks, block)
+
+		if _, exists := newByTopic[block.Topic]; !exists {
+			newByTopic[block.Topic] = block.ID
+		}
+	}
+
+	// Step 3: Re-link tags and relationships from old blocks to their successors
+	successors := make(map[uuid.UUID]uuid.UUID)
+	for oldID, topic := range oldTopics {
+		if newID, ok := newByTopic[topic]; ok {
+			successors[oldID] = newID
+		}
+	}
+
+	if err := relinkTagsTx(ctx, tx, successors); err != nil {
+		return nil, err
+	}
+	if err := relinkRelationshipsTx(ctx, tx, oldIDs, successors); err != nil {
+		return nil, err
+	}
+
+	// Step 4: Delete stale blocks (exchanges, tags and edges cascade)
+	if len(oldIDs) > 0 {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM blocks WHERE id = ANY($1::uuid[])`, pq.Array(uuidStrings(oldIDs))); err != nil {
+			return nil, fmt.Errorf("failed to delete stale blocks: %w", err)
+		}
+	}
+
+	// Step 5: Record the new hash and retire older import records for this file
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE import_history
+		SET status = 'superseded',
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE source_file = $1 AND id != $2 AND status = 'completed'
+	`, sourceFile, batchID); err != nil {
+		return nil, fmt.Errorf("failed to supersede import history: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE import_history
+		SET status = 'completed',
+		    block_count = $1,
+		    error_message = '',
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, len(result.Blocks), batchID); err != nil {
+		return nil, fmt.Errorf("failed to update import history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// CompleteImportBatch marks an import batch as completed once all of its blocks are stored
+func (p *PostgresDB) CompleteImportBatch(ctx context.Context, batchID uuid.UUID) error {
+	return p.updateImportHistoryTx(ctx, p.db, batchID, "", "", "completed", "")
+}
+
+// FailImportBatch marks an import batch as failed with an error message
+func (p *PostgresDB) FailImportBatch(ctx context.Context, batchID uuid.UUID, errorMessage string) error {
+	return p.updateImportHistoryTx(ctx, p.db, batchID, "", "", "failed", errorMessage)
+}
+
+// importBlockTx inserts a block, its exchanges and tags inside an existing transaction
+func (p *PostgresDB) importBlockTx(ctx context.Context, tx *sql.Tx, pb importablePreBlock, batchID uuid.UUID) (*types.Block, error) {
 	// Step 1: Get or create organization
 	orgID := pb.GetOrganizationID()
 	if orgID == nil {
@@ -695,22 +828,33 @@ func (p *PostgresDB) ImportBlock(ctx context.Context, preBlock interface{}, batc
 		}
 	}
 
-	embedding, err := p.embedder.Embed(ctx, topicText)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate embedding: %w", err)
+	embedding := precomputedEmbedding(pb)
+	if embedding == nil {
+		embedding, err = p.embedder.Embed(ctx, topicText)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate embedding: %w", err)
+		}
 	}
 
 	// Step 4: Insert block
 	block := &types.Block{
-		ID:            uuid.New(),
-		ProjectID:     project.ID,
-		Topic:         pb.GetTopic(),
-		StartedAt:     pb.GetStartedAt(),
-		CompletedAt:   pb.GetCompletedAt(),
-		ExchangeCount: len(exchanges),
-		Metadata:      pb.GetMetadata(),
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		ID:                uuid.New(),
+		ProjectID:         project.ID,
+		Topic:             pb.GetTopic(),
+		StartedAt:         pb.GetStartedAt(),
+		CompletedAt:       pb.GetCompletedAt(),
+		ExchangeCount:     len(exchanges),
+		Metadata:          pb.GetMetadata(),
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+		Visibility:        pb.GetVisibility(),
+		OrganizationID:    orgID,
+		SourceURL:         pb.GetSourceURL(),
+		SourceAttribution: pb.GetSourceAttribution(),
+		SourceFile:        pb.GetSourceFile(),
+		SourceType:        pb.GetSourceType(),
+		SourceHash:        pb.GetSourceHash(),
+		OwnerID:           pb.GetOwnerID(),
 	}
 
 	metadataJSON, err := json.Marshal(block.Metadata)
@@ -723,13 +867,13 @@ func (p *PostgresDB) ImportBlock(ctx context.Context, preBlock interface{}, batc
 			id, project_id, topic, started_at, completed_at, exchange_count,
 			embedding, metadata, created_at, updated_at,
 			visibility, organization_id, source_url, source_attribution,
-			source_file, source_type, source_hash, import_batch_id
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+			source_file, source_type, source_hash, import_batch_id, owner_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, NULLIF($19, ''))
 	`, block.ID, block.ProjectID, block.Topic, block.StartedAt, block.CompletedAt,
 		block.ExchangeCount, pgvector.NewVector(toFloat32(embedding)), metadataJSON,
 		block.CreatedAt, block.UpdatedAt,
 		pb.GetVisibility(), orgID, pb.GetSourceURL(), pb.GetSourceAttribution(),
-		pb.GetSourceFile(), pb.GetSourceType(), pb.GetSourceHash(), batchID)
+		pb.GetSourceFile(), pb.GetSourceType(), pb.GetSourceHash(), batchID, block.OwnerID)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert block: %w", err)
@@ -757,11 +901,14 @@ func (p *PostgresDB) ImportBlock(ctx context.Context, preBlock interface{}, batc
 			ModelUsed: exTyped.GetModelUsed(),
 		}
 
-		// Generate embedding for exchange
-		exText := exchange.Question + " " + exchange.Answer
-		exEmbedding, err := p.embedder.Embed(ctx, exText)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate embedding for exchange %d: %w", i, err)
+		// Generate embedding for exchange unless the importer already did
+		exEmbedding := precomputedEmbedding(ex)
+		if exEmbedding == nil {
+			exText := exchange.Question + " " + exchange.Answer
+			exEmbedding, err = p.embedder.Embed(ctx, exText)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate embedding for exchange %d: %w", i, err)
+			}
 		}
 
 		_, err = tx.ExecContext(ctx, `
@@ -786,17 +933,16 @@ func (p *PostgresDB) ImportBlock(ctx context.Context, preBlock interface{}, batc
 		}
 	}
 
-	// Step 7: Update import history
-	if err := p.updateImportHistoryTx(ctx, tx, batchID, pb.GetSourceFile(), pb.GetSourceHash(), "completed", ""); err != nil {
-		return nil, fmt.Errorf("failed to update import history: %w", err)
-	}
+	return block, nil
+}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+// precomputedEmbedding returns an embedding the importer attached to a PreBlock or
+// PreExchange, or nil when it must be generated at insert time
+func precomputedEmbedding(v interface{}) []float64 {
+	if e, ok := v.(interface{ GetEmbedding() []float64 }); ok && len(e.GetEmbedding()) > 0 {
+		return e.GetEmbedding()
 	}
-
-	return block, nil
+	return nil
 }
 
 // GetOrCreateOrganization gets or creates an organization by name
@@ -804,18 +950,37 @@ func (p *PostgresDB) GetOrCreateOrganization(ctx context.Context, name, tier str
 	return p.getOrCreateOrganizationTx(ctx, p.db, name, tier)
 }
 
+// ResolveCaller identifies a user of the named organization for visibility
+// checks. An organization that does not exist yet leaves the caller with
+// access to public blocks only.
+func (p *PostgresDB) ResolveCaller(ctx context.Context, userID, organization string, teams []string) (types.Caller, error) {
+	caller := types.Caller{UserID: userID, Teams: teams}
+
+	var orgID uuid.UUID
+	err := p.db.QueryRowContext(ctx, `SELECT id FROM organizations WHERE name = $1`, organization).Scan(&orgID)
+	if err == sql.ErrNoRows {
+		return caller, nil
+	}
+	if err != nil {
+		return types.Caller{}, fmt.Errorf("failed to query organization: %w", err)
+	}
+
+	caller.OrganizationID = &orgID
+	return caller, nil
+}
+
 // QueryImportHistory queries the import history for a specific source file and hash
 func (p *PostgresDB) QueryImportHistory(ctx context.Context, sourceFile, fileHash string) (*ImportHistoryRecord, error) {
 	var record ImportHistoryRecord
 	err := p.db.QueryRowContext(ctx, `
-		SELECT id, source_file, file_hash, imported_at, updated_at, block_count,
+		SELECT id, source_file, file_hash, COALESCE(file_size, 0), imported_at, updated_at, block_count,
 		       import_type, status, visibility, source_classification, organization_id
 		FROM import_history
 		WHERE source_file = $1 AND file_hash = $2
 		ORDER BY imported_at DESC
 		LIMIT 1
 	`, sourceFile, fileHash).Scan(
-		&record.ID, &record.SourceFile, &record.FileHash, &record.ImportedAt,
+		&record.ID, &record.SourceFile, &record.FileHash, &record.FileSize, &record.ImportedAt,
 		&record.UpdatedAt, &record.BlockCount, &record.ImportType, &record.Status,
 		&record.Visibility, &record.SourceClassification, &record.OrganizationID)
 
@@ -829,10 +994,35 @@ func (p *PostgresDB) QueryImportHistory(ctx context.Context, sourceFile, fileHas
 	return &record, nil
 }
 
+// QueryLatestImport returns the most recent completed import of a source file, whatever its hash
+func (p *PostgresDB) QueryLatestImport(ctx context.Context, sourceFile string) (*ImportHistoryRecord, error) {
+	var record ImportHistoryRecord
+	err := p.db.QueryRowContext(ctx, `
+		SELECT id, source_file, file_hash, COALESCE(file_size, 0), imported_at, updated_at, block_count,
+		       import_type, status, visibility, source_classification, organization_id
+		FROM import_history
+		WHERE source_file = $1 AND status = 'completed'
+		ORDER BY imported_at DESC
+		LIMIT 1
+	`, sourceFile).Scan(
+		&record.ID, &record.SourceFile, &record.FileHash, &record.FileSize, &record.ImportedAt,
+		&record.UpdatedAt, &record.BlockCount, &record.ImportType, &record.Status,
+		&record.Visibility, &record.SourceClassification, &record.OrganizationID)
+
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest import: %w", err)
+	}
+
+	return &record, nil
+}
+
 // QueryBlocksBySource queries blocks by source file
 func (p *PostgresDB) QueryBlocksBySource(ctx context.Context, sourceFile string) ([]BlockSourceRecord, error) {
 	rows, err := p.db.QueryContext(ctx, `
-		SELECT id, source_file, source_hash, source_type, topic
+		SELECT id, source_file, source_hash, source_type, topic, exchange_count
 		FROM blocks
 		WHERE source_file = $1
 		ORDER BY created_at DESC
@@ -845,7 +1035,7 @@ func (p *PostgresDB) QueryBlocksBySource(ctx context.Context, sourceFile string)
 	var records []BlockSourceRecord
 	for rows.Next() {
 		var record BlockSourceRecord
-		if err := rows.Scan(&record.BlockID, &record.SourceFile, &record.SourceHash, &record.SourceType, &record.Topic); err != nil {
+		if err := rows.Scan(&record.BlockID, &record.SourceFile, &record.SourceHash, &record.SourceType, &record.Topic, &record.ExchangeCount); err != nil {
 			return nil, fmt.Errorf("failed to scan block source record: %w", err)
 		}
 		records = append(records, record)
@@ -854,20 +1044,213 @@ func (p *PostgresDB) QueryBlocksBySource(ctx context.Context, sourceFile string)
 	return records, nil
 }
 
-// CreateImportBatch creates a new import batch in the import history
-func (p *PostgresDB) CreateImportBatch(ctx context.Context, sourceFile, fileHash, importType, visibility, sourceClass string, orgID *uuid.UUID) (uuid.UUID, error) {
-	batchID := uuid.New()
+// QueryBlockFiles returns the blocks selected by query that list file paths under
+// its metadata key (e.g. "files" for git history blocks)
+func (p *PostgresDB) QueryBlockFiles(ctx context.Context, query BlockFilesQuery) ([]BlockFilesRecord, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT b.id, b.source_file, b.metadata -> $2
+		FROM blocks b
+		WHERE b.source_type = $1
+		  AND jsonb_typeof(b.metadata -> $2) = 'array'
+		  AND b.organization_id IS NOT DISTINCT FROM $3
+		  AND (cardinality($4::text[]) = 0 OR b.source_file = ANY($4::text[]))
+		  AND (cardinality($5::text[]) = 0 OR EXISTS (
+		      SELECT 1 FROM unnest($5::text[]) AS m(path)
+		      WHERE b.source_file LIKE m.path || '/%'
+		         OR (b.metadata -> $2)::text LIKE '%' || m.path || '%'))
+	`, query.SourceType, query.MetadataKey, query.OrganizationID, pq.Array(query.SourceFiles), pq.Array(query.Mentioning))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query block files: %w", err)
+	}
+	defer rows.Close()
+
+	var records []BlockFilesRecord
+	for rows.Next() {
+		var record BlockFilesRecord
+		var files []byte
+		if err := rows.Scan(&record.BlockID, &record.SourceFile, &files); err != nil {
+			return nil, fmt.Errorf("failed to scan block files: %w", err)
+		}
+		if err := json.Unmarshal(files, &record.Files); err != nil {
+			return nil, fmt.Errorf("failed to decode files of block %s: %w", record.BlockID, err)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read block files: %w", err)
+	}
+
+	return records, nil
+}
+
+// SaveRelationships stores block relationships in one transaction. Existing
+// relationships keep their creation time and take the new confidence.
+func (p *PostgresDB) SaveRelationships(ctx context.Context, relationships []types.Relationship) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, r := range relationships {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO block_relationships (from_block_id, to_block_id, relationship_type, confidence)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (from_block_id, to_block_id, relationship_type)
+			DO UPDATE SET confidence = EXCLUDED.confidence
+		`, r.FromBlockID, r.ToBlockID, r.RelationshipType, r.Confidence); err != nil {
+			return fmt.Errorf("failed to save relationship: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit relationships: %w", err)
+	}
+	return nil
+}
+
+// QueryInterruptedImports returns import batches still marked in-progress,
+// i.e. left behind by an import that crashed or was cancelled
+func (p *PostgresDB) QueryInterruptedImports(ctx context.Context) ([]ImportHistoryRecord, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT id, source_file, file_hash, COALESCE(file_size, 0), imported_at, updated_at, block_count,
+		       import_type, status, visibility, source_classification, organization_id
+		FROM import_history
+		WHERE status = 'in-progress'
+		ORDER BY imported_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query interrupted imports: %w", err)
+	}
+	defer rows.Close()
+
+	var records []ImportHistoryRecord
+	for rows.Next() {
+		var record ImportHistoryRecord
+		if err := rows.Scan(
+			&record.ID, &record.SourceFile, &record.FileHash, &record.FileSize, &record.ImportedAt,
+			&record.UpdatedAt, &record.BlockCount, &record.ImportType, &record.Status,
+			&record.Visibility, &record.SourceClassification, &record.OrganizationID); err != nil {
+			return nil, fmt.Errorf("failed to scan import history record: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// QueryBatchBlocks returns the blocks written by an import batch in insertion order
+func (p *PostgresDB) QueryBatchBlocks(ctx context.Context, batchID uuid.UUID) ([]BlockSourceRecord, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT id, source_file, source_hash, source_type, topic, exchange_count
+		FROM blocks
+		WHERE import_batch_id = $1
+		ORDER BY created_at, id
+	`, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batch blocks: %w", err)
+	}
+	defer rows.Close()
+
+	var records []BlockSourceRecord
+	for rows.Next() {
+		var record BlockSourceRecord
+		if err := rows.Scan(&record.BlockID, &record.SourceFile, &record.SourceHash, &record.SourceType, &record.Topic, &record.ExchangeCount); err != nil {
+			return nil, fmt.Errorf("failed to scan block source record: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// RollbackImportBatch deletes every block written by a batch and marks it failed
+func (p *PostgresDB) RollbackImportBatch(ctx context.Context, batchID uuid.UUID, reason string) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM blocks WHERE import_batch_id = $1`, batchID); err != nil {
+		return fmt.Errorf("failed to delete batch blocks: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE import_history
+		SET status = 'failed',
+		    block_count = 0,
+		    error_message = $1,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, reason, batchID); err != nil {
+		return fmt.Errorf("failed to update import history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSessionChunkTotal rewrites total_chunks in the metadata of every block
+// imported from sourceFile, after new chunks were appended to a growing log
+func (p *PostgresDB) UpdateSessionChunkTotal(ctx context.Context, sourceFile string, totalChunks int) error {
 	_, err := p.db.ExecContext(ctx, `
+		UPDATE blocks
+		SET metadata = jsonb_set(COALESCE(metadata, '{}'::jsonb), '{total_chunks}', to_jsonb($1::int)),
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE source_file = $2
+	`, totalChunks, sourceFile)
+	if err != nil {
+		return fmt.Errorf("failed to update session chunk total: %w", err)
+	}
+	return nil
+}
+
+// CreateImportBatch creates a new import batch in the import history
+// Re-importing a (source_file, file_hash) pair seen before reuses and resets that record,
+// discarding any blocks left behind by the earlier, unfinished attempt
+func (p *PostgresDB) CreateImportBatch(ctx context.Context, sourceFile, fileHash string, fileSize int64, importType, visibility, sourceClass string, orgID *uuid.UUID) (uuid.UUID, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var batchID uuid.UUID
+	err = tx.QueryRowContext(ctx, `
 		INSERT INTO import_history (
-			id, source_file, file_hash, block_count, import_type,
+			id, source_file, file_hash, file_size, block_count, import_type,
 			status, visibility, source_classification, organization_id
-		) VALUES ($1, $2, $3, 0, $4, 'in-progress', $5, $6, $7)
-	`, batchID, sourceFile, fileHash, importType, visibility, sourceClass, orgID)
+		) VALUES ($1, $2, $3, $4, 0, $5, 'in-progress', $6, $7, $8)
+		ON CONFLICT (source_file, file_hash) DO UPDATE
+		SET block_count = 0,
+		    file_size = EXCLUDED.file_size,
+		    import_type = EXCLUDED.import_type,
+		    status = 'in-progress',
+		    error_message = NULL,
+		    visibility = EXCLUDED.visibility,
+		    source_classification = EXCLUDED.source_classification,
+		    organization_id = EXCLUDED.organization_id,
+		    imported_at = CURRENT_TIMESTAMP,
+		    updated_at = CURRENT_TIMESTAMP
+		RETURNING id
+	`, uuid.New(), sourceFile, fileHash, fileSize, importType, visibility, sourceClass, orgID).Scan(&batchID)
 
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("failed to create import batch: %w", err)
 	}
 
+	if _, err := tx.ExecContext(ctx, `DELETE FROM blocks WHERE import_batch_id = $1`, batchID); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to discard blocks from earlier attempt: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return batchID, nil
 }
 
@@ -958,21 +1341,10 @@ func (p *PostgresDB) saveBlockTagsTx(ctx context.Context, db interface {
 	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 }, blockID uuid.UUID, tagNames []string) error {
 	for _, tagName := range tagNames {
-		// Get or create tag
-		var tagID uuid.UUID
-		err := db.QueryRowContext(ctx, `
-			INSERT INTO tags (id, name, created_at)
-			VALUES ($1, $2, $3)
-			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
-			RETURNING id
-		`, uuid.New(), tagName, time.Now()).Scan(&tagID)
-
+		// Get or create tag (an alias links the tag it resolves to)
+		tag, err := getOrCreateTag(ctx, db, tagName)
 		if err != nil {
-			// Try to get existing
-			err = db.QueryRowContext(ctx, `SELECT id FROM tags WHERE name = $1`, tagName).Scan(&tagID)
-			if err != nil {
-				return fmt.Errorf("failed to get/create tag %s: %w", tagName, err)
-			}
+			return err
 		}
 
 		// Link tag to block
@@ -980,7 +1352,7 @@ func (p *PostgresDB) saveBlockTagsTx(ctx context.Context, db interface {
 			INSERT INTO block_tags (block_id, tag_id, confidence, created_at)
 			VALUES ($1, $2, $3, $4)
 			ON CONFLICT (block_id, tag_id) DO NOTHING
-		`, blockID, tagID, 1.0, time.Now())
+		`, blockID, tag.ID, 1.0, time.Now())
 
 		if err != nil {
 			return fmt.Errorf("failed to link tag %s to block: %w", tagName, err)
@@ -1008,6 +1380,86 @@ func (p *PostgresDB) updateImportHistoryTx(ctx context.Context, db interface {
 	return nil
 }
 
+// relinkTagsTx copies tag links from each old block to its successor
+func relinkTagsTx(ctx context.Context, tx *sql.Tx, successors map[uuid.UUID]uuid.UUID) error {
+	for oldID, newID := range successors {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO block_tags (block_id, tag_id, confidence, created_at)
+			SELECT $1, tag_id, confidence, created_at
+			FROM block_tags
+			WHERE block_id = $2
+			ON CONFLICT (block_id, tag_id) DO NOTHING
+		`, newID, oldID)
+		if err != nil {
+			return fmt.Errorf("failed to relink tags for block %s: %w", oldID, err)
+		}
+	}
+	return nil
+}
+
+// relinkRelationshipsTx re-points edges touching old blocks at their successors.
+// Edges whose old endpoint has no successor are dropped along with the old block.
+func relinkRelationshipsTx(ctx context.Context, tx *sql.Tx, oldIDs []uuid.UUID, successors map[uuid.UUID]uuid.UUID) error {
+	if len(oldIDs) == 0 || len(successors) == 0 {
+		return nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT from_block_id, to_block_id, relationship_type, confidence, created_at
+		FROM block_relationships
+		WHERE from_block_id = ANY($1::uuid[]) OR to_block_id = ANY($1::uuid[])
+	`, pq.Array(uuidStrings(oldIDs)))
+	if err != nil {
+		return fmt.Errorf("failed to query relationships: %w", err)
+	}
+
+	var edges []types.Relationship
+	for rows.Next() {
+		var rel types.Relationship
+		if err := rows.Scan(&rel.FromBlockID, &rel.ToBlockID, &rel.RelationshipType, &rel.Confidence, &rel.CreatedAt); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan relationship: %w", err)
+		}
+		edges = append(edges, rel)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read relationships: %w", err)
+	}
+
+	isOld := make(map[uuid.UUID]bool, len(oldIDs))
+	for _, id := range oldIDs {
+		isOld[id] = true
+	}
+
+	remap := func(id uuid.UUID) (uuid.UUID, bool) {
+		if !isOld[id] {
+			return id, true
+		}
+		newID, ok := successors[id]
+		return newID, ok
+	}
+
+	for _, rel := range edges {
+		from, okFrom := remap(rel.FromBlockID)
+		to, okTo := remap(rel.ToBlockID)
+		if !okFrom || !okTo || from == to {
+			continue
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO block_relationships (from_block_id, to_block_id, relationship_type, confidence, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (from_block_id, to_block_id, relationship_type) DO NOTHING
+		`, from, to, rel.RelationshipType, rel.Confidence, rel.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to relink relationship: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // Helper types for import operations
 
 type Organization struct {
@@ -1022,6 +1474,7 @@ type ImportHistoryRecord struct {
 	ID                   uuid.UUID
 	SourceFile           string
 	FileHash             string
+	FileSize             int64 // Bytes covered by this import (0 for imports before sizes were tracked)
 	ImportedAt           string
 	UpdatedAt            string
 	BlockCount           int
@@ -1032,12 +1485,35 @@ type ImportHistoryRecord struct {
 	OrganizationID       *uuid.UUID
 }
 
+// ReplaceResult summarizes a ReplaceSourceBlocks call
+type ReplaceResult struct {
+	Blocks   []*types.Block // Newly inserted blocks
+	Replaced int            // Stale blocks removed
+}
+
 type BlockSourceRecord struct {
+	BlockID       uuid.UUID
+	SourceFile    string
+	SourceHash    string
+	SourceType    string
+	Topic         string
+	ExchangeCount int
+}
+
+// BlockFilesQuery selects the blocks QueryBlockFiles returns
+type BlockFilesQuery struct {
+	SourceType     string     // e.g. "git-history"
+	MetadataKey    string     // Metadata key listing file paths, e.g. "files"
+	OrganizationID *uuid.UUID // Only blocks of this organization (nil: blocks without one)
+	SourceFiles    []string   // Only blocks from these source files (empty: any)
+	Mentioning     []string   // Only blocks whose source file lies below, or whose files name, one of these paths (empty: any)
+}
+
+// BlockFilesRecord is a block with the file paths listed in its metadata
+type BlockFilesRecord struct {
 	BlockID    uuid.UUID
 	SourceFile string
-	SourceHash string
-	SourceType string
-	Topic      string
+	Files      []string
 }
 
 // Helper function to extract project name from directory path
@@ -1052,6 +1528,15 @@ func extractProjectName(path string) string {
 	return path
 }
 
+// uuidStrings converts UUIDs to strings for pq.Array parameters
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = id.String()
+	}
+	return out
+}
+
 // Helper function to convert float64 slice to float32 for pgvector
 func toFloat32(f64 []float64) []float32 {
 	f32 := make([]float32, len(f64))
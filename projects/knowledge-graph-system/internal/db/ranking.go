@@ -0,0 +1,151 @@
+package db
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+)
+
+// searchSignals lists the signals in a fixed order, so ties rank the same way
+// on every search
+var searchSignals = []string{signalBlockVector, signalTopicKeyword, signalExchangeVector, signalExchangeKeyword}
+
+// keywordSignals are the signals scored by ts_rank, which has no fixed upper
+// bound (similarities are already 0-1)
+var keywordSignals = map[string]bool{signalTopicKeyword: true, signalExchangeKeyword: true}
+
+// signalWeights maps each signal to its weight, every signal weighing 1 when no
+// weight is set
+func signalWeights(w types.SignalWeights) map[string]float64 {
+	if w.IsZero() {
+		w = types.SignalWeights{BlockSimilarity: 1, TopicRank: 1, ExchangeSimilarity: 1, ExchangeRank: 1}
+	}
+	return map[string]float64{
+		signalBlockVector:     w.BlockSimilarity,
+		signalTopicKeyword:    w.TopicRank,
+		signalExchangeVector:  w.ExchangeSimilarity,
+		signalExchangeKeyword: w.ExchangeRank,
+	}
+}
+
+// rankBlocks sets the relevance of each block as configured by opts.Ranking,
+// drops blocks below opts.MinRelevance and returns the opts.Limit most
+// relevant, best first
+func rankBlocks(blocks []*blockHits, opts types.SearchOptions, now time.Time) []*blockHits {
+	ranking := opts.Ranking
+	weights := signalWeights(ranking.Weights)
+
+	switch ranking.Strategy {
+	case types.RankingLinear:
+		fuseLinear(blocks, weights)
+	default:
+		k := ranking.RRFK
+		if k == 0 {
+			k = types.DefaultRRFK
+		}
+		fuseRRF(blocks, weights, k)
+	}
+
+	ranked := blocks[:0]
+	for _, h := range blocks {
+		if ranking.RecencyHalfLifeDays > 0 && !h.createdAt.IsZero() {
+			if age := now.Sub(h.createdAt).Hours() / 24; age > 0 {
+				h.relevance *= math.Pow(0.5, age/ranking.RecencyHalfLifeDays)
+			}
+		}
+		if boost, ok := ranking.ProjectBoosts[h.projectID]; ok {
+			h.relevance *= boost
+		}
+
+		if h.relevance >= opts.MinRelevance {
+			ranked = append(ranked, h)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].relevance > ranked[j].relevance
+	})
+	if opts.Limit > 0 && len(ranked) > opts.Limit {
+		ranked = ranked[:opts.Limit]
+	}
+	return ranked
+}
+
+// fuseRRF scores blocks by reciprocal rank fusion: each signal adds
+// weight / (k + rank) for the blocks it matched, rank 1 being its best. The sum
+// is divided by its maximum, reached by a block every signal ranks first.
+func fuseRRF(blocks []*blockHits, weights map[string]float64, k float64) {
+	var total float64
+	for _, signal := range searchSignals {
+		total += weights[signal] / (k + 1)
+	}
+
+	for _, h := range blocks {
+		h.relevance = 0
+	}
+	for _, signal := range searchSignals {
+		if weights[signal] == 0 {
+			continue
+		}
+
+		var matched []*blockHits
+		for _, h := range blocks {
+			if _, ok := h.best[signal]; ok {
+				matched = append(matched, h)
+			}
+		}
+		sort.SliceStable(matched, func(i, j int) bool {
+			return matched[i].best[signal] > matched[j].best[signal]
+		})
+
+		for rank, h := range matched {
+			h.relevance += weights[signal] / (k + float64(rank+1))
+		}
+	}
+
+	if total > 0 {
+		for _, h := range blocks {
+			h.relevance /= total
+		}
+	}
+}
+
+// fuseLinear scores blocks by the weighted mean of their signal scores.
+// Similarities are used as they are (clamped to 0-1); keyword ranks are divided
+// by the best rank among the candidates.
+func fuseLinear(blocks []*blockHits, weights map[string]float64) {
+	best := make(map[string]float64)
+	for _, h := range blocks {
+		for signal, score := range h.best {
+			best[signal] = math.Max(best[signal], score)
+		}
+	}
+
+	var total float64
+	for _, signal := range searchSignals {
+		total += weights[signal]
+	}
+
+	for _, h := range blocks {
+		h.relevance = 0
+		for _, signal := range searchSignals {
+			score, ok := h.best[signal]
+			if !ok {
+				continue
+			}
+			if keywordSignals[signal] {
+				if best[signal] > 0 {
+					score /= best[signal]
+				}
+			} else {
+				score = math.Min(math.Max(score, 0), 1)
+			}
+			h.relevance += weights[signal] * score
+		}
+		if total > 0 {
+			h.relevance /= total
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rankingFixture returns three blocks: a strong semantic match, a keyword-only
+// match and a weak match on both
+func rankingFixture(now time.Time) (semantic, keyword, weak *blockHits) {
+	semantic = &blockHits{blockID: uuid.New(), createdAt: now, best: map[string]float64{
+		signalBlockVector: 0.90, signalExchangeVector: 0.85,
+	}}
+	keyword = &blockHits{blockID: uuid.New(), createdAt: now, best: map[string]float64{
+		signalTopicKeyword: 0.60, signalExchangeKeyword: 0.40,
+	}}
+	weak = &blockHits{blockID: uuid.New(), createdAt: now, best: map[string]float64{
+		signalBlockVector: 0.30, signalTopicKeyword: 0.06,
+	}}
+	return semantic, keyword, weak
+}
+
+func TestRankBlocks_RRF(t *testing.T) {
+	now := time.Now()
+	semantic, keyword, weak := rankingFixture(now)
+
+	ranked := rankBlocks([]*blockHits{weak, keyword, semantic}, types.SearchOptions{Limit: 10}, now)
+	require.Len(t, ranked, 3, "keyword-only matches are kept")
+
+	// Each signal ranked first in two lists; the weak block is second in two
+	assert.InDelta(t, 0.5, semantic.relevance, 1e-9)
+	assert.InDelta(t, 0.5, keyword.relevance, 1e-9)
+	assert.InDelta(t, 0.5*61/62, weak.relevance, 1e-9)
+	assert.Equal(t, weak, ranked[2])
+
+	// A block ranked first by every signal scores 1
+	all := &blockHits{best: map[string]float64{
+		signalBlockVector: 1, signalTopicKeyword: 1, signalExchangeVector: 1, signalExchangeKeyword: 1,
+	}}
+	rankBlocks([]*blockHits{all}, types.SearchOptions{}, now)
+	assert.InDelta(t, 1.0, all.relevance, 1e-9)
+
+	// Weights shift the fusion toward keyword signals
+	opts := types.SearchOptions{Ranking: types.RankingOptions{Weights: types.SignalWeights{TopicRank: 1, ExchangeRank: 1}}}
+	ranked = rankBlocks([]*blockHits{weak, keyword, semantic}, opts, now)
+	require.Len(t, ranked, 3)
+	assert.Equal(t, keyword, ranked[0])
+	assert.Equal(t, 0.0, semantic.relevance, "a zero weight ignores the signal")
+}
+
+func TestRankBlocks_Linear(t *testing.T) {
+	now := time.Now()
+	semantic, keyword, weak := rankingFixture(now)
+
+	opts := types.SearchOptions{Limit: 10, Ranking: types.RankingOptions{Strategy: types.RankingLinear}}
+	ranked := rankBlocks([]*blockHits{weak, keyword, semantic}, opts, now)
+	require.Len(t, ranked, 3)
+
+	assert.InDelta(t, (0.90+0.85)/4, semantic.relevance, 1e-9)
+	assert.InDelta(t, (1.0+1.0)/4, keyword.relevance, 1e-9, "keyword ranks are relative to the best rank")
+	assert.InDelta(t, (0.30+0.10)/4, weak.relevance, 1e-9)
+	assert.Equal(t, []*blockHits{keyword, semantic, weak}, ranked)
+}
+
+func TestRankBlocks_DecayBoostAndThreshold(t *testing.T) {
+	now := time.Now()
+	semantic, keyword, weak := rankingFixture(now)
+	semantic.createdAt = now.Add(-30 * 24 * time.Hour)
+	weak.projectID = uuid.New()
+
+	opts := types.SearchOptions{
+		Limit:        10,
+		MinRelevance: 0.3,
+		Ranking: types.RankingOptions{
+			RecencyHalfLifeDays: 30,
+			ProjectBoosts:       map[uuid.UUID]float64{weak.projectID: 2},
+		},
+	}
+	ranked := rankBlocks([]*blockHits{weak, keyword, semantic}, opts, now)
+
+	assert.InDelta(t, 0.25, semantic.relevance, 1e-9, "halved after one half-life")
+	assert.InDelta(t, 61.0/62, weak.relevance, 1e-9, "boosted")
+	assert.Equal(t, []*blockHits{weak, keyword}, ranked, "results below the minimum relevance are dropped")
+
+	opts.MinRelevance = 0
+	opts.Limit = 1
+	assert.Len(t, rankBlocks([]*blockHits{weak, keyword, semantic}, opts, now), 1)
+}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/core"
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+)
+
+// validateRelationship checks a relationship's type, ends and confidence,
+// defaulting a zero confidence to 1
+func validateRelationship(rel *types.Relationship) error {
+	if !types.ValidRelationshipType(rel.RelationshipType) {
+		return fmt.Errorf("%w: unknown type %q (want one of %v)", core.ErrInvalidRelationship, rel.RelationshipType, types.RelationshipTypes)
+	}
+	if rel.FromBlockID == rel.ToBlockID {
+		return fmt.Errorf("%w: a block cannot be related to itself", core.ErrInvalidRelationship)
+	}
+	if rel.Confidence == 0 {
+		rel.Confidence = 1
+	}
+	if rel.Confidence < 0 || rel.Confidence > 1 {
+		return fmt.Errorf("%w: confidence must be between 0 and 1", core.ErrInvalidRelationship)
+	}
+	return nil
+}
+
+// AddRelationship links two blocks the caller may see with a typed edge,
+// updating the confidence of an existing edge
+func (p *PostgresDB) AddRelationship(ctx context.Context, rel types.Relationship, caller types.Caller) error {
+	if err := validateRelationship(&rel); err != nil {
+		return err
+	}
+	if err := p.requireVisible(ctx, caller, rel.FromBlockID, rel.ToBlockID); err != nil {
+		return err
+	}
+	return p.SaveRelationships(ctx, []types.Relationship{rel})
+}
+
+// RemoveRelationship deletes a typed edge between two blocks the caller may see
+func (p *PostgresDB) RemoveRelationship(ctx context.Context, fromID, toID uuid.UUID, relationshipType string, caller types.Caller) error {
+	if err := p.requireVisible(ctx, caller, fromID, toID); err != nil {
+		return err
+	}
+
+	result, err := p.db.ExecContext(ctx, `
+		DELETE FROM block_relationships
+		WHERE from_block_id = $1 AND to_block_id = $2 AND relationship_type = $3
+	`, fromID, toID, relationshipType)
+	if err != nil {
+		return fmt.Errorf("failed to remove relationship: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: %s -%s-> %s", core.ErrRelationshipNotFound, fromID, relationshipType, toID)
+	}
+	return nil
+}
+
+// requireVisible returns ErrBlockNotFound unless the caller may see every block
+func (p *PostgresDB) requireVisible(ctx context.Context, caller types.Caller, ids ...uuid.UUID) error {
+	blocks, err := p.getBlocks(ctx, ids, caller)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, ok := blocks[id]; !ok {
+			return fmt.Errorf("%w: %s", core.ErrBlockNotFound, id)
+		}
+	}
+	return nil
+}
+
+// getEdges returns the relationships to and from a block whose other end the
+// caller may see, most confident first
+func (p *PostgresDB) getEdges(ctx context.Context, blockID uuid.UUID, caller types.Caller) ([]types.Edge, error) {
+	visible, visibleArgs := visibilityFilter(2, caller)
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT r.from_block_id, r.to_block_id, r.relationship_type, r.confidence, r.created_at,
+		       `+blockColumns+`
+		FROM block_relationships r
+		JOIN blocks b ON b.id = CASE WHEN r.from_block_id = $1 THEN r.to_block_id ELSE r.from_block_id END
+		WHERE (r.from_block_id = $1 OR r.to_block_id = $1)
+		  AND `+visible+`
+		ORDER BY r.confidence DESC, r.created_at DESC
+	`, append([]interface{}{blockID}, visibleArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query relationships: %w", err)
+	}
+	defer rows.Close()
+
+	var edges []types.Edge
+	for rows.Next() {
+		var edge types.Edge
+		rel := &edge.Relationship
+		block, err := scanBlock(prefixScanner{rows, []interface{}{
+			&rel.FromBlockID, &rel.ToBlockID, &rel.RelationshipType, &rel.Confidence, &rel.CreatedAt,
+		}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan relationship: %w", err)
+		}
+		if !caller.CanView(block) {
+			continue
+		}
+
+		edge.Direction = types.DirectionOutgoing
+		if rel.ToBlockID == blockID {
+			edge.Direction = types.DirectionIncoming
+		}
+		edge.Block = block
+		edges = append(edges, edge)
+	}
+
+	return edges, rows.Err()
+}
+
+// prefixScanner scans leading columns into prefix before handing the rest of
+// the row to the caller's destinations
+type prefixScanner struct {
+	row    interface{ Scan(...interface{}) error }
+	prefix []interface{}
+}
+
+func (s prefixScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(append([]interface{}(nil), s.prefix...), dest...)...)
+}
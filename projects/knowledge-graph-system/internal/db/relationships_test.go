@@ -0,0 +1,44 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/core"
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRelationship(t *testing.T) {
+	from, to := uuid.New(), uuid.New()
+
+	rel := types.Relationship{FromBlockID: from, ToBlockID: to, RelationshipType: types.RelationshipSupersedes}
+	require.NoError(t, validateRelationship(&rel))
+	assert.Equal(t, 1.0, rel.Confidence, "confidence defaults to 1")
+
+	for _, bad := range []types.Relationship{
+		{FromBlockID: from, ToBlockID: to, RelationshipType: "blocks"},
+		{FromBlockID: from, ToBlockID: from, RelationshipType: types.RelationshipRelatedTo},
+		{FromBlockID: from, ToBlockID: to, RelationshipType: types.RelationshipImplements, Confidence: 1.5},
+	} {
+		assert.ErrorIs(t, validateRelationship(&bad), core.ErrInvalidRelationship, "%+v", bad)
+	}
+}
+
+// rowFunc is a row whose Scan records its destinations
+type rowFunc func(dest ...interface{}) error
+
+func (f rowFunc) Scan(dest ...interface{}) error { return f(dest...) }
+
+func TestPrefixScanner(t *testing.T) {
+	var got []interface{}
+	row := rowFunc(func(dest ...interface{}) error {
+		got = dest
+		return nil
+	})
+
+	var a, b, c int
+	require.NoError(t, prefixScanner{row, []interface{}{&a}}.Scan(&b, &c))
+	assert.Equal(t, []interface{}{&a, &b, &c}, got)
+}
@@ -0,0 +1,266 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pgvector/pgvector-go"
+)
+
+// Search signals, as named by the candidate query
+const (
+	signalBlockVector     = "block_vector"     // Query vs block embedding
+	signalTopicKeyword    = "topic_keyword"    // Full-text match on the topic
+	signalExchangeVector  = "exchange_vector"  // Query vs exchange embedding
+	signalExchangeKeyword = "exchange_keyword" // Full-text match on an exchange's question and answer
+)
+
+// candidatesPerResult is how many candidates each signal contributes per
+// requested result. Exchange hits are aggregated to their block, so several
+// candidates can collapse into one result.
+const candidatesPerResult = 3
+
+// headlineOptions configures ts_headline for exchange snippets
+const headlineOptions = `StartSel=**, StopSel=**, MinWords=10, MaxWords=30, MaxFragments=2, FragmentDelimiter=" ... "`
+
+// searchHit is one candidate from one signal. Exchange signals carry the
+// exchange that matched.
+type searchHit struct {
+	signal     string
+	blockID    uuid.UUID
+	exchangeID *uuid.UUID
+	score      float64
+	projectID  uuid.UUID
+	createdAt  time.Time
+}
+
+// blockHits is a block's best score per signal and the exchanges that matched
+type blockHits struct {
+	blockID   uuid.UUID
+	projectID uuid.UUID
+	createdAt time.Time
+	best      map[string]float64 // Only signals that matched the block
+	matches   []types.ExchangeMatch
+	relevance float64 // Set by rankBlocks
+}
+
+// scores returns the block's best score per signal
+func (h *blockHits) scores() types.SearchScores {
+	return types.SearchScores{
+		BlockSimilarity:    h.best[signalBlockVector],
+		TopicRank:          h.best[signalTopicKeyword],
+		ExchangeSimilarity: h.best[signalExchangeVector],
+		ExchangeRank:       h.best[signalExchangeKeyword],
+	}
+}
+
+// match returns the block's match for an exchange, adding it if needed
+func (h *blockHits) match(exchangeID uuid.UUID) *types.ExchangeMatch {
+	for i := range h.matches {
+		if h.matches[i].ExchangeID == exchangeID {
+			return &h.matches[i]
+		}
+	}
+	h.matches = append(h.matches, types.ExchangeMatch{ExchangeID: exchangeID})
+	return &h.matches[len(h.matches)-1]
+}
+
+// aggregateHits groups hits by block, in the order blocks were first hit, each
+// with its matched exchanges best first
+func aggregateHits(hits []searchHit) []*blockHits {
+	byBlock := make(map[uuid.UUID]*blockHits)
+	var blocks []*blockHits
+
+	for _, hit := range hits {
+		h := byBlock[hit.blockID]
+		if h == nil {
+			h = &blockHits{
+				blockID:   hit.blockID,
+				projectID: hit.projectID,
+				createdAt: hit.createdAt,
+				best:      make(map[string]float64),
+			}
+			byBlock[hit.blockID] = h
+			blocks = append(blocks, h)
+		}
+
+		if best, ok := h.best[hit.signal]; !ok || hit.score > best {
+			h.best[hit.signal] = hit.score
+		}
+
+		if hit.exchangeID == nil {
+			continue
+		}
+		m := h.match(*hit.exchangeID)
+		switch hit.signal {
+		case signalExchangeVector:
+			m.Similarity = math.Max(m.Similarity, hit.score)
+		case signalExchangeKeyword:
+			m.Rank = math.Max(m.Rank, hit.score)
+		}
+	}
+
+	for _, h := range blocks {
+		sort.SliceStable(h.matches, func(i, j int) bool {
+			return h.matches[i].Similarity+h.matches[i].Rank > h.matches[j].Similarity+h.matches[j].Rank
+		})
+	}
+	return blocks
+}
+
+// searchCandidates runs every search signal over the blocks the caller may see
+// and returns their hits. Each signal contributes at most limit hits.
+func (p *PostgresDB) searchCandidates(ctx context.Context, query string, embedding []float64, opts types.SearchOptions, limit int) ([]searchHit, error) {
+	visible, visibleArgs := visibilityFilter(6, opts.Caller)
+	scope := `($2::uuid IS NULL OR b.project_id = $2)
+			  AND b.completed_at IS NOT NULL
+			  AND (cardinality($5::text[]) = 0 OR EXISTS (
+				SELECT 1 FROM block_tags bt
+				JOIN tags t ON t.id = bt.tag_id
+				WHERE bt.block_id = b.id AND t.name = ANY($5::text[])
+			  ))
+			  AND ` + visible
+
+	querySQL := `
+		WITH block_vector AS (
+			SELECT b.id AS block_id, NULL::uuid AS exchange_id, 1 - (b.embedding <=> $1) AS score,
+			       b.project_id, b.created_at
+			FROM blocks b
+			WHERE ` + scope + `
+			ORDER BY b.embedding <=> $1
+			LIMIT $3
+		),
+		topic_keyword AS (
+			SELECT b.id AS block_id, NULL::uuid AS exchange_id,
+			       ts_rank(to_tsvector('english', b.topic), plainto_tsquery('english', $4)) AS score,
+			       b.project_id, b.created_at
+			FROM blocks b
+			WHERE to_tsvector('english', b.topic) @@ plainto_tsquery('english', $4)
+			  AND ` + scope + `
+			ORDER BY score DESC
+			LIMIT $3
+		),
+		exchange_vector AS (
+			SELECT e.block_id, e.id AS exchange_id, 1 - (e.embedding <=> $1) AS score,
+			       b.project_id, b.created_at
+			FROM exchanges e
+			JOIN blocks b ON b.id = e.block_id
+			WHERE e.embedding IS NOT NULL
+			  AND ` + scope + `
+			ORDER BY e.embedding <=> $1
+			LIMIT $3
+		),
+		exchange_keyword AS (
+			SELECT e.block_id, e.id AS exchange_id,
+			       ts_rank(to_tsvector('english', e.question || ' ' || e.answer), plainto_tsquery('english', $4)) AS score,
+			       b.project_id, b.created_at
+			FROM exchanges e
+			JOIN blocks b ON b.id = e.block_id
+			WHERE to_tsvector('english', e.question || ' ' || e.answer) @@ plainto_tsquery('english', $4)
+			  AND ` + scope + `
+			ORDER BY score DESC
+			LIMIT $3
+		)
+		SELECT '` + signalBlockVector + `', block_id, exchange_id, score, project_id, created_at FROM block_vector
+		UNION ALL
+		SELECT '` + signalTopicKeyword + `', block_id, exchange_id, score, project_id, created_at FROM topic_keyword
+		UNION ALL
+		SELECT '` + signalExchangeVector + `', block_id, exchange_id, score, project_id, created_at FROM exchange_vector
+		UNION ALL
+		SELECT '` + signalExchangeKeyword + `', block_id, exchange_id, score, project_id, created_at FROM exchange_keyword
+	`
+
+	args := append([]interface{}{
+		pgvector.NewVector(toFloat32(embedding)),
+		opts.ProjectID,
+		limit,
+		query,
+		pq.Array(opts.Tags),
+	}, visibleArgs...)
+
+	rows, err := p.db.QueryContext(ctx, querySQL, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []searchHit
+	for rows.Next() {
+		var hit searchHit
+		if err := rows.Scan(&hit.signal, &hit.blockID, &hit.exchangeID, &hit.score, &hit.projectID, &hit.createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan search hit: %w", err)
+		}
+		hits = append(hits, hit)
+	}
+
+	return hits, rows.Err()
+}
+
+// getBlocks loads the blocks with the given IDs that the caller may see
+func (p *PostgresDB) getBlocks(ctx context.Context, ids []uuid.UUID, caller types.Caller) (map[uuid.UUID]*types.Block, error) {
+	blocks := make(map[uuid.UUID]*types.Block, len(ids))
+	if len(ids) == 0 {
+		return blocks, nil
+	}
+
+	visible, visibleArgs := visibilityFilter(2, caller)
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT `+blockColumns+`
+		FROM blocks b
+		WHERE b.id = ANY($1::uuid[])
+		  AND `+visible, append([]interface{}{pq.Array(uuidStrings(ids))}, visibleArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query blocks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		block, err := scanBlock(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan block: %w", err)
+		}
+		if caller.CanView(block) {
+			blocks[block.ID] = block
+		}
+	}
+
+	return blocks, rows.Err()
+}
+
+// exchangeSnippets returns ts_headline snippets of the question and answer of
+// each exchange, with the query terms highlighted
+func (p *PostgresDB) exchangeSnippets(ctx context.Context, ids []uuid.UUID, query string) (map[uuid.UUID][2]string, error) {
+	snippets := make(map[uuid.UUID][2]string, len(ids))
+	if len(ids) == 0 {
+		return snippets, nil
+	}
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT id,
+		       ts_headline('english', question, plainto_tsquery('english', $2), $3),
+		       ts_headline('english', answer, plainto_tsquery('english', $2), $3)
+		FROM exchanges
+		WHERE id = ANY($1::uuid[])
+	`, pq.Array(uuidStrings(ids)), query, headlineOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to highlight exchanges: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var question, answer string
+		if err := rows.Scan(&id, &question, &answer); err != nil {
+			return nil, fmt.Errorf("failed to scan snippet: %w", err)
+		}
+		snippets[id] = [2]string{question, answer}
+	}
+
+	return snippets, rows.Err()
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateHits(t *testing.T) {
+	topicOnly, exchangeOnly, both := uuid.New(), uuid.New(), uuid.New()
+	ex1, ex2, ex3 := uuid.New(), uuid.New(), uuid.New()
+
+	hits := []searchHit{
+		{signal: signalBlockVector, blockID: topicOnly, score: 0.40},
+		{signal: signalTopicKeyword, blockID: topicOnly, score: 0.10},
+		{signal: signalBlockVector, blockID: both, score: 0.50},
+		{signal: signalExchangeVector, blockID: both, exchangeID: &ex1, score: 0.80},
+		{signal: signalExchangeVector, blockID: both, exchangeID: &ex2, score: 0.60},
+		{signal: signalExchangeKeyword, blockID: both, exchangeID: &ex2, score: 0.30},
+		// An exchange can match without its block's own embedding or topic matching
+		{signal: signalExchangeKeyword, blockID: exchangeOnly, exchangeID: &ex3, score: 0.20},
+	}
+
+	blocks := aggregateHits(hits)
+	require.Len(t, blocks, 3)
+
+	assert.Equal(t, topicOnly, blocks[0].blockID)
+	assert.Equal(t, types.SearchScores{BlockSimilarity: 0.40, TopicRank: 0.10}, blocks[0].scores())
+	assert.Empty(t, blocks[0].matches)
+
+	assert.Equal(t, both, blocks[1].blockID)
+	assert.Equal(t, types.SearchScores{BlockSimilarity: 0.50, ExchangeSimilarity: 0.80, ExchangeRank: 0.30}, blocks[1].scores(),
+		"the best exchange score per signal counts for the block")
+	require.Len(t, blocks[1].matches, 2)
+	assert.Equal(t, types.ExchangeMatch{ExchangeID: ex2, Similarity: 0.60, Rank: 0.30}, blocks[1].matches[0], "best match first")
+	assert.Equal(t, ex1, blocks[1].matches[1].ExchangeID)
+
+	assert.Equal(t, exchangeOnly, blocks[2].blockID)
+	assert.Equal(t, []types.ExchangeMatch{{ExchangeID: ex3, Rank: 0.20}}, blocks[2].matches)
+
+	assert.Empty(t, aggregateHits(nil))
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/core"
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/lib/pq"
+)
+
+// SetTagExtractor configures how SaveBlock and ExtractTags tag content
+func (p *PostgresDB) SetTagExtractor(tagger core.TagExtractor) {
+	p.tagger = tagger
+}
+
+// extractTags tags content with the configured extractor (none: no tags)
+func (p *PostgresDB) extractTags(ctx context.Context, content string) ([]types.ExtractedTag, error) {
+	if p.tagger == nil {
+		return nil, nil
+	}
+	return p.tagger.ExtractTags(ctx, content)
+}
+
+// DocumentFrequencies counts the blocks whose topic contains each term (by
+// full-text match, so word forms are folded) and the blocks in total, for
+// weighting keyword tags
+func (p *PostgresDB) DocumentFrequencies(ctx context.Context, terms []string) (map[string]int, int, error) {
+	var documents int
+	if err := p.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM blocks`).Scan(&documents); err != nil {
+		return nil, 0, fmt.Errorf("failed to count blocks: %w", err)
+	}
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT t.term, (
+			SELECT COUNT(*) FROM blocks b
+			WHERE to_tsvector('english', b.topic) @@ plainto_tsquery('english', t.term)
+		)
+		FROM unnest($1::text[]) AS t(term)
+	`, pq.Array(terms))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query term frequencies: %w", err)
+	}
+	defer rows.Close()
+
+	frequencies := make(map[string]int, len(terms))
+	for rows.Next() {
+		var term string
+		var count int
+		if err := rows.Scan(&term, &count); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan term frequency: %w", err)
+		}
+		frequencies[term] = count
+	}
+
+	return frequencies, documents, rows.Err()
+}
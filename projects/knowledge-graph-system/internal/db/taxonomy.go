@@ -0,0 +1,597 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/core"
+	"github.com/TheGenXCoder/knowledge-graph/internal/tagging"
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// defaultTagListLimit caps the tags ListTags returns unless opts.Limit is set
+const defaultTagListLimit = 100
+
+// tagQuerier runs single-row tag queries on the database or inside a transaction
+type tagQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// normalizeTagName canonicalizes a new tag name or alias
+func normalizeTagName(name string) (string, error) {
+	normalized := tagging.NormalizeTag(name, nil)
+	if normalized == "" {
+		return "", unusableTagName(name)
+	}
+	return normalized, nil
+}
+
+// unusableTagName rejects a tag name. It is also returned for names held by
+// tags the caller may not see, so they read like any other unusable name.
+func unusableTagName(name string) error {
+	return fmt.Errorf("%w: %q is not a usable tag name", core.ErrInvalidTag, name)
+}
+
+// tagLookupNames returns every name the requested tags may be stored under:
+// as given (trimmed) and normalized, since older tags were not normalized
+func tagLookupNames(names []string) []string {
+	seen := make(map[string]bool)
+	var lookup []string
+	for _, name := range names {
+		for _, candidate := range []string{strings.TrimSpace(name), tagging.NormalizeTag(name, nil)} {
+			if candidate != "" && !seen[candidate] {
+				seen[candidate] = true
+				lookup = append(lookup, candidate)
+			}
+		}
+	}
+	return lookup
+}
+
+// unionNames merges name lists, sorted and without duplicates
+func unionNames(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, list := range lists {
+		for _, name := range list {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveTag finds a tag by name or alias, preferring a tag with that name
+func resolveTag(ctx context.Context, q tagQuerier, name string) (types.Tag, error) {
+	var tag types.Tag
+	lookup := tagLookupNames([]string{name})
+	if len(lookup) == 0 {
+		return tag, fmt.Errorf("%w: empty tag name", core.ErrInvalidTag)
+	}
+
+	err := q.QueryRowContext(ctx, `
+		SELECT t.id, t.name, t.created_at
+		FROM tags t
+		WHERE t.name = ANY($1::text[])
+		   OR t.id IN (SELECT tag_id FROM tag_aliases WHERE alias = ANY($1::text[]))
+		ORDER BY t.name = ANY($1::text[]) DESC
+		LIMIT 1
+	`, pq.Array(lookup)).Scan(&tag.ID, &tag.Name, &tag.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return tag, fmt.Errorf("%w: %s", core.ErrTagNotFound, name)
+	}
+	if err != nil {
+		return tag, fmt.Errorf("failed to resolve tag %s: %w", name, err)
+	}
+	return tag, nil
+}
+
+// tagAccess reports whether a tag is on a block the caller may see, and whether
+// it is on a block outside the caller's organization
+func tagAccess(ctx context.Context, q tagQuerier, caller types.Caller, tagID uuid.UUID) (visible, foreign bool, err error) {
+	visibleFilter, visibleArgs := visibilityFilter(3, caller)
+	err = q.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM block_tags bt JOIN blocks b ON b.id = bt.block_id
+		               WHERE bt.tag_id = $1 AND `+visibleFilter+`),
+		       EXISTS (SELECT 1 FROM block_tags bt JOIN blocks b ON b.id = bt.block_id
+		               WHERE bt.tag_id = $1 AND b.organization_id IS DISTINCT FROM $2)
+	`, append([]interface{}{tagID, caller.OrganizationID}, visibleArgs...)...).Scan(&visible, &foreign)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to check access to tag: %w", err)
+	}
+	return visible, foreign, nil
+}
+
+// resolveOwnedTag resolves a tag (by name or alias) the caller may change (see
+// checkTagOwned)
+func resolveOwnedTag(ctx context.Context, q tagQuerier, caller types.Caller, name string) (types.Tag, error) {
+	tag, err := resolveTag(ctx, q, name)
+	if err != nil {
+		return tag, err
+	}
+	return tag, checkTagOwned(ctx, q, caller, tag, name)
+}
+
+// checkTagOwned checks the caller may change a tag: it must be on blocks the
+// caller may see and on none outside the caller's organization. A tag the
+// caller may not see is reported as not found (as name), as GetTag does.
+func checkTagOwned(ctx context.Context, q tagQuerier, caller types.Caller, tag types.Tag, name string) error {
+	visible, foreign, err := tagAccess(ctx, q, caller, tag.ID)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return fmt.Errorf("%w: %s", core.ErrTagNotFound, name)
+	}
+	if foreign {
+		return fmt.Errorf("%w: %q is also used outside your organization", core.ErrInvalidTag, tag.Name)
+	}
+	return nil
+}
+
+// resolveVisibleTag resolves a tag (by name or alias) on a block the caller may
+// see, reporting any other tag as not found
+func resolveVisibleTag(ctx context.Context, q tagQuerier, caller types.Caller, name string) (types.Tag, error) {
+	tag, err := resolveTag(ctx, q, name)
+	if err != nil {
+		return tag, err
+	}
+	if visible, _, err := tagAccess(ctx, q, caller, tag.ID); err != nil {
+		return tag, err
+	} else if !visible {
+		return tag, fmt.Errorf("%w: %s", core.ErrTagNotFound, name)
+	}
+	return tag, nil
+}
+
+// nameHolder returns the tag using name as its name (isName) or as an alias,
+// or uuid.Nil when no tag does
+func nameHolder(ctx context.Context, q tagQuerier, name string) (holder uuid.UUID, isName bool, err error) {
+	err = q.QueryRowContext(ctx, `
+		SELECT id, true FROM tags WHERE name = $1
+		UNION ALL
+		SELECT tag_id, false FROM tag_aliases WHERE alias = $1
+		LIMIT 1
+	`, name).Scan(&holder, &isName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, false, nil
+	}
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("failed to check tag name %s: %w", name, err)
+	}
+	return holder, isName, nil
+}
+
+// getOrCreateTag returns the tag a block tagged name is linked to: the tag
+// name is an alias of, or else the tag with that name, created if missing
+func getOrCreateTag(ctx context.Context, q tagQuerier, name string) (types.Tag, error) {
+	var tag types.Tag
+	err := q.QueryRowContext(ctx, `
+		SELECT t.id, t.name, t.created_at
+		FROM tag_aliases a
+		JOIN tags t ON t.id = a.tag_id
+		WHERE a.alias = $1
+	`, name).Scan(&tag.ID, &tag.Name, &tag.CreatedAt)
+	if err == nil {
+		return tag, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return tag, fmt.Errorf("failed to resolve tag %s: %w", name, err)
+	}
+
+	err = q.QueryRowContext(ctx, `
+		INSERT INTO tags (id, name, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id, name, created_at
+	`, uuid.New(), name, time.Now()).Scan(&tag.ID, &tag.Name, &tag.CreatedAt)
+	if err != nil {
+		return tag, fmt.Errorf("failed to get/create tag %s: %w", name, err)
+	}
+	return tag, nil
+}
+
+// ListTags lists the tags of blocks the caller may see with their parent,
+// children, aliases and usage counts, most used first
+func (p *PostgresDB) ListTags(ctx context.Context, opts types.TagListOptions) ([]types.TagInfo, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = defaultTagListLimit
+	}
+	return p.queryTagInfos(ctx, opts.Caller, `left(t.name, length($1)) = $1`, opts.Limit, strings.TrimSpace(opts.Prefix))
+}
+
+// GetTag retrieves a tag by name or alias, with its usage among the blocks the
+// caller may see. A tag only on blocks the caller may not see is reported as not
+// found, so tag names do not leak across organizations.
+func (p *PostgresDB) GetTag(ctx context.Context, name string, caller types.Caller) (*types.TagInfo, error) {
+	tag, err := resolveTag(ctx, p.db, name)
+	if err != nil {
+		return nil, err
+	}
+
+	infos, err := p.queryTagInfos(ctx, caller, `t.id = $1`, 1, tag.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(infos) == 0 {
+		return nil, fmt.Errorf("%w: %s", core.ErrTagNotFound, name)
+	}
+	return &infos[0], nil
+}
+
+// queryTagInfos loads the tags matching where (whose placeholders are args),
+// most used first. Only tags on blocks the caller may see are loaded, usage only
+// counts those blocks, and parents and children without such blocks are left out.
+func (p *PostgresDB) queryTagInfos(ctx context.Context, caller types.Caller, where string, limit int, args ...interface{}) ([]types.TagInfo, error) {
+	limitArg := fmt.Sprintf("$%d", len(args)+1)
+	visible, visibleArgs := visibilityFilter(len(args)+2, caller)
+	rows, err := p.db.QueryContext(ctx, `
+		WITH usage AS (
+			SELECT bt.tag_id, COUNT(*) AS usage_count
+			FROM block_tags bt
+			JOIN blocks b ON b.id = bt.block_id
+			WHERE `+visible+`
+			GROUP BY bt.tag_id
+		)
+		SELECT t.id, t.name, t.created_at,
+		       COALESCE((SELECT parent.name FROM tags parent
+		                 JOIN usage pu ON pu.tag_id = parent.id
+		                 WHERE parent.id = t.parent_id), ''),
+		       ARRAY(SELECT c.name FROM tags c
+		             JOIN usage cu ON cu.tag_id = c.id
+		             WHERE c.parent_id = t.id ORDER BY c.name),
+		       ARRAY(SELECT a.alias FROM tag_aliases a WHERE a.tag_id = t.id ORDER BY a.alias),
+		       u.usage_count
+		FROM tags t
+		JOIN usage u ON u.tag_id = t.id
+		WHERE `+where+`
+		ORDER BY u.usage_count DESC, t.name
+		LIMIT `+limitArg+`
+	`, append(append(args, limit), visibleArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	var infos []types.TagInfo
+	for rows.Next() {
+		var info types.TagInfo
+		if err := rows.Scan(&info.ID, &info.Name, &info.CreatedAt, &info.Parent,
+			pq.Array(&info.Children), pq.Array(&info.Aliases), &info.UsageCount); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, rows.Err()
+}
+
+// RenameTag renames a tag. The old name becomes an alias, so blocks tagged
+// with it later still get the renamed tag. Only tags the caller may change
+// (see checkTagOwned) can be renamed.
+func (p *PostgresDB) RenameTag(ctx context.Context, name, newName string, caller types.Caller) error {
+	newName, err := normalizeTagName(newName)
+	if err != nil {
+		return err
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	tag, err := resolveOwnedTag(ctx, tx, caller, name)
+	if err != nil {
+		return err
+	}
+	if tag.Name == newName {
+		return nil
+	}
+
+	// The new name must not belong to another tag, as its name or an alias
+	owner, _, err := nameHolder(ctx, tx, newName)
+	if err != nil {
+		return err
+	}
+	if owner != uuid.Nil && owner != tag.ID {
+		visible, _, err := tagAccess(ctx, tx, caller, owner)
+		if err != nil {
+			return err
+		}
+		if !visible {
+			return unusableTagName(newName)
+		}
+		return fmt.Errorf("%w: %q is already in use; merge %q into it instead", core.ErrInvalidTag, newName, tag.Name)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tag_aliases WHERE alias = $1`, newName); err != nil {
+		return fmt.Errorf("failed to remove alias %s: %w", newName, err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE tags SET name = $2 WHERE id = $1`, tag.ID, newName); err != nil {
+		return fmt.Errorf("failed to rename tag %s: %w", tag.Name, err)
+	}
+	if err := saveTagAliasTx(ctx, tx, tag.Name, tag.ID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// MergeTags folds sources into target (created if missing): their blocks,
+// aliases and children move to target, and their names become its aliases.
+// Sources already resolving to target are skipped. Sources and an existing
+// target must be tags the caller may change (see checkTagOwned).
+func (p *PostgresDB) MergeTags(ctx context.Context, sources []string, target string, caller types.Caller) (*types.TagMergeResult, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("%w: no tags to merge", core.ErrInvalidTag)
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	targetTag, err := resolveTag(ctx, tx, target)
+	if errors.Is(err, core.ErrTagNotFound) {
+		var name string
+		if name, err = normalizeTagName(target); err != nil {
+			return nil, err
+		}
+		targetTag, err = getOrCreateTag(ctx, tx, name)
+	} else if err == nil {
+		err = checkTagOwned(ctx, tx, caller, targetTag, target)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.TagMergeResult{Target: targetTag}
+	for _, source := range sources {
+		tag, err := resolveOwnedTag(ctx, tx, caller, source)
+		if err != nil {
+			return nil, err
+		}
+		if tag.ID == targetTag.ID {
+			continue
+		}
+
+		relinked, err := mergeTagTx(ctx, tx, tag, targetTag)
+		if err != nil {
+			return nil, err
+		}
+		result.Merged = append(result.Merged, tag.Name)
+		result.BlocksRelinked += relinked
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return result, nil
+}
+
+// mergeTagTx folds source into target and returns the block tags moved
+func mergeTagTx(ctx context.Context, tx *sql.Tx, source, target types.Tag) (int, error) {
+	moved, err := tx.ExecContext(ctx, `
+		INSERT INTO block_tags (block_id, tag_id, confidence, created_at)
+		SELECT block_id, $2, confidence, created_at
+		FROM block_tags
+		WHERE tag_id = $1
+		ON CONFLICT (block_id, tag_id) DO UPDATE SET confidence = GREATEST(block_tags.confidence, EXCLUDED.confidence)
+	`, source.ID, target.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move blocks from tag %s: %w", source.Name, err)
+	}
+	relinked, _ := moved.RowsAffected()
+
+	// A target narrower than the source moves up to the source's parent first,
+	// so taking over the source's children cannot make a cycle
+	if _, err := tx.ExecContext(ctx, `
+		WITH RECURSIVE below(id) AS (
+			SELECT id FROM tags WHERE parent_id = $1
+			UNION
+			SELECT t.id FROM tags t JOIN below ON t.parent_id = below.id
+		)
+		UPDATE tags SET parent_id = (SELECT parent_id FROM tags WHERE id = $1)
+		WHERE id = $2 AND id IN (SELECT id FROM below)
+	`, source.ID, target.ID); err != nil {
+		return 0, fmt.Errorf("failed to reparent tag %s: %w", target.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE tags SET parent_id = $2 WHERE parent_id = $1 AND id <> $2`, source.ID, target.ID); err != nil {
+		return 0, fmt.Errorf("failed to move children of tag %s: %w", source.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE tag_aliases SET tag_id = $2 WHERE tag_id = $1`, source.ID, target.ID); err != nil {
+		return 0, fmt.Errorf("failed to move aliases of tag %s: %w", source.Name, err)
+	}
+
+	// Deleting the source drops its block tags, which now all exist on target
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id = $1`, source.ID); err != nil {
+		return 0, fmt.Errorf("failed to delete tag %s: %w", source.Name, err)
+	}
+	if err := saveTagAliasTx(ctx, tx, source.Name, target.ID); err != nil {
+		return 0, err
+	}
+
+	return int(relinked), nil
+}
+
+// AddTagAlias makes alias resolve to a tag (by name or alias), re-pointing an
+// existing alias. An alias cannot take the name of a tag; merge the tags instead.
+// Both the tag and the tag an existing alias points at must be tags the caller
+// may change (see checkTagOwned).
+func (p *PostgresDB) AddTagAlias(ctx context.Context, alias, tag string, caller types.Caller) error {
+	alias, err := normalizeTagName(alias)
+	if err != nil {
+		return err
+	}
+
+	target, err := resolveOwnedTag(ctx, p.db, caller, tag)
+	if err != nil {
+		return err
+	}
+
+	holder, isTag, err := nameHolder(ctx, p.db, alias)
+	if err != nil {
+		return err
+	}
+	if holder != uuid.Nil && holder != target.ID {
+		visible, foreign, err := tagAccess(ctx, p.db, caller, holder)
+		if err != nil {
+			return err
+		}
+		if !visible {
+			return unusableTagName(alias)
+		}
+		if foreign && !isTag {
+			return fmt.Errorf("%w: %q is an alias of a tag also used outside your organization", core.ErrInvalidTag, alias)
+		}
+	}
+	if isTag {
+		return fmt.Errorf("%w: %q is a tag; merge it into %q instead", core.ErrInvalidTag, alias, target.Name)
+	}
+
+	return saveTagAliasTx(ctx, p.db, alias, target.ID)
+}
+
+// saveTagAliasTx points alias at a tag
+func saveTagAliasTx(ctx context.Context, db interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}, alias string, tagID uuid.UUID) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO tag_aliases (alias, tag_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (alias) DO UPDATE SET tag_id = EXCLUDED.tag_id
+	`, alias, tagID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save tag alias %s: %w", alias, err)
+	}
+	return nil
+}
+
+// RemoveTagAlias deletes an alias of a tag the caller may change (see
+// checkTagOwned)
+func (p *PostgresDB) RemoveTagAlias(ctx context.Context, alias string, caller types.Caller) error {
+	var aliases []string
+	for _, name := range tagLookupNames([]string{alias}) {
+		var holder uuid.UUID
+		err := p.db.QueryRowContext(ctx, `SELECT tag_id FROM tag_aliases WHERE alias = $1`, name).Scan(&holder)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to resolve tag alias %s: %w", name, err)
+		}
+
+		visible, foreign, err := tagAccess(ctx, p.db, caller, holder)
+		if err != nil {
+			return err
+		}
+		if !visible {
+			continue
+		}
+		if foreign {
+			return fmt.Errorf("%w: %q is an alias of a tag also used outside your organization", core.ErrInvalidTag, name)
+		}
+		aliases = append(aliases, name)
+	}
+	if len(aliases) == 0 {
+		return fmt.Errorf("%w: alias %s", core.ErrTagNotFound, alias)
+	}
+
+	if _, err := p.db.ExecContext(ctx, `DELETE FROM tag_aliases WHERE alias = ANY($1::text[])`, pq.Array(aliases)); err != nil {
+		return fmt.Errorf("failed to remove tag alias %s: %w", alias, err)
+	}
+	return nil
+}
+
+// SetTagParent makes parent the broader tag of tag, or tag a root when parent
+// is empty. The parent cannot be the tag itself or one of its narrower tags.
+// The tag must be one the caller may change (see checkTagOwned), and the
+// parent one on a block the caller may see.
+func (p *PostgresDB) SetTagParent(ctx context.Context, tag, parent string, caller types.Caller) error {
+	child, err := resolveOwnedTag(ctx, p.db, caller, tag)
+	if err != nil {
+		return err
+	}
+
+	var parentID *uuid.UUID
+	if strings.TrimSpace(parent) != "" {
+		parentTag, err := resolveVisibleTag(ctx, p.db, caller, parent)
+		if err != nil {
+			return err
+		}
+
+		var cycle bool
+		if err := p.db.QueryRowContext(ctx, `
+			WITH RECURSIVE below(id) AS (
+				SELECT $1::uuid
+				UNION
+				SELECT t.id FROM tags t JOIN below ON t.parent_id = below.id
+			)
+			SELECT EXISTS (SELECT 1 FROM below WHERE id = $2)
+		`, child.ID, parentTag.ID).Scan(&cycle); err != nil {
+			return fmt.Errorf("failed to check tag hierarchy: %w", err)
+		}
+		if cycle {
+			return fmt.Errorf("%w: %q cannot be under %q, which is %q or narrower than it", core.ErrInvalidTag, child.Name, parentTag.Name, child.Name)
+		}
+		parentID = &parentTag.ID
+	}
+
+	if _, err := p.db.ExecContext(ctx, `UPDATE tags SET parent_id = $2 WHERE id = $1`, child.ID, parentID); err != nil {
+		return fmt.Errorf("failed to set parent of tag %s: %w", child.Name, err)
+	}
+	return nil
+}
+
+// ExpandTags resolves tag names through aliases and adds every narrower tag.
+// Unknown names are kept, so filtering by them matches no block.
+func (p *PostgresDB) ExpandTags(ctx context.Context, names []string) ([]string, error) {
+	lookup := tagLookupNames(names)
+	if len(lookup) == 0 {
+		return nil, nil
+	}
+
+	rows, err := p.db.QueryContext(ctx, `
+		WITH RECURSIVE tree(id) AS (
+			SELECT t.id FROM tags t
+			WHERE t.name = ANY($1::text[])
+			   OR t.id IN (SELECT tag_id FROM tag_aliases WHERE alias = ANY($1::text[]))
+			UNION
+			SELECT t.id FROM tags t JOIN tree ON t.parent_id = tree.id
+		)
+		SELECT t.name FROM tags t JOIN tree ON tree.id = t.id
+	`, pq.Array(lookup))
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand tags: %w", err)
+	}
+	defer rows.Close()
+
+	var expanded []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		expanded = append(expanded, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return unionNames(lookup, expanded), nil
+}
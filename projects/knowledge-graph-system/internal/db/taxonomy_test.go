@@ -0,0 +1,35 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTagName(t *testing.T) {
+	name, err := normalizeTagName("  Vector Search ")
+	require.NoError(t, err)
+	assert.Equal(t, "vector-search", name)
+
+	name, err = normalizeTagName("pg")
+	require.NoError(t, err)
+	assert.Equal(t, "pg", name, "no synonyms: aliases are managed in the database")
+
+	_, err = normalizeTagName("!!!")
+	assert.ErrorIs(t, err, core.ErrInvalidTag)
+}
+
+func TestTagLookupNames(t *testing.T) {
+	assert.Equal(t, []string{"Vector Search", "vector-search", "postgres"},
+		tagLookupNames([]string{"Vector Search", " postgres ", "postgres", "  "}),
+		"tags stored before normalization are found under the name given")
+	assert.Empty(t, tagLookupNames(nil))
+}
+
+func TestUnionNames(t *testing.T) {
+	assert.Equal(t, []string{"pg", "pgvector", "postgres"},
+		unionNames([]string{"postgres", "pg"}, []string{"postgres", "pgvector"}))
+	assert.Nil(t, unionNames(nil, nil))
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Traversal limits
+const (
+	defaultTraversalHops  = 2
+	maxTraversalHops      = 5
+	defaultTraversalNodes = 50
+	maxTraversalNodes     = 500
+
+	// traversalPathsPerNode bounds the paths the walk enumerates per node in
+	// the budget, so a dense graph cannot make the recursive query explode.
+	// The walk is breadth-first, so the paths cut are the longest.
+	traversalPathsPerNode = 20
+)
+
+// normalizeTraversal validates opts and applies the traversal defaults and caps
+func normalizeTraversal(opts types.TraversalOptions) (types.TraversalOptions, error) {
+	if err := opts.Validate(); err != nil {
+		return opts, err
+	}
+	if opts.MaxHops == 0 {
+		opts.MaxHops = defaultTraversalHops
+	}
+	if opts.MaxHops > maxTraversalHops {
+		opts.MaxHops = maxTraversalHops
+	}
+	if opts.MaxNodes == 0 {
+		opts.MaxNodes = defaultTraversalNodes
+	}
+	if opts.MaxNodes > maxTraversalNodes {
+		opts.MaxNodes = maxTraversalNodes
+	}
+	if opts.Direction == "" {
+		opts.Direction = types.DirectionBoth
+	}
+	return opts, nil
+}
+
+// GetContextNPlusK walks the relationship graph up to opts.MaxHops from a
+// block, returning every block reached with the shortest path to it. Cycles
+// are not followed, and only blocks the caller may see are visited.
+func (p *PostgresDB) GetContextNPlusK(ctx context.Context, blockID uuid.UUID, opts types.TraversalOptions) (*types.TraversalResult, error) {
+	opts, err := normalizeTraversal(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Tags, err = p.ExpandTags(ctx, opts.Tags); err != nil {
+		return nil, err
+	}
+
+	root, err := p.GetBlock(ctx, blockID, opts.Caller)
+	if err != nil {
+		return nil, err
+	}
+
+	visible, visibleArgs := visibilityFilter(10, opts.Caller)
+	rows, err := p.db.QueryContext(ctx, `
+		WITH RECURSIVE walk(block_id, depth, visited, path, confidence) AS (
+			SELECT $1::uuid, 0, ARRAY[$1::uuid], '[]'::jsonb, 1.0::float8
+			UNION ALL
+			SELECT b.id, w.depth + 1, w.visited || b.id,
+			       w.path || jsonb_build_array(jsonb_build_object(
+			           'from_block_id', r.from_block_id,
+			           'to_block_id', r.to_block_id,
+			           'relationship_type', r.relationship_type,
+			           'confidence', r.confidence)),
+			       w.confidence * COALESCE(r.confidence, 1.0)
+			FROM walk w
+			JOIN block_relationships r
+			  ON ($3::text IN ('both', 'outgoing') AND r.from_block_id = w.block_id)
+			  OR ($3::text IN ('both', 'incoming') AND r.to_block_id = w.block_id)
+			JOIN blocks b ON b.id = CASE WHEN r.from_block_id = w.block_id THEN r.to_block_id ELSE r.from_block_id END
+			WHERE w.depth < $2
+			  AND b.id <> ALL(w.visited)
+			  AND (cardinality($4::text[]) = 0 OR r.relationship_type = ANY($4::text[]))
+			  AND `+visible+`
+		),
+		bounded AS (
+			SELECT * FROM walk WHERE depth > 0 LIMIT $5
+		),
+		reached AS (
+			SELECT DISTINCT ON (block_id) block_id, depth, path, confidence
+			FROM bounded
+			ORDER BY block_id, depth, confidence DESC
+		)
+		SELECT n.depth, n.path, n.confidence,
+		       `+blockColumns+`
+		FROM reached n
+		JOIN blocks b ON b.id = n.block_id
+		WHERE ($6::uuid IS NULL OR b.project_id = $6)
+		  AND (cardinality($7::text[]) = 0 OR COALESCE(b.visibility, 'org-private') = ANY($7::text[]))
+		  AND (cardinality($8::text[]) = 0 OR EXISTS (
+			SELECT 1 FROM block_tags bt
+			JOIN tags t ON t.id = bt.tag_id
+			WHERE bt.block_id = b.id AND t.name = ANY($8::text[])
+		  ))
+		ORDER BY n.depth, n.confidence DESC, b.created_at DESC
+		LIMIT $9
+	`, append([]interface{}{
+		blockID,
+		opts.MaxHops,
+		opts.Direction,
+		pq.Array(opts.RelationshipTypes),
+		opts.MaxNodes * traversalPathsPerNode,
+		opts.ProjectID,
+		pq.Array(opts.Visibility),
+		pq.Array(opts.Tags),
+		opts.MaxNodes + 1,
+	}, visibleArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("traversal query failed: %w", err)
+	}
+	defer rows.Close()
+
+	result := &types.TraversalResult{Root: root}
+	for rows.Next() {
+		var node types.TraversalNode
+		var pathJSON []byte
+		block, err := scanBlock(prefixScanner{rows, []interface{}{&node.Depth, &pathJSON, &node.Confidence}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan traversal node: %w", err)
+		}
+		if !opts.Caller.CanView(block) {
+			continue
+		}
+		if err := json.Unmarshal(pathJSON, &node.Path); err != nil {
+			return nil, fmt.Errorf("failed to parse traversal path: %w", err)
+		}
+		node.Block = block
+
+		if len(result.Nodes) == opts.MaxNodes {
+			result.Truncated = true
+			break
+		}
+		result.Nodes = append(result.Nodes, node)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTraversal(t *testing.T) {
+	opts, err := normalizeTraversal(types.TraversalOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, defaultTraversalHops, opts.MaxHops)
+	assert.Equal(t, defaultTraversalNodes, opts.MaxNodes)
+	assert.Equal(t, types.DirectionBoth, opts.Direction)
+
+	opts, err = normalizeTraversal(types.TraversalOptions{MaxHops: 12, MaxNodes: 10000, Direction: types.DirectionIncoming})
+	require.NoError(t, err)
+	assert.Equal(t, maxTraversalHops, opts.MaxHops)
+	assert.Equal(t, maxTraversalNodes, opts.MaxNodes)
+	assert.Equal(t, types.DirectionIncoming, opts.Direction)
+
+	for _, bad := range []types.TraversalOptions{
+		{MaxHops: -1},
+		{Direction: "sideways"},
+		{RelationshipTypes: []string{types.RelationshipImplements, "blocks"}},
+	} {
+		_, err := normalizeTraversal(bad)
+		assert.Error(t, err, "%+v", bad)
+	}
+}
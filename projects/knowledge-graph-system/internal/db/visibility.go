@@ -0,0 +1,34 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/lib/pq"
+)
+
+// visibilityFilter returns a condition limiting the blocks aliased b to those
+// caller may see, numbering its placeholders from $n. It mirrors
+// types.Caller.CanView, which every query re-checks before returning a block.
+func visibilityFilter(n int, caller types.Caller) (string, []interface{}) {
+	condition := fmt.Sprintf(`(
+			COALESCE(b.visibility, 'org-private') IN ('public', 'anonymized')
+			OR (b.organization_id = $%[1]d AND (
+				(COALESCE(b.visibility, 'org-private') = 'org-private' AND (COALESCE(b.team, '') = '' OR b.team = ANY($%[2]d)))
+				OR (b.visibility = 'individual' AND b.owner_id <> '' AND b.owner_id = $%[3]d)
+			))
+		)`, n, n+1, n+2)
+
+	return condition, []interface{}{caller.OrganizationID, pq.Array(caller.Teams), caller.UserID}
+}
+
+// visibleBlocks drops the blocks caller may not see
+func visibleBlocks(caller types.Caller, blocks []*types.Block) []*types.Block {
+	visible := blocks[:0]
+	for _, block := range blocks {
+		if caller.CanView(block) {
+			visible = append(visible, block)
+		}
+	}
+	return visible
+}
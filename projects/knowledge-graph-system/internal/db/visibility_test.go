@@ -0,0 +1,44 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVisibilityFilter(t *testing.T) {
+	orgID := uuid.New()
+	caller := types.Caller{UserID: "alice", OrganizationID: &orgID, Teams: []string{"platform"}}
+
+	condition, args := visibilityFilter(5, caller)
+
+	for _, placeholder := range []string{"$5", "$6", "$7"} {
+		assert.Contains(t, condition, placeholder)
+	}
+	assert.NotContains(t, condition, "$4")
+	assert.NotContains(t, condition, "$8")
+	assert.Equal(t, []interface{}{&orgID, pq.Array([]string{"platform"}), "alice"}, args)
+
+	// Only public and anonymized blocks are matched without the organization
+	assert.Contains(t, condition, "IN ('public', 'anonymized')")
+	assert.Contains(t, condition, "b.organization_id = $5 AND")
+}
+
+func TestVisibleBlocks(t *testing.T) {
+	acme, globex := uuid.New(), uuid.New()
+	caller := types.Caller{UserID: "alice", OrganizationID: &acme}
+
+	public := &types.Block{Topic: "public", Visibility: "public", OrganizationID: &globex}
+	own := &types.Block{Topic: "own", Visibility: "individual", OrganizationID: &acme, OwnerID: "alice"}
+	org := &types.Block{Topic: "org", Visibility: "org-private", OrganizationID: &acme}
+	otherOrg := &types.Block{Topic: "other org", Visibility: "org-private", OrganizationID: &globex}
+	otherOrgIndividual := &types.Block{Topic: "other org individual", Visibility: "individual", OrganizationID: &globex, OwnerID: "alice"}
+	colleague := &types.Block{Topic: "colleague", Visibility: "individual", OrganizationID: &acme, OwnerID: "bob"}
+
+	visible := visibleBlocks(caller, []*types.Block{public, own, org, otherOrg, otherOrgIndividual, colleague})
+
+	assert.Equal(t, []*types.Block{public, own, org}, visible, "blocks of other organizations and users never leak")
+}
@@ -0,0 +1,179 @@
+package evaluation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// Searcher runs a search; core.KnowledgeGraph satisfies it
+type Searcher interface {
+	Search(ctx context.Context, query string, opts types.SearchOptions) (*types.SearchResults, error)
+}
+
+// QuerySet is a labeled set of queries:
+//
+//	queries:
+//	  - query: how do we rotate database credentials
+//	    relevant:
+//	      - 3f2b9c9e-...   # block IDs that should be returned
+type QuerySet struct {
+	Queries []LabeledQuery `yaml:"queries" json:"queries"`
+}
+
+// LabeledQuery is a query and the blocks a good search returns for it
+type LabeledQuery struct {
+	Query    string      `yaml:"query" json:"query"`
+	Relevant []uuid.UUID `yaml:"-" json:"relevant"`
+
+	RelevantIDs []string `yaml:"relevant" json:"-"`
+}
+
+// LoadQuerySet reads a labeled query set from a YAML file
+func LoadQuerySet(path string) (*QuerySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var set QuerySet
+	if err := yaml.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid query set %s: %w", path, err)
+	}
+	if len(set.Queries) == 0 {
+		return nil, fmt.Errorf("query set %s has no queries", path)
+	}
+
+	for i := range set.Queries {
+		q := &set.Queries[i]
+		q.Query = strings.TrimSpace(q.Query)
+		if q.Query == "" {
+			return nil, fmt.Errorf("query %d in %s is empty", i+1, path)
+		}
+		if len(q.RelevantIDs) == 0 {
+			return nil, fmt.Errorf("query %q in %s lists no relevant blocks", q.Query, path)
+		}
+		for _, id := range q.RelevantIDs {
+			blockID, err := uuid.Parse(strings.TrimSpace(id))
+			if err != nil {
+				return nil, fmt.Errorf("query %q in %s: invalid block id %q", q.Query, path, id)
+			}
+			q.Relevant = append(q.Relevant, blockID)
+		}
+	}
+
+	return &set, nil
+}
+
+// Strategy is a named ranking configuration to evaluate
+type Strategy struct {
+	Name    string
+	Ranking types.RankingOptions
+}
+
+// QueryScore is how one strategy did on one query
+type QueryScore struct {
+	Query      string        `json:"query"`
+	Recall     float64       `json:"recall"`     // Share of the relevant blocks in the top K
+	Reciprocal float64       `json:"reciprocal"` // 1 / rank of the first relevant block (0 if none)
+	NDCG       float64       `json:"ndcg"`       // Normalized discounted cumulative gain at K
+	Latency    time.Duration `json:"latency"`
+}
+
+// Report summarizes a strategy over the whole query set
+type Report struct {
+	Strategy    string        `json:"strategy"`
+	K           int           `json:"k"`
+	Recall      float64       `json:"recall"` // Mean recall@K
+	MRR         float64       `json:"mrr"`    // Mean reciprocal rank
+	NDCG        float64       `json:"ndcg"`   // Mean nDCG@K
+	MeanLatency time.Duration `json:"mean_latency"`
+	Queries     []QueryScore  `json:"queries"`
+}
+
+// Evaluate runs every query in set with each strategy, retrieving the top k
+// results with base's other options, and reports the mean metrics per strategy
+func Evaluate(ctx context.Context, searcher Searcher, set *QuerySet, strategies []Strategy, base types.SearchOptions, k int) ([]Report, error) {
+	if k <= 0 {
+		k = 10
+	}
+
+	reports := make([]Report, 0, len(strategies))
+	for _, strategy := range strategies {
+		opts := base
+		opts.Limit = k
+		opts.Ranking = strategy.Ranking
+
+		report := Report{Strategy: strategy.Name, K: k}
+		var latency time.Duration
+		for _, q := range set.Queries {
+			results, err := searcher.Search(ctx, q.Query, opts)
+			if err != nil {
+				return nil, fmt.Errorf("strategy %s, query %q: %w", strategy.Name, q.Query, err)
+			}
+
+			score := scoreQuery(q, results, k)
+			report.Queries = append(report.Queries, score)
+			report.Recall += score.Recall
+			report.MRR += score.Reciprocal
+			report.NDCG += score.NDCG
+			latency += score.Latency
+		}
+
+		if n := len(set.Queries); n > 0 {
+			report.Recall /= float64(n)
+			report.MRR /= float64(n)
+			report.NDCG /= float64(n)
+			report.MeanLatency = latency / time.Duration(n)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// scoreQuery computes recall@k, reciprocal rank and nDCG@k (binary relevance)
+// of the results for q
+func scoreQuery(q LabeledQuery, results *types.SearchResults, k int) QueryScore {
+	score := QueryScore{Query: q.Query, Latency: results.SearchTime}
+
+	relevant := make(map[uuid.UUID]bool, len(q.Relevant))
+	for _, id := range q.Relevant {
+		relevant[id] = true
+	}
+	if len(relevant) == 0 {
+		return score
+	}
+
+	var found int
+	var dcg float64
+	for i, result := range results.Results {
+		if i >= k {
+			break
+		}
+		if result.Block == nil || !relevant[result.Block.ID] {
+			continue
+		}
+		found++
+		dcg += 1 / math.Log2(float64(i+2))
+		if score.Reciprocal == 0 {
+			score.Reciprocal = 1 / float64(i+1)
+		}
+	}
+
+	var ideal float64
+	for i := 0; i < len(relevant) && i < k; i++ {
+		ideal += 1 / math.Log2(float64(i+2))
+	}
+
+	score.Recall = float64(found) / float64(len(relevant))
+	score.NDCG = dcg / ideal
+	return score
+}
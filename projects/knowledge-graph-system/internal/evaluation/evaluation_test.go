@@ -0,0 +1,87 @@
+package evaluation
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSearcher returns fixed result orders per ranking strategy
+type fakeSearcher struct {
+	results map[string][]uuid.UUID
+	seen    []types.SearchOptions
+}
+
+func (f *fakeSearcher) Search(ctx context.Context, query string, opts types.SearchOptions) (*types.SearchResults, error) {
+	f.seen = append(f.seen, opts)
+	results := &types.SearchResults{SearchTime: 10 * time.Millisecond}
+	for _, id := range f.results[opts.Ranking.Strategy] {
+		results.Results = append(results.Results, types.SearchResult{Block: &types.Block{ID: id}})
+	}
+	return results, nil
+}
+
+func TestEvaluate(t *testing.T) {
+	a, b, noise := uuid.New(), uuid.New(), uuid.New()
+	set := &QuerySet{Queries: []LabeledQuery{{Query: "rotate credentials", Relevant: []uuid.UUID{a, b}}}}
+	searcher := &fakeSearcher{results: map[string][]uuid.UUID{
+		types.RankingRRF:    {a, b, noise},
+		types.RankingLinear: {noise, a},
+	}}
+
+	reports, err := Evaluate(context.Background(), searcher, set, []Strategy{
+		{Name: "rrf", Ranking: types.RankingOptions{Strategy: types.RankingRRF}},
+		{Name: "linear", Ranking: types.RankingOptions{Strategy: types.RankingLinear}},
+	}, types.SearchOptions{Limit: 50, IncludeNPlus: true}, 2)
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+
+	rrf, linear := reports[0], reports[1]
+	assert.Equal(t, "rrf", rrf.Strategy)
+	assert.Equal(t, 1.0, rrf.Recall)
+	assert.Equal(t, 1.0, rrf.MRR)
+	assert.InDelta(t, 1.0, rrf.NDCG, 1e-9)
+	assert.Equal(t, 10*time.Millisecond, rrf.MeanLatency)
+
+	assert.Equal(t, 0.5, linear.Recall)
+	assert.Equal(t, 0.5, linear.MRR)
+	assert.InDelta(t, (1/math.Log2(3))/(1+1/math.Log2(3)), linear.NDCG, 1e-9)
+
+	for _, opts := range searcher.seen {
+		assert.Equal(t, 2, opts.Limit, "searches retrieve the top K")
+		assert.True(t, opts.IncludeNPlus, "other options are kept")
+	}
+}
+
+func TestLoadQuerySet(t *testing.T) {
+	dir := t.TempDir()
+	id := uuid.New()
+
+	path := filepath.Join(dir, "queries.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("queries:\n  - query: ' rotate credentials '\n    relevant: ["+id.String()+"]\n"), 0644))
+
+	set, err := LoadQuerySet(path)
+	require.NoError(t, err)
+	require.Len(t, set.Queries, 1)
+	assert.Equal(t, "rotate credentials", set.Queries[0].Query)
+	assert.Equal(t, []uuid.UUID{id}, set.Queries[0].Relevant)
+
+	for _, bad := range []string{
+		"queries: []\n",
+		"queries:\n  - query: ''\n    relevant: [" + id.String() + "]\n",
+		"queries:\n  - query: rotate credentials\n",
+		"queries:\n  - query: rotate credentials\n    relevant: [not-a-uuid]\n",
+	} {
+		require.NoError(t, os.WriteFile(path, []byte(bad), 0644))
+		_, err := LoadQuerySet(path)
+		assert.Error(t, err, bad)
+	}
+}
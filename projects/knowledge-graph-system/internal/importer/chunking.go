@@ -3,9 +3,12 @@ package importer
 import (
 	"fmt"
 	"log"
+	"path"
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/tagging"
 )
 
 // ChunkOptions configures the chunking behavior
@@ -45,19 +48,13 @@ func ChunkDocument(doc *ParsedDocument, opts ChunkOptions) ([]PreBlock, error) {
 		opts.TargetExchanges = 4
 	}
 
-	// Route to appropriate chunking strategy based on file type
-	switch doc.Source.FileType {
-	case "conversation-log":
-		return chunkConversationLog(doc, opts)
-	case "spec", "doc", "readme", "mission":
-		log.Printf("[CHUNK] Routing to documentation chunker for type: %s", doc.Source.FileType)
-		return chunkDocumentation(doc, opts)
-	case "working-file":
-		return chunkWorkingFile(doc, opts)
-	default:
+	// Route to the chunker registered for the file type
+	format, ok := LookupFormat(doc.Source.FileType)
+	if !ok {
 		log.Printf("[CHUNK] ERROR: Unsupported file type: %s", doc.Source.FileType)
 		return nil, fmt.Errorf("unsupported file type: %s", doc.Source.FileType)
 	}
+	return format.Chunker.Chunk(doc, opts)
 }
 
 // chunkConversationLog handles conversation log files with milestone-based chunking
@@ -134,11 +131,100 @@ func chunkDocumentation(doc *ParsedDocument, opts ChunkOptions) ([]PreBlock, err
 	return blocks, nil
 }
 
-// chunkWorkingFile handles .working.md files (Week 2+)
+// chunkWorkingFile turns a .working.md snapshot into up to four blocks: current
+// state, open questions, decisions and modified files. Working files are rewritten
+// in place, so a re-import replaces these blocks instead of adding to them.
 func chunkWorkingFile(doc *ParsedDocument, opts ChunkOptions) ([]PreBlock, error) {
-	// Placeholder for future implementation
-	// Will extract current state, decisions, and file modifications
-	return nil, fmt.Errorf("working file chunking not yet implemented")
+	log.Printf("[CHUNK-WORK] Processing working file: %s", doc.Source.FilePath)
+	log.Printf("[CHUNK-WORK] Total sections: %d", len(doc.Sections))
+
+	docTitle := extractDocumentTitle(doc)
+
+	// Group sections by the block they belong in
+	grouped := make(map[string][]Section)
+	for _, section := range workingFileSections(doc.Sections) {
+		kind := classifyWorkingSection(section.Title)
+		log.Printf("[CHUNK-WORK] Section %q → %s", section.Title, kind)
+		grouped[kind] = append(grouped[kind], section)
+	}
+
+	var blocks []PreBlock
+	for _, kind := range workingBlockOrder {
+		if block := createWorkingBlock(doc, docTitle, kind, grouped[kind]); block != nil {
+			blocks = append(blocks, *block)
+		}
+	}
+
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no content found in working file")
+	}
+
+	log.Printf("[CHUNK-WORK] Created %d blocks from working file", len(blocks))
+	return blocks, nil
+}
+
+// chunkConversations handles transcripts whose parser already paired turns into
+// exchanges. Each conversation is chunked like a session log, with the
+// conversation ID as session_id so re-imports produce the same blocks.
+func chunkConversations(doc *ParsedDocument, opts ChunkOptions) ([]PreBlock, error) {
+	log.Printf("[CHUNK-CONV] Processing %d conversations from %s", len(doc.Conversations), doc.Source.FilePath)
+
+	var blocks []PreBlock
+	for _, conversation := range doc.Conversations {
+		if len(conversation.Exchanges) == 0 {
+			continue
+		}
+
+		// Blocks carry the conversation's metadata on top of the file's
+		convDoc := *doc
+		convDoc.Metadata = make(map[string]interface{}, len(doc.Metadata)+len(conversation.Metadata))
+		for k, v := range doc.Metadata {
+			convDoc.Metadata[k] = v
+		}
+		for k, v := range conversation.Metadata {
+			convDoc.Metadata[k] = v
+		}
+
+		topic := conversation.Title
+		if topic == "" {
+			topic = shortTitle(conversation.Exchanges[0].Question, 60)
+		}
+		startedAt := conversation.StartedAt
+		if startedAt.IsZero() {
+			startedAt = conversation.Exchanges[0].Timestamp
+		}
+
+		if len(conversation.Exchanges) <= opts.TargetExchanges*2 {
+			blocks = append(blocks, createPreBlock(topic, conversation.Exchanges, &convDoc, conversation.ID, startedAt, 1, 1))
+		} else {
+			blocks = append(blocks, splitIntoBlocks(topic, conversation.Exchanges, &convDoc, conversation.ID, startedAt, opts)...)
+		}
+	}
+
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no exchanges found in transcript")
+	}
+
+	log.Printf("[CHUNK-CONV] Created %d blocks from %s", len(blocks), doc.Source.FilePath)
+	return blocks, nil
+}
+
+// shortTitle reduces free text (e.g. a prompt) to its first line, cut at a word
+// boundary to at most maxLen characters
+func shortTitle(text string, maxLen int) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexByte(text, '\n'); i != -1 {
+		text = strings.TrimSpace(text[:i])
+	}
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+	cut := string(runes[:maxLen])
+	if i := strings.LastIndexByte(cut, ' '); i > maxLen/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "..."
 }
 
 // extractSessionTopic extracts the session topic/title from metadata or first H1
@@ -422,7 +508,7 @@ func splitIntoBlocks(
 		topic := baseTopic
 		if totalChunks > 1 {
 			// Add temporal context from first/last exchange in chunk
-			firstTitle := extractMilestoneTitle(chunkExchanges[0].Question)
+			firstTitle := shortTitle(extractMilestoneTitle(chunkExchanges[0].Question), 60)
 			topic = fmt.Sprintf("%s (Part %d: %s)", baseTopic, chunkNumber, firstTitle)
 		}
 
@@ -440,6 +526,53 @@ func splitIntoBlocks(
 	return blocks
 }
 
+// chunkAppendedExchanges chunks the milestones appended to a conversation log
+// after it was last imported. The first previousExchanges exchanges are already
+// stored as previousChunks blocks, so the new blocks continue the same session_id
+// with chunk numbers previousChunks+1..N and total_chunks set to N.
+func chunkAppendedExchanges(doc *ParsedDocument, opts ChunkOptions, previousExchanges, previousChunks int) []PreBlock {
+	exchanges := extractExchangesFromSections(doc.Sections)
+	if len(exchanges) <= previousExchanges {
+		return nil
+	}
+	appended := exchanges[previousExchanges:]
+
+	if opts.MinExchangesPerBlock < 1 {
+		opts.MinExchangesPerBlock = 1
+	}
+	if opts.TargetExchanges < 3 || opts.TargetExchanges > 5 {
+		opts.TargetExchanges = 4
+	}
+
+	// Group the new exchanges, folding a short tail into the previous group
+	var groups [][]PreExchange
+	for i := 0; i < len(appended); i += opts.TargetExchanges {
+		end := i + opts.TargetExchanges
+		if end > len(appended) || len(appended)-end < opts.MinExchangesPerBlock {
+			end = len(appended)
+		}
+		groups = append(groups, appended[i:end])
+		if end == len(appended) {
+			break
+		}
+	}
+
+	sessionTopic := extractSessionTopic(doc)
+	sessionDate := extractSessionDate(doc)
+	sessionID := generateSessionID(doc.Source.FilePath, sessionDate)
+	totalChunks := previousChunks + len(groups)
+
+	blocks := make([]PreBlock, 0, len(groups))
+	for i, group := range groups {
+		chunkNumber := previousChunks + i + 1
+		topic := fmt.Sprintf("%s (Part %d: %s)", sessionTopic, chunkNumber, extractMilestoneTitle(group[0].Question))
+		blocks = append(blocks, createPreBlock(topic, group, doc, sessionID, sessionDate, chunkNumber, totalChunks))
+	}
+
+	log.Printf("[CHUNK] %s: %d appended exchanges as chunks %d-%d", doc.Source.FilePath, len(appended), previousChunks+1, totalChunks)
+	return blocks
+}
+
 // createPreBlock constructs a PreBlock from exchanges and metadata
 func createPreBlock(
 	topic string,
@@ -662,16 +795,16 @@ func createDocumentationBlock(doc *ParsedDocument, docTitle string, section Sect
 	tags := extractDocumentationTags(doc, section)
 
 	block := &PreBlock{
-		Topic:       fmt.Sprintf("%s: %s", docTitle, section.Title),
-		Exchanges:   []PreExchange{exchange},
-		Tags:        tags,
-		ProjectPath: extractProjectPath(doc.Source.FilePath),
-		SourceFile:  doc.Source.FilePath,
-		SourceType:  doc.Source.FileType,
-		SourceHash:  doc.Source.FileHash,
-		StartedAt:   doc.Source.LastModified,
-		CompletedAt: &doc.Source.LastModified,
-		Visibility:  doc.Source.Visibility,
+		Topic:          fmt.Sprintf("%s: %s", docTitle, section.Title),
+		Exchanges:      []PreExchange{exchange},
+		Tags:           tags,
+		ProjectPath:    extractProjectPath(doc.Source.FilePath),
+		SourceFile:     doc.Source.FilePath,
+		SourceType:     doc.Source.FileType,
+		SourceHash:     doc.Source.FileHash,
+		StartedAt:      doc.Source.LastModified,
+		CompletedAt:    &doc.Source.LastModified,
+		Visibility:     doc.Source.Visibility,
 		OrganizationID: doc.Source.OrganizationID,
 		Metadata: map[string]interface{}{
 			"section_level": section.Level,
@@ -696,35 +829,12 @@ func extractDocumentationTags(doc *ParsedDocument, section Section) []string {
 	}
 
 	// Add common keywords from title and content
-	keywords := extractKeywords(section.Title + " " + section.Content)
+	keywords := tagging.Keywords(section.Title + " " + section.Content)
 	tags = append(tags, keywords...)
 
 	return deduplicateTags(tags)
 }
 
-// extractKeywords does simple keyword extraction from text
-func extractKeywords(text string) []string {
-	// Lowercase and split on whitespace/punctuation
-	text = strings.ToLower(text)
-	
-	// Common technical keywords to look for
-	keywords := []string{}
-	technicalTerms := []string{
-		"api", "database", "frontend", "backend", "deployment", "testing",
-		"authentication", "authorization", "cache", "queue", "migration",
-		"docker", "kubernetes", "postgres", "redis", "nginx", "react",
-		"golang", "python", "typescript", "javascript", "sql",
-	}
-
-	for _, term := range technicalTerms {
-		if strings.Contains(text, term) {
-			keywords = append(keywords, term)
-		}
-	}
-
-	return keywords
-}
-
 // deduplicateTags removes duplicate tags
 func deduplicateTags(tags []string) []string {
 	seen := make(map[string]bool)
@@ -740,3 +850,340 @@ func deduplicateTags(tags []string) []string {
 	
 	return result
 }
+
+// Working file block kinds, in the order their blocks are created
+const (
+	workingStateBlock     = "current-state"
+	workingQuestionsBlock = "open-questions"
+	workingDecisionsBlock = "decisions"
+	workingFilesBlock     = "files"
+)
+
+var workingBlockOrder = []string{workingStateBlock, workingQuestionsBlock, workingDecisionsBlock, workingFilesBlock}
+
+// workingBlockTitles are appended to the document title to form each block's topic
+var workingBlockTitles = map[string]string{
+	workingStateBlock:     "Current State",
+	workingQuestionsBlock: "Open Questions",
+	workingDecisionsBlock: "Decisions",
+	workingFilesBlock:     "Files",
+}
+
+// workingSectionKeywords routes a section to a block by words in its title; the
+// first match wins and anything unmatched describes current state
+var workingSectionKeywords = []struct {
+	kind     string
+	keywords []string
+}{
+	{workingFilesBlock, []string{"files", "git status"}},
+	{workingQuestionsBlock, []string{"question", "open", "next", "todo", "blocker", "pending", "remaining"}},
+	{workingDecisionsBlock, []string{"decision", "learning", "pivot"}},
+}
+
+// workingFileSections flattens a working file into its top-level sections. The
+// text under the H1 (usually **Status:** lines) becomes a "Status" section.
+func workingFileSections(sections []Section) []Section {
+	var result []Section
+
+	for _, section := range sections {
+		if section.Level != 1 {
+			result = append(result, section)
+			continue
+		}
+		if strings.TrimSpace(section.Content) != "" {
+			result = append(result, Section{
+				Level:   2,
+				Title:   "Status",
+				Content: section.Content,
+				Line:    section.Line,
+			})
+		}
+		result = append(result, section.Children...)
+	}
+
+	return result
+}
+
+// classifyWorkingSection returns the block kind a working file section belongs in
+func classifyWorkingSection(title string) string {
+	titleLower := strings.ToLower(title)
+	for _, group := range workingSectionKeywords {
+		for _, keyword := range group.keywords {
+			if strings.Contains(titleLower, keyword) {
+				return group.kind
+			}
+		}
+	}
+	return workingStateBlock
+}
+
+// createWorkingBlock creates one working file block with an exchange per section
+func createWorkingBlock(doc *ParsedDocument, docTitle, kind string, sections []Section) *PreBlock {
+	var exchanges []PreExchange
+	var sectionTitles []string
+	var text strings.Builder
+
+	for _, section := range sections {
+		answer := strings.TrimSpace(renderSection(section))
+		if answer == "" {
+			log.Printf("[CHUNK-WORK] Skipping section %q - no content", section.Title)
+			continue
+		}
+		exchanges = append(exchanges, PreExchange{
+			Question:  section.Title,
+			Answer:    answer,
+			Timestamp: doc.Source.LastModified,
+			ModelUsed: "working-file",
+		})
+		sectionTitles = append(sectionTitles, section.Title)
+		text.WriteString(section.Title + " " + answer + "\n")
+	}
+
+	if len(exchanges) == 0 {
+		return nil
+	}
+
+	metadata := map[string]interface{}{
+		"doc_title":      docTitle,
+		"working_block":  kind,
+		"section_titles": sectionTitles,
+	}
+	if kind == workingFilesBlock {
+		metadata["files"] = extractWorkingFiles(text.String())
+	}
+
+	tags := []string{doc.Source.FileType, kind}
+	tags = append(tags, extractTags(doc.Metadata)...)
+	tags = append(tags, tagging.Keywords(text.String())...)
+
+	return &PreBlock{
+		Topic:          fmt.Sprintf("%s: %s", docTitle, workingBlockTitles[kind]),
+		Exchanges:      exchanges,
+		Metadata:       metadata,
+		Tags:           deduplicateTags(tags),
+		ProjectPath:    extractProjectPath(doc.Source.FilePath),
+		SourceFile:     doc.Source.FilePath,
+		SourceType:     doc.Source.FileType,
+		SourceHash:     doc.Source.FileHash,
+		StartedAt:      doc.Source.LastModified,
+		CompletedAt:    &doc.Source.LastModified,
+		Visibility:     doc.Source.Visibility,
+		OrganizationID: doc.Source.OrganizationID,
+	}
+}
+
+// renderSection renders a section's content followed by its subsections as markdown
+func renderSection(section Section) string {
+	var b strings.Builder
+	b.WriteString(section.Content)
+	for _, child := range section.Children {
+		b.WriteString(fmt.Sprintf("\n%s %s\n", strings.Repeat("#", child.Level), child.Title))
+		b.WriteString(renderSection(child))
+	}
+	return b.String()
+}
+
+var (
+	// "- `internal/importer/chunking.go` - what changed"
+	workingFileItemRe = regexp.MustCompile("^\\s*(?:[-*]|\\d+\\.)\\s+`([^`]+)`")
+	// "M internal/importer/chunking.go" from pasted git status output
+	workingGitStatusRe = regexp.MustCompile(`^\s*(?:[MADRCU?]{1,2})\s+(\S+)$`)
+)
+
+// extractWorkingFiles lists the file paths named in a working file's file sections
+func extractWorkingFiles(text string) []string {
+	files := []string{}
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(text, "\n") {
+		var path string
+		if m := workingFileItemRe.FindStringSubmatch(line); m != nil {
+			path = m[1]
+		} else if m := workingGitStatusRe.FindStringSubmatch(line); m != nil {
+			path = m[1]
+		}
+		// Function names, commands and bare words are not files
+		if path == "" || strings.ContainsAny(path, " ()") || !strings.ContainsAny(path, "./") || seen[path] {
+			continue
+		}
+		seen[path] = true
+		files = append(files, path)
+	}
+
+	return files
+}
+
+// gitTopicWindow is the longest gap between consecutive commits of one block
+const gitTopicWindow = 8 * time.Hour
+
+var (
+	// "feat(importer): add git history" → "importer"
+	conventionalScopeRe = regexp.MustCompile(`^[a-zA-Z]+\(([^)]+)\)!?:`)
+	// "[user-013] Add git history" → "user-013"
+	bracketPrefixRe = regexp.MustCompile(`^\[([^\]]+)\]`)
+)
+
+// chunkGitHistory groups consecutive commits on the same topic into blocks, one
+// exchange per commit. A block ends when the topic changes, when the next commit
+// comes more than gitTopicWindow later, or at twice the target exchange count.
+func chunkGitHistory(doc *ParsedDocument, opts ChunkOptions) ([]PreBlock, error) {
+	log.Printf("[CHUNK-GIT] Processing %d commits from %s", len(doc.Commits), doc.Source.FilePath)
+
+	if len(doc.Commits) == 0 {
+		return nil, fmt.Errorf("no commits found in repository")
+	}
+
+	maxCommits := opts.TargetExchanges * 2
+	if maxCommits < 1 {
+		maxCommits = 1
+	}
+
+	var blocks []PreBlock
+	var group []Commit
+	topic := ""
+	for _, commit := range doc.Commits {
+		key := commitTopic(commit)
+		if len(group) > 0 && (key != topic || len(group) >= maxCommits ||
+			commit.Time.Sub(group[len(group)-1].Time) > gitTopicWindow) {
+			blocks = append(blocks, createGitBlock(doc, topic, group))
+			group = nil
+		}
+		topic = key
+		group = append(group, commit)
+	}
+	blocks = append(blocks, createGitBlock(doc, topic, group))
+
+	log.Printf("[CHUNK-GIT] Created %d blocks from %s", len(blocks), doc.Source.FilePath)
+	return blocks, nil
+}
+
+// commitTopic returns the conventional-commit scope or bracketed prefix of a
+// commit's subject, or else the directory most of its files are in ("" for the
+// repository root)
+func commitTopic(commit Commit) string {
+	if m := conventionalScopeRe.FindStringSubmatch(commit.Subject); m != nil {
+		return strings.ToLower(strings.TrimSpace(m[1]))
+	}
+	if m := bracketPrefixRe.FindStringSubmatch(commit.Subject); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+
+	counts := make(map[string]int)
+	best := ""
+	for _, file := range commit.Files {
+		dir := path.Dir(file)
+		counts[dir]++
+		if counts[dir] > counts[best] || (counts[dir] == counts[best] && dir < best) {
+			best = dir
+		}
+	}
+	if best == "." {
+		return ""
+	}
+	return best
+}
+
+// createGitBlock creates one block from consecutive commits on a topic
+func createGitBlock(doc *ParsedDocument, topicKey string, commits []Commit) PreBlock {
+	repository, _ := doc.Metadata["repository"].(string)
+
+	exchanges := make([]PreExchange, 0, len(commits))
+	commitInfo := make([]map[string]interface{}, 0, len(commits))
+	hashes := make([]string, 0, len(commits))
+	authors := []string{}
+	files := []string{}
+	seen := make(map[string]bool)
+	var text strings.Builder
+
+	for _, commit := range commits {
+		subject := commit.Subject
+		if subject == "" {
+			subject = "Commit " + shortHash(commit.Hash)
+		}
+		exchanges = append(exchanges, PreExchange{
+			Question:  subject,
+			Answer:    commitAnswer(commit),
+			Timestamp: commit.Time,
+			ModelUsed: "git",
+		})
+
+		hashes = append(hashes, commit.Hash)
+		commitInfo = append(commitInfo, map[string]interface{}{
+			"hash":         commit.Hash,
+			"author":       commit.Author,
+			"author_email": commit.AuthorEmail,
+			"files":        commit.Files,
+		})
+		if !seen["author:"+commit.Author] {
+			seen["author:"+commit.Author] = true
+			authors = append(authors, commit.Author)
+		}
+		for _, file := range commit.Files {
+			if !seen["file:"+file] {
+				seen["file:"+file] = true
+				files = append(files, file)
+			}
+		}
+		text.WriteString(subject + " " + commit.Body + "\n")
+	}
+
+	title := topicKey
+	if title == "" {
+		title = extractDocumentTitle(doc)
+	}
+	// The topic key already names the scope, so drop it from the subject
+	subject := exchanges[0].Question
+	for _, re := range []*regexp.Regexp{conventionalScopeRe, bracketPrefixRe} {
+		if loc := re.FindStringIndex(subject); loc != nil && strings.TrimSpace(subject[loc[1]:]) != "" {
+			subject = strings.TrimSpace(subject[loc[1]:])
+			break
+		}
+	}
+	topic := fmt.Sprintf("%s: %s", title, shortTitle(subject, 60))
+	if len(commits) > 1 {
+		topic += fmt.Sprintf(" (+%d more)", len(commits)-1)
+	}
+
+	tags := []string{"git", doc.Source.FileType}
+	tags = append(tags, tagging.Keywords(text.String())...)
+
+	completedAt := commits[len(commits)-1].Time
+	return PreBlock{
+		Topic:     topic,
+		Exchanges: exchanges,
+		Metadata: map[string]interface{}{
+			"repository":    repository,
+			"topic_key":     topicKey,
+			"commits":       commitInfo,
+			"commit_hashes": hashes,
+			"authors":       authors,
+			"files":         files,
+			"commit_count":  len(commits),
+		},
+		Tags:           deduplicateTags(tags),
+		ProjectPath:    repository,
+		SourceFile:     doc.Source.FilePath,
+		SourceType:     doc.Source.FileType,
+		SourceHash:     doc.Source.FileHash,
+		StartedAt:      commits[0].Time,
+		CompletedAt:    &completedAt,
+		Visibility:     doc.Source.Visibility,
+		OrganizationID: doc.Source.OrganizationID,
+	}
+}
+
+// commitAnswer renders a commit's author, date, message body and files
+func commitAnswer(commit Commit) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Commit %s by %s on %s\n", shortHash(commit.Hash), commit.Author, commit.Time.Format("2006-01-02 15:04")))
+	if commit.Body != "" {
+		b.WriteString("\n" + commit.Body + "\n")
+	}
+	if len(commit.Files) > 0 {
+		b.WriteString("\nFiles:\n")
+		for _, file := range commit.Files {
+			b.WriteString("- " + file + "\n")
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
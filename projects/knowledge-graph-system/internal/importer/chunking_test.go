@@ -566,6 +566,143 @@ func TestChunkDocument_VisibilityPreservation(t *testing.T) {
 	assert.Equal(t, &orgID, block.OrganizationID)
 }
 
+// TestChunkDocument_WorkingFile tests grouping a .working.md into state, questions, decisions and files blocks
+func TestChunkDocument_WorkingFile(t *testing.T) {
+	content := `# Working Context - Import System
+
+**Status:** Registry done, working files next
+
+## What We Built
+
+Parser registry with per-format chunkers.
+
+## Key Decisions
+
+- Formats register from init
+
+## Next Steps
+
+1. Chunk .working.md files
+
+## Files Modified This Session
+
+**Modified:**
+- ` + "`internal/importer/chunking.go`" + ` - working file chunker
+- ` + "`chunkWorkingFile()`" + ` - new function
+
+## Git Status
+
+` + "```" + `
+M internal/importer/registry.go
+?? internal/importer/registry_test.go
+` + "```" + `
+`
+	doc, err := parseMarkdown(ImportSource{
+		FilePath: "/test/project/.working.md",
+		FileType: "working-file",
+		FileHash: "abc123",
+	}, content)
+	require.NoError(t, err)
+
+	blocks, err := ChunkDocument(doc, DefaultChunkOptions())
+	require.NoError(t, err)
+	require.Len(t, blocks, 4)
+
+	assert.Equal(t, "Working Context - Import System: Current State", blocks[0].Topic)
+	require.Len(t, blocks[0].Exchanges, 2)
+	assert.Equal(t, "Status", blocks[0].Exchanges[0].Question)
+	assert.Contains(t, blocks[0].Exchanges[0].Answer, "Registry done")
+	assert.Equal(t, "What We Built", blocks[0].Exchanges[1].Question)
+
+	assert.Equal(t, "open-questions", blocks[1].Metadata["working_block"])
+	assert.Equal(t, "Next Steps", blocks[1].Exchanges[0].Question)
+
+	assert.Equal(t, "decisions", blocks[2].Metadata["working_block"])
+	assert.Equal(t, "Key Decisions", blocks[2].Exchanges[0].Question)
+
+	files := blocks[3]
+	assert.Equal(t, "files", files.Metadata["working_block"])
+	assert.Len(t, files.Exchanges, 2)
+	assert.Equal(t, []string{
+		"internal/importer/chunking.go",
+		"internal/importer/registry.go",
+		"internal/importer/registry_test.go",
+	}, files.Metadata["files"])
+
+	for _, block := range blocks {
+		assert.Equal(t, "working-file", block.SourceType)
+		assert.Equal(t, "abc123", block.SourceHash)
+		assert.Contains(t, block.Tags, "working-file")
+	}
+}
+
+// TestChunkDocument_EmptyWorkingFile tests error handling for working files with no content
+func TestChunkDocument_EmptyWorkingFile(t *testing.T) {
+	doc := &ParsedDocument{
+		Source:   ImportSource{FileType: "working-file", FilePath: "/test/.working.md"},
+		Metadata: make(map[string]interface{}),
+		Sections: []Section{{Level: 1, Title: "Working Context"}, {Level: 2, Title: "Next Steps"}},
+	}
+
+	blocks, err := ChunkDocument(doc, DefaultChunkOptions())
+
+	assert.Error(t, err)
+	assert.Nil(t, blocks)
+}
+
+// TestChunkDocument_Conversations tests chunking parsed transcripts per conversation
+func TestChunkDocument_Conversations(t *testing.T) {
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	exchanges := func(n int) []PreExchange {
+		var result []PreExchange
+		for i := 0; i < n; i++ {
+			result = append(result, PreExchange{
+				Question:  fmt.Sprintf("Question %d\nwith a second line", i+1),
+				Answer:    fmt.Sprintf("Answer %d", i+1),
+				Timestamp: start.Add(time.Duration(i) * time.Minute),
+				ModelUsed: "gpt-4o",
+			})
+		}
+		return result
+	}
+
+	doc := &ParsedDocument{
+		Source:   ImportSource{FilePath: "/exports/conversations.json", FileType: "chatgpt-export", FileHash: "h"},
+		Metadata: map[string]interface{}{"export_format": "chatgpt"},
+		Conversations: []Conversation{
+			{ID: "conv-short", Title: "Short chat", StartedAt: start, Exchanges: exchanges(2),
+				Metadata: map[string]interface{}{"conversation_id": "conv-short"}},
+			{ID: "conv-empty", Title: "Nothing said"},
+			{ID: "conv-long", StartedAt: start, Exchanges: exchanges(12)},
+		},
+	}
+
+	blocks, err := ChunkDocument(doc, DefaultChunkOptions())
+	require.NoError(t, err)
+	require.Len(t, blocks, 4)
+
+	short := blocks[0]
+	assert.Equal(t, "Short chat", short.Topic)
+	assert.Equal(t, "conv-short", short.Metadata["session_id"])
+	assert.Equal(t, "conv-short", short.Metadata["conversation_id"])
+	assert.Equal(t, "chatgpt", short.Metadata["export_format"])
+	assert.Equal(t, start, short.StartedAt)
+	assert.Len(t, short.Exchanges, 2)
+
+	for i, block := range blocks[1:] {
+		assert.Equal(t, "conv-long", block.Metadata["session_id"])
+		assert.Equal(t, i+1, block.Metadata["chunk_number"])
+		assert.Equal(t, 3, block.Metadata["total_chunks"])
+		assert.NotContains(t, block.Topic, "\n", "untitled conversations are named after their first prompt line")
+	}
+	assert.Equal(t, "Question 1 (Part 1: Question 1)", blocks[1].Topic)
+}
+
+func TestShortTitle(t *testing.T) {
+	assert.Equal(t, "Short", shortTitle("  Short\nsecond line", 20))
+	assert.Equal(t, "How do I index a...", shortTitle("How do I index a JSONB column?", 20))
+}
+
 // Helper function to create test conversation logs
 func createTestConversationLog(exchangeCount int, topic string) *ParsedDocument {
 	sections := []Section{
@@ -610,3 +747,66 @@ func createTestConversationLog(exchangeCount int, topic string) *ParsedDocument
 		Sections: sections,
 	}
 }
+
+func TestChunkDocument_GitHistory(t *testing.T) {
+	start := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	commit := func(hash, subject string, offset time.Duration, files ...string) Commit {
+		return Commit{Hash: hash, Author: "Ada", AuthorEmail: "ada@example.com", Time: start.Add(offset), Subject: subject, Files: files}
+	}
+
+	doc := &ParsedDocument{
+		Source:   ImportSource{FilePath: "/src/repo/.git", FileType: "git-history", FileHash: "head", Visibility: "org-private"},
+		Metadata: map[string]interface{}{"repository": "/src/repo", "title": "repo"},
+		Commits: []Commit{
+			commit("a1", "feat(importer): add git parser", 0, "internal/importer/parsers/git.go"),
+			commit("a2", "fix(importer): handle empty repos", time.Hour, "internal/importer/parsers/git.go", "internal/importer/discovery.go"),
+			commit("a3", "Update docs", 2*time.Hour, "docs/a.md", "docs/b.md", "README.md"),
+			commit("a4", "More docs", 3*time.Hour, "docs/c.md"),
+			commit("a5", "More docs after a break", 30*time.Hour, "docs/c.md"),
+			commit("a6", "Bump version", 31*time.Hour, "VERSION"),
+		},
+	}
+
+	blocks, err := ChunkDocument(doc, DefaultChunkOptions())
+	require.NoError(t, err)
+	require.Len(t, blocks, 4)
+
+	importerBlock := blocks[0]
+	assert.Equal(t, "importer: add git parser (+1 more)", importerBlock.Topic)
+	assert.Equal(t, []string{"a1", "a2"}, importerBlock.Metadata["commit_hashes"])
+	assert.Equal(t, []string{"Ada"}, importerBlock.Metadata["authors"])
+	assert.Equal(t, []string{"internal/importer/parsers/git.go", "internal/importer/discovery.go"}, importerBlock.Metadata["files"])
+	assert.Equal(t, "/src/repo", importerBlock.ProjectPath)
+	assert.Equal(t, "git-history", importerBlock.SourceType)
+	assert.Equal(t, "org-private", importerBlock.Visibility)
+	assert.Equal(t, start, importerBlock.StartedAt)
+	require.NotNil(t, importerBlock.CompletedAt)
+	assert.Equal(t, start.Add(time.Hour), *importerBlock.CompletedAt)
+
+	require.Len(t, importerBlock.Exchanges, 2)
+	assert.Equal(t, "fix(importer): handle empty repos", importerBlock.Exchanges[1].Question)
+	assert.Contains(t, importerBlock.Exchanges[1].Answer, "Commit a2 by Ada")
+	assert.Contains(t, importerBlock.Exchanges[1].Answer, "- internal/importer/discovery.go")
+	assert.Equal(t, "git", importerBlock.Exchanges[1].ModelUsed)
+
+	assert.Equal(t, "docs: Update docs (+1 more)", blocks[1].Topic, "commits share the directory most of their files are in")
+	assert.Equal(t, "docs: More docs after a break", blocks[2].Topic, "a long gap starts a new block")
+	assert.Equal(t, "repo: Bump version", blocks[3].Topic, "root-level commits use the repository name")
+}
+
+func TestChunkDocument_GitHistoryNoCommits(t *testing.T) {
+	doc := &ParsedDocument{
+		Source:   ImportSource{FilePath: "/src/repo/.git", FileType: "git-history"},
+		Metadata: map[string]interface{}{"repository": "/src/repo"},
+	}
+
+	_, err := ChunkDocument(doc, DefaultChunkOptions())
+	assert.Error(t, err)
+}
+
+func TestCommitTopic(t *testing.T) {
+	assert.Equal(t, "importer", commitTopic(Commit{Subject: "feat(Importer)!: breaking"}))
+	assert.Equal(t, "user-013", commitTopic(Commit{Subject: "[user-013] Import git history"}))
+	assert.Equal(t, "cmd/kg", commitTopic(Commit{Subject: "Add flag", Files: []string{"cmd/kg/a.go", "cmd/kg/b.go", "go.mod"}}))
+	assert.Equal(t, "", commitTopic(Commit{Subject: "Tidy", Files: []string{"go.mod"}}))
+}
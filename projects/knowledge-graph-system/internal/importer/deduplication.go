@@ -2,14 +2,24 @@ package importer
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/TheGenXCoder/knowledge-graph/internal/core"
 	"github.com/TheGenXCoder/knowledge-graph/internal/db"
 	"github.com/google/uuid"
 )
 
+// importHistoryStore is the subset of PostgresDB needed to make import decisions
+type importHistoryStore interface {
+	QueryImportHistory(ctx context.Context, sourceFile, fileHash string) (*db.ImportHistoryRecord, error)
+	QueryLatestImport(ctx context.Context, sourceFile string) (*db.ImportHistoryRecord, error)
+	QueryBlocksBySource(ctx context.Context, sourceFile string) ([]db.BlockSourceRecord, error)
+}
+
 // DeduplicateBlocks checks if blocks already exist using hash-based detection
 // Returns import decisions for each PreBlock indicating whether to insert, update, or skip
 func DeduplicateBlocks(ctx context.Context, kg core.KnowledgeGraph, preBlocks []PreBlock) ([]ImportDecision, error) {
@@ -17,10 +27,7 @@ func DeduplicateBlocks(ctx context.Context, kg core.KnowledgeGraph, preBlocks []
 
 	// Get access to the underlying database for import history queries
 	// We need to cast to PostgresDB to access the database connection
-	postgresKG, ok := kg.(interface {
-		QueryImportHistory(ctx context.Context, sourceFile, fileHash string) (*db.ImportHistoryRecord, error)
-		QueryBlocksBySource(ctx context.Context, sourceFile string) ([]db.BlockSourceRecord, error)
-	})
+	postgresKG, ok := kg.(importHistoryStore)
 	if !ok {
 		return nil, fmt.Errorf("knowledge graph does not support import history queries")
 	}
@@ -38,10 +45,7 @@ func DeduplicateBlocks(ctx context.Context, kg core.KnowledgeGraph, preBlocks []
 }
 
 // deduplicateBlock determines the import action for a single PreBlock
-func deduplicateBlock(ctx context.Context, kg interface {
-	QueryImportHistory(ctx context.Context, sourceFile, fileHash string) (*db.ImportHistoryRecord, error)
-	QueryBlocksBySource(ctx context.Context, sourceFile string) ([]db.BlockSourceRecord, error)
-}, preBlock *PreBlock) (ImportDecision, error) {
+func deduplicateBlock(ctx context.Context, kg importHistoryStore, preBlock *PreBlock) (ImportDecision, error) {
 
 	// Step 1: Check import_history for (source_file, file_hash)
 	historyRecord, err := kg.QueryImportHistory(ctx, preBlock.SourceFile, preBlock.SourceHash)
@@ -49,17 +53,8 @@ func deduplicateBlock(ctx context.Context, kg interface {
 		return ImportDecision{}, fmt.Errorf("failed to query import history: %w", err)
 	}
 
-	// Case 1: File never imported before (NOT found)
-	if err == sql.ErrNoRows || historyRecord == nil {
-		return ImportDecision{
-			Action:   "insert",
-			PreBlock: preBlock,
-			Reason:   "new file - never imported before",
-		}, nil
-	}
-
-	// Case 2: File found with SAME hash (unchanged)
-	if historyRecord.FileHash == preBlock.SourceHash {
+	// Case 1: File found with SAME hash and that import completed (unchanged)
+	if err == nil && historyRecord != nil && historyRecord.Status == "completed" {
 		// Apply special rules for conversation logs vs specs/docs
 		if isImmutableSourceType(preBlock.SourceType) {
 			return ImportDecision{
@@ -76,6 +71,21 @@ func deduplicateBlock(ctx context.Context, kg interface {
 		}, nil
 	}
 
+	// Step 2: Look for any earlier completed import of this file
+	latestRecord, err := kg.QueryLatestImport(ctx, preBlock.SourceFile)
+	if err != nil && err != sql.ErrNoRows {
+		return ImportDecision{}, fmt.Errorf("failed to query import history: %w", err)
+	}
+
+	// Case 2: File never imported before (NOT found)
+	if err == sql.ErrNoRows || latestRecord == nil {
+		return ImportDecision{
+			Action:   "insert",
+			PreBlock: preBlock,
+			Reason:   "new file - never imported before",
+		}, nil
+	}
+
 	// Case 3: File found with DIFFERENT hash (file changed)
 	// Check special rules before deciding to update
 	if isImmutableSourceType(preBlock.SourceType) {
@@ -103,17 +113,104 @@ func deduplicateBlock(ctx context.Context, kg interface {
 		Action:      "update",
 		PreBlock:    preBlock,
 		ExistingID:  existingID,
-		Reason:      fmt.Sprintf("%s file changed - hash mismatch (old: %s, new: %s)", preBlock.SourceType, historyRecord.FileHash[:8], preBlock.SourceHash[:8]),
+		Reason:      fmt.Sprintf("%s file changed - hash mismatch (old: %s, new: %s)", preBlock.SourceType, shortHash(latestRecord.FileHash), shortHash(preBlock.SourceHash)),
 		SourceBlock: nil, // Will be populated during import
 	}, nil
 }
 
+// planAppend checks whether a changed conversation log only grew since its last
+// completed import. If the previously imported bytes are an unchanged prefix of the
+// file, the milestones after them are chunked as additional blocks of the same
+// session and returned with "append" decisions; ok is false when the regular
+// per-block deduplication should decide instead.
+//
+// Exchanges are matched by count, so text added to the last milestone that was
+// already imported is not picked up - only milestones after it are.
+func planAppend(ctx context.Context, kg importHistoryStore, doc *ParsedDocument) (blocks []PreBlock, decisions []ImportDecision, ok bool, err error) {
+	source := doc.Source
+
+	historyRecord, err := kg.QueryImportHistory(ctx, source.FilePath, source.FileHash)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, nil, false, fmt.Errorf("failed to query import history: %w", err)
+	}
+	if err == nil && historyRecord != nil && historyRecord.Status == "completed" {
+		return nil, nil, false, nil // unchanged
+	}
+
+	latestRecord, err := kg.QueryLatestImport(ctx, source.FilePath)
+	if err == sql.ErrNoRows || latestRecord == nil {
+		return nil, nil, false, nil // never imported
+	}
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to query import history: %w", err)
+	}
+
+	// Imports recorded before file sizes were tracked cannot be compared
+	if latestRecord.FileSize <= 0 || source.FileSize <= latestRecord.FileSize {
+		return nil, nil, false, nil
+	}
+
+	extended, err := hasPrefixHash(source.FilePath, latestRecord.FileSize, latestRecord.FileHash)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to hash previously imported content: %w", err)
+	}
+	if !extended {
+		return nil, nil, false, nil
+	}
+
+	existingBlocks, err := kg.QueryBlocksBySource(ctx, source.FilePath)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to query existing blocks: %w", err)
+	}
+	previousExchanges := 0
+	for _, b := range existingBlocks {
+		previousExchanges += b.ExchangeCount
+	}
+
+	blocks = chunkAppendedExchanges(doc, DefaultChunkOptions(), previousExchanges, len(existingBlocks))
+	for i := range blocks {
+		decisions = append(decisions, ImportDecision{
+			Action:   "append",
+			PreBlock: &blocks[i],
+			Reason: fmt.Sprintf("%s grew from %d to %d bytes - %d new exchanges after the %d already imported",
+				source.FileType, latestRecord.FileSize, source.FileSize, len(blocks[i].Exchanges), previousExchanges),
+		})
+	}
+	return blocks, decisions, true, nil
+}
+
+// hasPrefixHash reports whether the first size bytes of the file hash to fileHash
+func hasPrefixHash(path string, size int64, fileHash string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.CopyN(h, f, size); err != nil {
+		if err == io.EOF {
+			return false, nil // shrank since it was stat'ed
+		}
+		return false, err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)) == fileHash, nil
+}
+
+// shortHash abbreviates a file hash for log and report messages
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
+
 // isImmutableSourceType returns true for source types that should never be updated
 func isImmutableSourceType(sourceType string) bool {
 	switch sourceType {
 	case "conversation-log":
 		return true
-	case "spec", "doc", "working-file":
+	case "spec", "doc", "working-file", "chatgpt-export", "claude-transcript", "git-history":
 		return false
 	default:
 		// Default to immutable for safety
@@ -139,6 +236,7 @@ func SummarizeDecisions(decisions []ImportDecision) map[string]int {
 	summary := map[string]int{
 		"insert": 0,
 		"update": 0,
+		"append": 0,
 		"skip":   0,
 	}
 	for _, d := range decisions {
@@ -0,0 +1,189 @@
+package importer
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/db"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHistoryStore is an in-memory importHistoryStore keyed by source file
+type fakeHistoryStore struct {
+	history map[string][]db.ImportHistoryRecord
+	blocks  map[string][]db.BlockSourceRecord
+}
+
+func (f *fakeHistoryStore) QueryImportHistory(ctx context.Context, sourceFile, fileHash string) (*db.ImportHistoryRecord, error) {
+	for _, record := range f.history[sourceFile] {
+		if record.FileHash == fileHash {
+			r := record
+			return &r, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeHistoryStore) QueryLatestImport(ctx context.Context, sourceFile string) (*db.ImportHistoryRecord, error) {
+	records := f.history[sourceFile]
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Status == "completed" {
+			r := records[i]
+			return &r, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeHistoryStore) QueryBlocksBySource(ctx context.Context, sourceFile string) ([]db.BlockSourceRecord, error) {
+	return f.blocks[sourceFile], nil
+}
+
+// TestDeduplicateBlock_Decisions covers insert, skip and update for changed and unchanged files
+func TestDeduplicateBlock_Decisions(t *testing.T) {
+	existingID := uuid.New()
+	store := &fakeHistoryStore{
+		history: map[string][]db.ImportHistoryRecord{
+			"/docs/spec.md":    {{SourceFile: "/docs/spec.md", FileHash: "oldhash1", Status: "completed"}},
+			"/logs/session.md": {{SourceFile: "/logs/session.md", FileHash: "oldhash2", Status: "completed"}},
+			"/docs/failed.md":  {{SourceFile: "/docs/failed.md", FileHash: "samehash", Status: "failed"}},
+		},
+		blocks: map[string][]db.BlockSourceRecord{
+			"/docs/spec.md": {{BlockID: existingID, SourceFile: "/docs/spec.md", SourceHash: "oldhash1"}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		preBlock   PreBlock
+		wantAction string
+	}{
+		{"new file", PreBlock{SourceFile: "/docs/new.md", SourceType: "doc", SourceHash: "abc"}, "insert"},
+		{"unchanged spec", PreBlock{SourceFile: "/docs/spec.md", SourceType: "spec", SourceHash: "oldhash1"}, "skip"},
+		{"changed spec", PreBlock{SourceFile: "/docs/spec.md", SourceType: "spec", SourceHash: "newhash1"}, "update"},
+		{"changed conversation log", PreBlock{SourceFile: "/logs/session.md", SourceType: "conversation-log", SourceHash: "newhash2"}, "skip"},
+		{"previous import failed", PreBlock{SourceFile: "/docs/failed.md", SourceType: "doc", SourceHash: "samehash"}, "insert"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preBlock := tt.preBlock
+			decision, err := deduplicateBlock(context.Background(), store, &preBlock)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAction, decision.Action, decision.Reason)
+		})
+	}
+}
+
+// TestDeduplicateBlock_UpdateReferencesExistingBlock ensures updates point at the stale block
+func TestDeduplicateBlock_UpdateReferencesExistingBlock(t *testing.T) {
+	existingID := uuid.New()
+	store := &fakeHistoryStore{
+		history: map[string][]db.ImportHistoryRecord{
+			"/docs/spec.md": {{SourceFile: "/docs/spec.md", FileHash: "oldhash1", Status: "completed"}},
+		},
+		blocks: map[string][]db.BlockSourceRecord{
+			"/docs/spec.md": {{BlockID: existingID, SourceFile: "/docs/spec.md"}},
+		},
+	}
+
+	preBlock := PreBlock{SourceFile: "/docs/spec.md", SourceType: "spec", SourceHash: "newhash1"}
+	decision, err := deduplicateBlock(context.Background(), store, &preBlock)
+
+	require.NoError(t, err)
+	require.NotNil(t, decision.ExistingID)
+	assert.Equal(t, existingID, *decision.ExistingID)
+	assert.Contains(t, decision.Reason, "oldhash1")
+}
+
+// writeGrownLog writes a log file whose first imported bytes hash to the returned value
+func writeGrownLog(t *testing.T, imported, appended string) (path, importedHash string) {
+	path = filepath.Join(t.TempDir(), "session-2025-10-15-1600.md")
+	require.NoError(t, os.WriteFile(path, []byte(imported+appended), 0644))
+	return path, fmt.Sprintf("%x", sha256.Sum256([]byte(imported)))
+}
+
+// milestoneDoc builds a parsed conversation log with n milestones
+func milestoneDoc(source ImportSource, n int) *ParsedDocument {
+	sections := []Section{{Level: 1, Title: "Session Log: 2025-10-15 - Append Test"}}
+	for i := 1; i <= n; i++ {
+		sections = append(sections, Section{
+			Level:   2,
+			Title:   fmt.Sprintf("16:%02d - Milestone %d", i, i),
+			Content: fmt.Sprintf("Work done in milestone %d", i),
+		})
+	}
+	return &ParsedDocument{Source: source, Sections: sections, Metadata: map[string]interface{}{}}
+}
+
+// TestPlanAppend_GrownLogContinuesSession ensures only new milestones are chunked, numbered after the old chunks
+func TestPlanAppend_GrownLogContinuesSession(t *testing.T) {
+	imported, appended := "# Session Log\n\n## 16:01 - Milestone 1\n", "\n## 16:06 - Milestone 6\n"
+	path, importedHash := writeGrownLog(t, imported, appended)
+
+	store := &fakeHistoryStore{
+		history: map[string][]db.ImportHistoryRecord{
+			path: {{SourceFile: path, FileHash: importedHash, FileSize: int64(len(imported)), Status: "completed"}},
+		},
+		blocks: map[string][]db.BlockSourceRecord{
+			path: {{SourceFile: path, ExchangeCount: 2}, {SourceFile: path, ExchangeCount: 2}},
+		},
+	}
+	source := ImportSource{FilePath: path, FileType: "conversation-log", FileHash: "grownhash", FileSize: int64(len(imported + appended))}
+	doc := milestoneDoc(source, 6)
+
+	blocks, decisions, ok, err := planAppend(context.Background(), store, doc)
+
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, blocks, 1)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, "append", decisions[0].Action)
+
+	block := blocks[0]
+	require.Len(t, block.Exchanges, 2)
+	assert.Equal(t, "Milestone 5", block.Exchanges[0].Question)
+	assert.Equal(t, 3, block.Metadata["chunk_number"])
+	assert.Equal(t, 3, block.Metadata["total_chunks"])
+	assert.Equal(t, generateSessionID(path, extractSessionDate(doc)), block.Metadata["session_id"])
+	assert.Contains(t, block.Topic, "Part 3")
+}
+
+// TestPlanAppend_FallsBack ensures anything but a strict extension is left to regular deduplication
+func TestPlanAppend_FallsBack(t *testing.T) {
+	imported, appended := "# Session Log\n\n## 16:01 - Milestone 1\n", "\n## 16:06 - Milestone 6\n"
+	path, importedHash := writeGrownLog(t, imported, appended)
+	grownSize := int64(len(imported + appended))
+
+	tests := []struct {
+		name   string
+		record db.ImportHistoryRecord
+	}{
+		{"earlier content edited", db.ImportHistoryRecord{FileHash: "otherhash", FileSize: int64(len(imported)), Status: "completed"}},
+		{"size not tracked", db.ImportHistoryRecord{FileHash: importedHash, Status: "completed"}},
+		{"file shrank", db.ImportHistoryRecord{FileHash: importedHash, FileSize: grownSize + 10, Status: "completed"}},
+		{"unchanged", db.ImportHistoryRecord{FileHash: "grownhash", FileSize: grownSize, Status: "completed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := tt.record
+			record.SourceFile = path
+			store := &fakeHistoryStore{history: map[string][]db.ImportHistoryRecord{path: {record}}}
+			source := ImportSource{FilePath: path, FileType: "conversation-log", FileHash: "grownhash", FileSize: grownSize}
+
+			_, _, ok, err := planAppend(context.Background(), store, milestoneDoc(source, 6))
+
+			require.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
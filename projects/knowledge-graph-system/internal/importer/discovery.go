@@ -24,6 +24,17 @@ func Discover(opts ImportOptions) ([]ImportSource, error) {
 
 		// Skip directories
 		if info.IsDir() {
+			// Directory sources (e.g. .git) are matched before exclusions, which
+			// usually cover their contents; they are never descended into
+			source, err := matchDirectory(patterns, path, info)
+			if err != nil {
+				return err
+			}
+			if source != nil {
+				sources = append(sources, *source)
+				return filepath.SkipDir
+			}
+
 			// Check if directory should be excluded
 			relPath, _ := filepath.Rel(opts.RootDir, path)
 			if shouldExclude(relPath, opts.ExcludePattern) {
@@ -32,31 +43,12 @@ func Discover(opts ImportOptions) ([]ImportSource, error) {
 			return nil
 		}
 
-		// Check if file matches any pattern
-		relPath, _ := filepath.Rel(opts.RootDir, path)
-		if shouldExclude(relPath, opts.ExcludePattern) {
-			return nil
+		source, err := matchSource(opts, patterns, path, info)
+		if err != nil {
+			return err
 		}
-
-		for _, pattern := range patterns {
-			matched, _ := filepath.Match(pattern.Pattern, info.Name())
-			if matched {
-				// Calculate file hash
-				hash, err := calculateFileHash(path)
-				if err != nil {
-					return fmt.Errorf("failed to hash file %s: %w", path, err)
-				}
-
-				source := ImportSource{
-					FilePath:     path,
-					FileType:     pattern.Type,
-					LastModified: info.ModTime(),
-					FileHash:     hash,
-					FileSize:     info.Size(),
-				}
-				sources = append(sources, source)
-				break
-			}
+		if source != nil {
+			sources = append(sources, *source)
 		}
 
 		return nil
@@ -79,58 +71,163 @@ func Discover(opts ImportOptions) ([]ImportSource, error) {
 	return sources, nil
 }
 
+// DiscoverFile applies the same type patterns and exclusions as Discover to a
+// single path. It returns nil (and no error) when the file is not importable.
+func DiscoverFile(opts ImportOptions, path string) (*ImportSource, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return nil, nil
+	}
+
+	return matchSource(opts, getFilePatterns(opts.FileTypes), path, info)
+}
+
+// matchSource builds an ImportSource for a file matching one of patterns. When
+// several match, the most specific wins (see patternSpecificity), so
+// .working.md or README.md is not taken for any other *.md.
+func matchSource(opts ImportOptions, patterns []FilePattern, path string, info os.FileInfo) (*ImportSource, error) {
+	relPath, _ := filepath.Rel(opts.RootDir, path)
+	if shouldExclude(relPath, opts.ExcludePattern) {
+		return nil, nil
+	}
+
+	var best *FilePattern
+	for i, pattern := range patterns {
+		if pattern.Directory {
+			continue
+		}
+		matched, _ := filepath.Match(pattern.Pattern, info.Name())
+		if !matched {
+			continue
+		}
+		if best != nil && patternSpecificity(pattern.Pattern) <= patternSpecificity(best.Pattern) {
+			continue
+		}
+		// Files whose content is not in the format are skipped quietly
+		if pattern.Detect != nil && !pattern.Detect(path) {
+			continue
+		}
+		best = &patterns[i]
+	}
+	if best == nil {
+		return nil, nil
+	}
+
+	// Calculate file hash
+	hash, err := calculateFileHash(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash file %s: %w", path, err)
+	}
+
+	return &ImportSource{
+		FilePath:     path,
+		FileType:     best.Type,
+		LastModified: info.ModTime(),
+		FileHash:     hash,
+		FileSize:     info.Size(),
+	}, nil
+}
+
+// patternSpecificity ranks a glob by its literal characters: README.md and
+// session-*.md are more specific than *.md. Ties go to the pattern listed first.
+func patternSpecificity(pattern string) int {
+	n := 0
+	for _, r := range pattern {
+		if !strings.ContainsRune(`*?[]\`, r) {
+			n++
+		}
+	}
+	return n
+}
+
+// matchDirectory builds an ImportSource for a directory matching one of the
+// patterns of a directory format. A directory whose parser cannot read its
+// version (e.g. a repository without commits) is logged and skipped.
+func matchDirectory(patterns []FilePattern, path string, info os.FileInfo) (*ImportSource, error) {
+	for _, pattern := range patterns {
+		if !pattern.Directory {
+			continue
+		}
+		matched, _ := filepath.Match(pattern.Pattern, info.Name())
+		if !matched {
+			continue
+		}
+
+		format, ok := LookupFormat(pattern.Type)
+		if !ok {
+			return nil, fmt.Errorf("format %s is not registered", pattern.Type)
+		}
+		hash, err := format.Parser.(DirectoryParser).Version(path)
+		if err != nil {
+			log.Printf("[DISCOVERY] Skipping %s: %v", path, err)
+			return nil, nil
+		}
+
+		return &ImportSource{
+			FilePath:     path,
+			FileType:     pattern.Type,
+			LastModified: info.ModTime(),
+			FileHash:     hash,
+		}, nil
+	}
+
+	return nil, nil
+}
+
 // FilePattern maps file patterns to import types
 type FilePattern struct {
-	Pattern string
-	Type    string
+	Pattern   string
+	Type      string
+	Directory bool                   // Matches directory names instead of file names
+	Detect    func(path string) bool // Format's content check (nil: any match)
 }
 
-// getFilePatterns returns file patterns for requested types
+// getFilePatterns returns the patterns of the registered formats selected by the
+// requested types (a format's --types names or its file type; "all" selects every
+// format that is not opt-in). Patterns come in the order the types were requested.
 func getFilePatterns(types []string) []FilePattern {
 	patterns := []FilePattern{}
+	seen := make(map[string]bool)
 
 	for _, t := range types {
-		switch t {
-		case "logs", "conversation-logs":
-			patterns = append(patterns, FilePattern{
-				Pattern: "session-*.md",
-				Type:    "conversation-log",
-			})
-		case "specs", "specifications":
-			patterns = append(patterns, FilePattern{
-				Pattern: "*-spec.md",
-				Type:    "spec",
-			})
-			patterns = append(patterns, FilePattern{
-				Pattern: "*-specification.md",
-				Type:    "spec",
-			})
-		case "docs", "documentation":
-			patterns = append(patterns, FilePattern{
-				Pattern: "README.md",
-				Type:    "readme",
-			})
-			patterns = append(patterns, FilePattern{
-				Pattern: "MISSION.md",
-				Type:    "mission",
-			})
-			patterns = append(patterns, FilePattern{
-				Pattern: "*.md",
-				Type:    "doc",
-			})
-		case "working":
-			patterns = append(patterns, FilePattern{
-				Pattern: ".working.md",
-				Type:    "working-file",
-			})
-		case "all":
-			return getFilePatterns([]string{"logs", "specs", "docs", "working"})
+		for _, format := range Formats() {
+			if seen[format.FileType] || !formatSelected(format, t) {
+				continue
+			}
+			seen[format.FileType] = true
+			for _, pattern := range format.Patterns {
+				patterns = append(patterns, FilePattern{
+					Pattern:   pattern,
+					Type:      format.FileType,
+					Directory: isDirectoryFormat(format),
+					Detect:    format.Detect,
+				})
+			}
 		}
 	}
 
 	return patterns
 }
 
+// formatSelected reports whether a --types value selects the format
+func formatSelected(format Format, t string) bool {
+	if t == format.FileType || (t == "all" && !format.OptIn) {
+		return true
+	}
+	for _, name := range format.Types {
+		if name == t {
+			return true
+		}
+	}
+	return false
+}
+
 // shouldExclude checks if a path matches any exclude pattern
 func shouldExclude(path string, patterns []string) bool {
 	for _, pattern := range patterns {
@@ -3,6 +3,7 @@ package importer
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/TheGenXCoder/knowledge-graph/internal/db"
@@ -21,6 +22,11 @@ func ExampleImportWorkflow(ctx context.Context, kg *db.PostgresDB, sourceFile st
 	}
 	fmt.Printf("1. File Hash: %s\n", fileHash[:8])
 
+	info, err := os.Stat(sourceFile)
+	if err != nil {
+		return fmt.Errorf("failed to stat source file: %w", err)
+	}
+
 	// Step 2: Deduplicate blocks
 	fmt.Println("\n2. Deduplication Analysis:")
 	decisions, err := DeduplicateBlocks(ctx, kg, preBlocks)
@@ -46,7 +52,7 @@ func ExampleImportWorkflow(ctx context.Context, kg *db.PostgresDB, sourceFile st
 		visibility = "org-private"
 	}
 
-	batchID, err := kg.CreateImportBatch(ctx, sourceFile, fileHash,
+	batchID, err := kg.CreateImportBatch(ctx, sourceFile, fileHash, info.Size(),
 		preBlocks[0].SourceType, visibility, sourceClass, orgID)
 	if err != nil {
 		return fmt.Errorf("failed to create import batch: %w", err)
@@ -0,0 +1,239 @@
+package importer
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// GitRemotePolicy decides from its remotes whether a repository is public.
+// A remote cannot tell a private repository on a public host from a public one,
+// so a repository is only public when its owner or the repository itself is
+// listed under PublicOrgs or PublicRepos; everything else stays private.
+type GitRemotePolicy struct {
+	PublicHosts  []string `yaml:"public_hosts" json:"public_hosts"`   // Hosts that may serve public repositories, e.g. "github.com"
+	PublicOrgs   []string `yaml:"public_orgs" json:"public_orgs"`     // Owners whose repositories are all public: "org" on any public host, or "host/org"
+	PublicRepos  []string `yaml:"public_repos" json:"public_repos"`   // Public repositories: "owner/repo", or "host/owner/repo"
+	PrivateHosts []string `yaml:"private_hosts" json:"private_hosts"` // Hosts that only serve private repositories (self-hosted, enterprise)
+	PrivateOrgs  []string `yaml:"private_orgs" json:"private_orgs"`   // Owners whose repositories are private: "org" on any host, or "host/org"
+}
+
+// DefaultGitRemotePolicy lets the big public forges serve public repositories
+// but lists none, so no checkout is public until its owner or repository is listed
+var DefaultGitRemotePolicy = GitRemotePolicy{
+	PublicHosts: []string{"github.com", "gitlab.com", "bitbucket.org", "codeberg.org", "git.sr.ht"},
+}
+
+// gitRemoteClassifier applies a policy and caches the result per repository
+type gitRemoteClassifier struct {
+	policy GitRemotePolicy
+
+	mu    sync.Mutex
+	repos map[string]gitRepoVisibility // Keyed by repository root
+}
+
+type gitRepoVisibility struct {
+	public bool
+	url    string // Web URL of the public remote
+}
+
+var gitRemotes = newGitRemoteClassifier(DefaultGitRemotePolicy)
+
+func newGitRemoteClassifier(policy GitRemotePolicy) *gitRemoteClassifier {
+	return &gitRemoteClassifier{policy: policy, repos: make(map[string]gitRepoVisibility)}
+}
+
+// SetGitRemotePolicy replaces the policy used to classify git checkouts and
+// clears the per-repository cache
+func SetGitRemotePolicy(policy GitRemotePolicy) {
+	gitRemotes = newGitRemoteClassifier(policy)
+}
+
+// classify returns whether the repository enclosing path is public, with the
+// web URL of its public remote
+func (c *gitRemoteClassifier) classify(path string) (string, bool) {
+	root, gitDir := findGitRepo(path)
+	if root == "" {
+		return "", false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.repos[root]; ok {
+		return cached.url, cached.public
+	}
+
+	result := c.policy.evaluate(readGitRemotes(gitDir))
+	c.repos[root] = result
+	return result.url, result.public
+}
+
+// evaluate classifies a repository by its remote URLs. Any private remote makes
+// the repository private; otherwise it is public if a remote is on a public host
+// and its owner or repository is listed as public.
+func (p GitRemotePolicy) evaluate(remoteURLs []string) gitRepoVisibility {
+	var result gitRepoVisibility
+
+	for _, raw := range remoteURLs {
+		host, repoPath, ok := parseGitRemoteURL(raw)
+		if !ok {
+			continue
+		}
+
+		owner := strings.ToLower(strings.SplitN(repoPath, "/", 2)[0])
+		if hostListed(host, p.PrivateHosts) || pathListed(host, owner, p.PrivateOrgs) {
+			return gitRepoVisibility{}
+		}
+		if result.public || !hostListed(host, p.PublicHosts) {
+			continue
+		}
+		if pathListed(host, owner, p.PublicOrgs) || pathListed(host, strings.ToLower(repoPath), p.PublicRepos) {
+			result = gitRepoVisibility{public: true, url: "https://" + host + "/" + repoPath}
+		}
+	}
+
+	return result
+}
+
+// pathListed reports whether an owner or "owner/repo" path on host is in list,
+// either bare or prefixed with the host
+func pathListed(host, path string, list []string) bool {
+	for _, entry := range list {
+		entry = strings.ToLower(strings.Trim(entry, "/"))
+		if entry == path || entry == host+"/"+path {
+			return true
+		}
+	}
+	return false
+}
+
+// hostListed reports whether host or one of its parent domains is in hosts
+func hostListed(host string, hosts []string) bool {
+	for _, h := range hosts {
+		h = strings.ToLower(h)
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseGitRemoteURL extracts the lowercased host and "owner/repo" path from a
+// remote URL in URL form (https://, ssh://, git://) or scp form (git@host:owner/repo).
+// Local paths and file:// remotes are not hosted and return false.
+func parseGitRemoteURL(raw string) (host, repoPath string, ok bool) {
+	raw = strings.TrimSpace(raw)
+
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil || u.Scheme == "file" || u.Hostname() == "" {
+			return "", "", false
+		}
+		host, repoPath = u.Hostname(), u.Path
+	} else {
+		// scp-like syntax: [user@]host:path
+		colon := strings.Index(raw, ":")
+		if colon <= 0 || strings.Contains(raw[:colon], "/") {
+			return "", "", false
+		}
+		host = raw[:colon]
+		if at := strings.LastIndex(host, "@"); at != -1 {
+			host = host[at+1:]
+		}
+		repoPath = raw[colon+1:]
+	}
+
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	if host == "" || !strings.Contains(repoPath, "/") {
+		return "", "", false
+	}
+	return strings.ToLower(host), repoPath, true
+}
+
+// findGitRepo returns the root of the repository enclosing path and the git
+// directory holding its config, or "" if path is not inside a repository
+func findGitRepo(path string) (root, gitDir string) {
+	dir := filepath.Dir(filepath.Clean(path))
+
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			if info.IsDir() {
+				return dir, dotGit
+			}
+			// Worktrees and submodules have a .git file pointing at their git directory
+			if gitDir := readGitDirFile(dotGit); gitDir != "" {
+				return dir, gitDir
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// readGitDirFile resolves a "gitdir: <path>" file to the directory holding the
+// repository's config (a worktree's config lives in the main repository)
+func readGitDirFile(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	line := strings.TrimSpace(string(content))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return ""
+	}
+
+	gitDir := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+
+	if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+		return filepath.Clean(commonDir)
+	}
+	return gitDir
+}
+
+// readGitRemotes returns the URLs of the [remote "..."] sections of a git config
+func readGitRemotes(gitDir string) []string {
+	file, err := os.Open(filepath.Join(gitDir, "config"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var urls []string
+	inRemote := false
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			inRemote = strings.HasPrefix(line, "[remote ")
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if inRemote && found && strings.EqualFold(strings.TrimSpace(key), "url") {
+			urls = append(urls, strings.Trim(strings.TrimSpace(value), `"`))
+		}
+	}
+
+	return urls
+}
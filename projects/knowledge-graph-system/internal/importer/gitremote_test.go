@@ -0,0 +1,182 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeGitCheckout creates a fake checkout whose .git/config has the given remotes
+func writeGitCheckout(t *testing.T, root string, remotes map[string]string) {
+	t.Helper()
+	config := "[core]\n\tbare = false\n"
+	for name, url := range remotes {
+		config += "[remote \"" + name + "\"]\n\turl = " + url + "\n\tfetch = +refs/heads/*:refs/remotes/" + name + "/*\n"
+	}
+	config += "[branch \"main\"]\n\tremote = origin\n"
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "config"), []byte(config), 0644))
+}
+
+func TestParseGitRemoteURL(t *testing.T) {
+	tests := []struct {
+		raw, host, path string
+		ok              bool
+	}{
+		{"https://github.com/golang/go.git", "github.com", "golang/go", true},
+		{"git@github.com:golang/go.git", "github.com", "golang/go", true},
+		{"ssh://git@GitLab.com:2222/group/sub/project", "gitlab.com", "group/sub/project", true},
+		{"git://git.sr.ht/~user/repo", "git.sr.ht", "~user/repo", true},
+		{"/srv/git/repo.git", "", "", false},
+		{"file:///srv/git/repo.git", "", "", false},
+		{"../other", "", "", false},
+	}
+
+	for _, tt := range tests {
+		host, path, ok := parseGitRemoteURL(tt.raw)
+		assert.Equal(t, tt.ok, ok, tt.raw)
+		assert.Equal(t, tt.host, host, tt.raw)
+		assert.Equal(t, tt.path, path, tt.raw)
+	}
+}
+
+func TestGitRemotePolicy_Evaluate(t *testing.T) {
+	policy := GitRemotePolicy{
+		PublicHosts:  []string{"github.com", "gitlab.com"},
+		PublicOrgs:   []string{"kubernetes", "github.com/secret", "gitlab.com/group", "x", "acme"},
+		PublicRepos:  []string{"golang/go", "bitbucket.org/x/y"},
+		PrivateHosts: []string{"git.acme.internal"},
+		PrivateOrgs:  []string{"acme", "gitlab.com/secret"},
+	}
+
+	public := policy.evaluate([]string{"/local/mirror", "git@github.com:kubernetes/kubernetes.git"})
+	assert.True(t, public.public)
+	assert.Equal(t, "https://github.com/kubernetes/kubernetes", public.url)
+
+	assert.False(t, policy.evaluate([]string{"https://github.com/Acme/tools.git"}).public, "private org on any host")
+	assert.False(t, policy.evaluate([]string{"https://gitlab.com/secret/app"}).public, "private org on one host")
+	assert.True(t, policy.evaluate([]string{"https://github.com/secret/app"}).public, "host-scoped org elsewhere")
+	assert.False(t, policy.evaluate([]string{"https://github.com/x/y", "ssh://git@git.acme.internal/x/y"}).public,
+		"a private remote wins over a public one")
+	assert.False(t, policy.evaluate([]string{"https://bitbucket.org/x/y"}).public, "unlisted hosts are not public")
+	assert.False(t, policy.evaluate(nil).public, "repositories without remotes are not public")
+
+	assert.True(t, policy.evaluate([]string{"https://github.com/golang/go"}).public, "listed repository")
+	assert.True(t, policy.evaluate([]string{"https://gitlab.com/group/sub/project"}).public, "host-scoped public org")
+	assert.False(t, policy.evaluate([]string{"https://github.com/group/project"}).public, "host-scoped public org elsewhere")
+	assert.False(t, policy.evaluate([]string{"https://github.com/golang/tools"}).public, "unlisted repository of a listed one's owner")
+	assert.False(t, policy.evaluate([]string{"git@github.com:acme-corp/internal-billing.git"}).public, "unlisted owner on a public host")
+}
+
+func TestDefaultGitRemotePolicy_ListsNothingPublic(t *testing.T) {
+	for _, remote := range []string{
+		"https://github.com/spf13/cobra.git",
+		"git@github.com:acme-corp/internal-billing.git",
+		"https://gitlab.com/group/project",
+	} {
+		assert.False(t, DefaultGitRemotePolicy.evaluate([]string{remote}).public, remote)
+	}
+}
+
+func TestClassifySource_PublicGitCheckout(t *testing.T) {
+	defer SetGitRemotePolicy(DefaultGitRemotePolicy)
+	SetGitRemotePolicy(GitRemotePolicy{
+		PublicHosts: []string{"github.com"},
+		PublicRepos: []string{"spf13/cobra"},
+		PrivateOrgs: []string{"acme"},
+	})
+
+	dir := t.TempDir()
+	oss := filepath.Join(dir, "home", "me", "src", "cobra")
+	writeGitCheckout(t, oss, map[string]string{"origin": "https://github.com/spf13/cobra.git"})
+	private := filepath.Join(dir, "home", "me", "src", "tools")
+	writeGitCheckout(t, private, map[string]string{"origin": "git@github.com:acme/tools.git"})
+
+	doc := filepath.Join(oss, "site", "content", "README.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(doc), 0755))
+	require.NoError(t, os.WriteFile(doc, []byte("# Cobra\n\nA CLI library.\n"), 0644))
+
+	source := ImportSource{FilePath: doc}
+	require.NoError(t, ClassifySource(&source, "auto", nil))
+	assert.Equal(t, "public", source.Visibility, "listed public checkouts win over the personal /home/ pattern")
+	assert.Equal(t, "public-repo", source.SourceClass)
+	assert.Equal(t, "https://github.com/spf13/cobra", source.Metadata["repository_url"])
+
+	block := PreBlock{}
+	AddAttributionToPreBlock(&block, &source)
+	assert.Equal(t, "https://github.com/spf13/cobra", block.SourceURL)
+	assert.Equal(t, "Source: GitHub - https://github.com/spf13/cobra", block.SourceAttribution)
+
+	privateDoc := filepath.Join(private, "NOTES.md")
+	require.NoError(t, os.WriteFile(privateDoc, []byte("# Notes\n"), 0644))
+	source = ImportSource{FilePath: privateDoc}
+	require.NoError(t, ClassifySource(&source, "auto", nil))
+	assert.Equal(t, "individual", source.Visibility)
+
+	// Git history sources are the .git directory itself
+	source = ImportSource{FilePath: filepath.Join(oss, ".git"), FileType: "git-history"}
+	require.NoError(t, ClassifySource(&source, "auto", nil))
+	assert.Equal(t, "public", source.Visibility)
+}
+
+func TestClassifySource_UnlistedGitHubOrgStaysPrivate(t *testing.T) {
+	defer SetGitRemotePolicy(DefaultGitRemotePolicy)
+	SetGitRemotePolicy(DefaultGitRemotePolicy)
+
+	dir := t.TempDir()
+	for path, visibility := range map[string]string{
+		filepath.Join(dir, "home", "alice", "src", "internal-billing"): "individual",
+		filepath.Join(dir, "srv", "src", "internal-billing"):           "org-private",
+	} {
+		writeGitCheckout(t, path, map[string]string{"origin": "git@github.com:acme-corp/internal-billing.git"})
+		doc := filepath.Join(path, "README.md")
+		require.NoError(t, os.WriteFile(doc, []byte("# Billing\n\nInvoices and payouts.\n"), 0644))
+
+		source := ImportSource{FilePath: doc}
+		require.NoError(t, ClassifySource(&source, "auto", nil))
+		assert.Equal(t, visibility, source.Visibility, path)
+		assert.NotEqual(t, "public-repo", source.SourceClass, path)
+		assert.Empty(t, source.Metadata["repository_url"], path)
+	}
+}
+
+func TestGitRemoteClassifier_CachesPerRepository(t *testing.T) {
+	root := t.TempDir()
+	writeGitCheckout(t, root, map[string]string{"origin": "https://github.com/spf13/cobra.git"})
+
+	classifier := newGitRemoteClassifier(GitRemotePolicy{PublicHosts: []string{"github.com"}, PublicOrgs: []string{"spf13"}})
+	url, ok := classifier.classify(filepath.Join(root, "a", "b.md"))
+	require.True(t, ok)
+	assert.Equal(t, "https://github.com/spf13/cobra", url)
+
+	// Later config changes are not re-read for the same repository
+	writeGitCheckout(t, root, map[string]string{"origin": "git@git.internal:x/y.git"})
+	_, ok = classifier.classify(filepath.Join(root, "c.md"))
+	assert.True(t, ok)
+	assert.Len(t, classifier.repos, 1)
+}
+
+func TestFindGitRepo_Worktree(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main")
+	writeGitCheckout(t, main, map[string]string{"origin": "https://gitlab.com/group/project.git"})
+
+	worktreeGitDir := filepath.Join(main, ".git", "worktrees", "feature")
+	require.NoError(t, os.MkdirAll(worktreeGitDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(worktreeGitDir, "commondir"), []byte("../..\n"), 0644))
+
+	worktree := filepath.Join(dir, "feature")
+	require.NoError(t, os.MkdirAll(worktree, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: "+worktreeGitDir+"\n"), 0644))
+
+	root, gitDir := findGitRepo(filepath.Join(worktree, "docs", "x.md"))
+	assert.Equal(t, worktree, root)
+	assert.Equal(t, filepath.Join(main, ".git"), gitDir)
+	assert.Equal(t, []string{"https://gitlab.com/group/project.git"}, readGitRemotes(gitDir))
+
+	root, _ = findGitRepo(filepath.Join(dir, "elsewhere", "x.md"))
+	assert.Empty(t, root)
+}
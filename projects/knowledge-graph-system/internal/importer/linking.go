@@ -0,0 +1,273 @@
+package importer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/db"
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+)
+
+// relationshipStore is the subset of PostgresDB the commit linker needs
+type relationshipStore interface {
+	QueryBlockFiles(ctx context.Context, query db.BlockFilesQuery) ([]db.BlockFilesRecord, error)
+	SaveRelationships(ctx context.Context, relationships []types.Relationship) error
+}
+
+// relationshipInferrer is the subset of PostgresDB relationship inference needs
+type relationshipInferrer interface {
+	QueryBlocksBySource(ctx context.Context, sourceFile string) ([]db.BlockSourceRecord, error)
+	InferRelationships(ctx context.Context, blockIDs []uuid.UUID, opts types.InferenceOptions) (int, error)
+}
+
+// inferRelationships relates the blocks of every file written by the import to
+// existing blocks. It returns the number of relationships saved.
+func inferRelationships(ctx context.Context, store relationshipInferrer, jobs []*sourceJob, opts types.InferenceOptions) (int, error) {
+	var blockIDs []uuid.UUID
+	for _, job := range jobs {
+		if job.imported == 0 {
+			continue
+		}
+		records, err := store.QueryBlocksBySource(ctx, job.source.FilePath)
+		if err != nil {
+			return 0, err
+		}
+		for _, record := range records {
+			blockIDs = append(blockIDs, record.BlockID)
+		}
+	}
+	if len(blockIDs) == 0 {
+		return 0, nil
+	}
+	return store.InferRelationships(ctx, blockIDs, opts)
+}
+
+// linkCommitsToSessions relates the git history and conversation-log blocks the
+// import wrote to blocks of the other kind: commits to the sessions of the same
+// organization whose Files Modified sections name files the commits touched.
+// Only the changed sources' repositories are searched, so an import does not
+// compare every commit with every session. It returns the number of
+// relationships saved.
+func linkCommitsToSessions(ctx context.Context, store relationshipStore, jobs []*sourceJob) (int, error) {
+	var relationships []types.Relationship
+
+	for _, job := range jobs {
+		if job.imported == 0 {
+			continue
+		}
+
+		var links []types.Relationship
+		var err error
+		switch job.source.FileType {
+		case "git-history":
+			links, err = linkChangedRepository(ctx, store, job.source)
+		case "conversation-log":
+			links, err = linkChangedSession(ctx, store, job.source)
+		default:
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		relationships = append(relationships, links...)
+	}
+
+	if len(relationships) == 0 {
+		return 0, nil
+	}
+	if err := store.SaveRelationships(ctx, relationships); err != nil {
+		return 0, err
+	}
+	return len(relationships), nil
+}
+
+// linkChangedRepository relates the commit blocks of a git history source to the
+// sessions of its organization that live in, or name files in, its repository
+func linkChangedRepository(ctx context.Context, store relationshipStore, source ImportSource) ([]types.Relationship, error) {
+	commits, err := store.QueryBlockFiles(ctx, db.BlockFilesQuery{
+		SourceType:     "git-history",
+		MetadataKey:    "files",
+		OrganizationID: source.OrganizationID,
+		SourceFiles:    []string{source.FilePath},
+	})
+	if err != nil || len(commits) == 0 {
+		return nil, err
+	}
+
+	sessions, err := store.QueryBlockFiles(ctx, db.BlockFilesQuery{
+		SourceType:     "conversation-log",
+		MetadataKey:    "files_modified",
+		OrganizationID: source.OrganizationID,
+		Mentioning:     repositoryMentions(filepath.Dir(source.FilePath)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return commitSessionLinks(commits, sessions), nil
+}
+
+// linkChangedSession relates the blocks of a conversation-log source to the
+// commits of its organization in the repositories it could refer to
+func linkChangedSession(ctx context.Context, store relationshipStore, source ImportSource) ([]types.Relationship, error) {
+	sessions, err := store.QueryBlockFiles(ctx, db.BlockFilesQuery{
+		SourceType:     "conversation-log",
+		MetadataKey:    "files_modified",
+		OrganizationID: source.OrganizationID,
+		SourceFiles:    []string{source.FilePath},
+	})
+	if err != nil || len(sessions) == 0 {
+		return nil, err
+	}
+
+	commits, err := store.QueryBlockFiles(ctx, db.BlockFilesQuery{
+		SourceType:     "git-history",
+		MetadataKey:    "files",
+		OrganizationID: source.OrganizationID,
+		SourceFiles:    candidateRepositories(sessions),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return commitSessionLinks(commits, sessions), nil
+}
+
+// repositoryMentions returns the paths a session in, or naming files in, a
+// repository contains: the repository itself and its ~/ form under the home directory
+func repositoryMentions(repository string) []string {
+	mentions := []string{repository}
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, err := filepath.Rel(home, repository); err == nil && !strings.HasPrefix(rel, "..") && rel != "." {
+			mentions = append(mentions, "~/"+filepath.ToSlash(rel))
+		}
+	}
+	return mentions
+}
+
+// candidateRepositories returns the git history sources (.git paths) that could
+// hold the commits of sessions: every directory enclosing a session log or an
+// absolute path it names. Relative paths only match the repository holding the log.
+func candidateRepositories(sessions []db.BlockFilesRecord) []string {
+	seen := make(map[string]bool)
+	var candidates []string
+	addAncestors := func(path string) {
+		for dir := filepath.Dir(filepath.Clean(path)); ; dir = filepath.Dir(dir) {
+			gitDir := filepath.Join(dir, ".git")
+			if !seen[gitDir] {
+				seen[gitDir] = true
+				candidates = append(candidates, gitDir)
+			}
+			if parent := filepath.Dir(dir); parent == dir {
+				return
+			}
+		}
+	}
+
+	home, _ := os.UserHomeDir()
+	for _, session := range sessions {
+		addAncestors(session.SourceFile)
+		for _, file := range session.Files {
+			if strings.HasPrefix(file, "~/") && home != "" {
+				file = filepath.Join(home, file[2:])
+			}
+			if filepath.IsAbs(file) {
+				addAncestors(file)
+			}
+		}
+	}
+
+	sort.Strings(candidates)
+	return candidates
+}
+
+// commitSessionLinks returns an "implements" relationship from each commit block
+// to each session block that names files the commits touched. Confidence is the
+// share of the session's files the commits account for.
+func commitSessionLinks(commits, sessions []db.BlockFilesRecord) []types.Relationship {
+	var relationships []types.Relationship
+
+	for _, commit := range commits {
+		// Git history sources are the repository's .git directory
+		repository := filepath.Dir(commit.SourceFile)
+
+		for _, session := range sessions {
+			sessionInRepo := strings.HasPrefix(session.SourceFile, repository+string(filepath.Separator))
+
+			matched := 0
+			for _, file := range session.Files {
+				sessionPath, ok := repoRelativePath(file, repository, sessionInRepo)
+				if !ok {
+					continue
+				}
+				for _, committed := range commit.Files {
+					if pathMatches(committed, sessionPath) {
+						matched++
+						break
+					}
+				}
+			}
+			if matched == 0 {
+				continue
+			}
+
+			relationships = append(relationships, types.Relationship{
+				FromBlockID:      commit.BlockID,
+				ToBlockID:        session.BlockID,
+				RelationshipType: types.RelationshipImplements,
+				Confidence:       float64(matched) / float64(len(session.Files)),
+			})
+		}
+	}
+
+	return relationships
+}
+
+// repoRelativePath turns a path from a session log into one comparable with
+// commit paths. Absolute paths must lie inside the repository; relative paths are
+// only trusted when the session log itself lives in the repository.
+func repoRelativePath(file, repository string, sessionInRepo bool) (string, bool) {
+	if strings.HasPrefix(file, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		file = filepath.Join(home, file[2:]) + trailingSlash(file)
+	}
+
+	if filepath.IsAbs(file) {
+		rel, err := filepath.Rel(repository, file)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return "", false
+		}
+		return filepath.ToSlash(rel) + trailingSlash(file), true
+	}
+
+	if !sessionInRepo {
+		return "", false
+	}
+	return strings.TrimPrefix(filepath.ToSlash(file), "./"), true
+}
+
+// pathMatches reports whether a repository-relative commit path is the file a
+// session named. Session paths may be relative to a subdirectory of the
+// repository, so they match at any path-segment boundary; a trailing slash
+// names a directory and matches everything below it.
+func pathMatches(committed, sessionPath string) bool {
+	if strings.HasSuffix(sessionPath, "/") {
+		return strings.HasPrefix(committed, sessionPath) || strings.Contains(committed, "/"+sessionPath)
+	}
+	return committed == sessionPath || strings.HasSuffix(committed, "/"+sessionPath)
+}
+
+// trailingSlash returns "/" if path names a directory by ending in one
+func trailingSlash(path string) string {
+	if strings.HasSuffix(path, "/") {
+		return "/"
+	}
+	return ""
+}
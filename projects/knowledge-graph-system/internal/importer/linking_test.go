@@ -0,0 +1,157 @@
+package importer
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/db"
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitSessionLinks(t *testing.T) {
+	commits := []db.BlockFilesRecord{{
+		BlockID:    uuid.New(),
+		SourceFile: "/src/repo/.git",
+		Files:      []string{"projects/kg/internal/importer/chunking.go", "projects/kg/docs/IMPORT.md", "go.mod"},
+	}}
+
+	inRepo := db.BlockFilesRecord{
+		BlockID:    uuid.New(),
+		SourceFile: "/src/repo/projects/kg/conversation-logs/session-1.md",
+		Files:      []string{"internal/importer/chunking.go", "docs/", "cmd/kg/main.go", "./go.mod"},
+	}
+	absolute := db.BlockFilesRecord{
+		BlockID:    uuid.New(),
+		SourceFile: "/logs/session-2.md",
+		Files:      []string{"/src/repo/go.mod", "/elsewhere/go.mod"},
+	}
+	otherRepo := db.BlockFilesRecord{
+		BlockID:    uuid.New(),
+		SourceFile: "/other/conversation-logs/session-3.md",
+		Files:      []string{"go.mod"},
+	}
+	partialName := db.BlockFilesRecord{
+		BlockID:    uuid.New(),
+		SourceFile: "/src/repo/conversation-logs/session-4.md",
+		Files:      []string{"unking.go"},
+	}
+
+	links := commitSessionLinks(commits, []db.BlockFilesRecord{inRepo, absolute, otherRepo, partialName})
+	require.Len(t, links, 2, "relative paths from other repositories and partial names do not match")
+
+	assert.Equal(t, commits[0].BlockID, links[0].FromBlockID)
+	assert.Equal(t, inRepo.BlockID, links[0].ToBlockID)
+	assert.Equal(t, "implements", links[0].RelationshipType)
+	assert.Equal(t, 0.75, links[0].Confidence, "3 of the session's 4 files were committed")
+
+	assert.Equal(t, absolute.BlockID, links[1].ToBlockID)
+	assert.Equal(t, 0.5, links[1].Confidence)
+}
+
+// fakeBlockFiles is a relationshipStore over in-memory blocks, applying
+// BlockFilesQuery the way the SQL query does
+type fakeBlockFiles struct {
+	blocks  map[string][]fakeFilesBlock // source type → blocks
+	queries []db.BlockFilesQuery
+	saved   []types.Relationship
+}
+
+type fakeFilesBlock struct {
+	db.BlockFilesRecord
+	org *uuid.UUID
+}
+
+func (f *fakeBlockFiles) QueryBlockFiles(ctx context.Context, query db.BlockFilesQuery) ([]db.BlockFilesRecord, error) {
+	f.queries = append(f.queries, query)
+
+	var records []db.BlockFilesRecord
+	for _, block := range f.blocks[query.SourceType] {
+		if (block.org == nil) != (query.OrganizationID == nil) || (block.org != nil && *block.org != *query.OrganizationID) {
+			continue
+		}
+		if len(query.SourceFiles) > 0 && !slices.Contains(query.SourceFiles, block.SourceFile) {
+			continue
+		}
+		if len(query.Mentioning) > 0 && !slices.ContainsFunc(query.Mentioning, func(path string) bool {
+			return strings.HasPrefix(block.SourceFile, path+"/") || strings.Contains(strings.Join(block.Files, " "), path)
+		}) {
+			continue
+		}
+		records = append(records, block.BlockFilesRecord)
+	}
+	return records, nil
+}
+
+func (f *fakeBlockFiles) SaveRelationships(ctx context.Context, relationships []types.Relationship) error {
+	f.saved = append(f.saved, relationships...)
+	return nil
+}
+
+// TestLinkCommitsToSessions_OnlyChangedSources ensures linking only looks at the
+// changed sources' organization and repositories
+func TestLinkCommitsToSessions_OnlyChangedSources(t *testing.T) {
+	acme, other := uuid.New(), uuid.New()
+	block := func(org *uuid.UUID, sourceFile string, files ...string) fakeFilesBlock {
+		return fakeFilesBlock{BlockFilesRecord: db.BlockFilesRecord{BlockID: uuid.New(), SourceFile: sourceFile, Files: files}, org: org}
+	}
+
+	repoCommits := block(&acme, "/src/repo/.git", "internal/importer/linking.go")
+	otherRepoCommits := block(&acme, "/src/tools/.git", "internal/importer/linking.go")
+	inRepo := block(&acme, "/src/repo/conversation-logs/session-1.md", "internal/importer/linking.go")
+	absolute := block(&acme, "/logs/session-2.md", "/src/repo/internal/importer/linking.go")
+	otherOrg := block(&other, "/src/repo/conversation-logs/session-3.md", "internal/importer/linking.go")
+
+	store := &fakeBlockFiles{blocks: map[string][]fakeFilesBlock{
+		"git-history":      {repoCommits, otherRepoCommits},
+		"conversation-log": {inRepo, absolute, otherOrg},
+	}}
+
+	t.Run("changed repository", func(t *testing.T) {
+		store.saved, store.queries = nil, nil
+		jobs := []*sourceJob{
+			{source: ImportSource{FilePath: "/src/repo/.git", FileType: "git-history", OrganizationID: &acme}, imported: 3},
+			{source: ImportSource{FilePath: "/src/repo/README.md", FileType: "readme", OrganizationID: &acme}, imported: 1},
+		}
+
+		linked, err := linkCommitsToSessions(context.Background(), store, jobs)
+		require.NoError(t, err)
+		assert.Equal(t, 2, linked)
+		for _, rel := range store.saved {
+			assert.Equal(t, repoCommits.BlockID, rel.FromBlockID)
+			assert.NotEqual(t, otherOrg.BlockID, rel.ToBlockID)
+		}
+		for _, query := range store.queries {
+			assert.Equal(t, &acme, query.OrganizationID)
+			assert.True(t, len(query.SourceFiles) > 0 || len(query.Mentioning) > 0, "queries are scoped to the repository")
+		}
+	})
+
+	t.Run("changed session", func(t *testing.T) {
+		store.saved, store.queries = nil, nil
+		jobs := []*sourceJob{
+			{source: ImportSource{FilePath: "/logs/session-2.md", FileType: "conversation-log", OrganizationID: &acme}, imported: 1},
+			{source: ImportSource{FilePath: "/src/repo/conversation-logs/session-1.md", FileType: "conversation-log", OrganizationID: &acme}},
+		}
+
+		linked, err := linkCommitsToSessions(context.Background(), store, jobs)
+		require.NoError(t, err)
+		require.Equal(t, 1, linked, "only the session written by this import is related")
+		assert.Equal(t, repoCommits.BlockID, store.saved[0].FromBlockID)
+		assert.Equal(t, absolute.BlockID, store.saved[0].ToBlockID)
+		assert.NotContains(t, store.queries[1].SourceFiles, "/src/tools/.git")
+	})
+}
+
+func TestCandidateRepositories(t *testing.T) {
+	candidates := candidateRepositories([]db.BlockFilesRecord{{
+		SourceFile: "/logs/session-2.md",
+		Files:      []string{"/src/repo/go.mod", "docs/"},
+	}})
+
+	assert.Equal(t, []string{"/.git", "/logs/.git", "/src/.git", "/src/repo/.git"}, candidates)
+}
@@ -0,0 +1,249 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/importtypes"
+)
+
+// ChatGPTExportParser parses the conversations.json file of a ChatGPT data export
+type ChatGPTExportParser struct{}
+
+// NewChatGPTExportParser creates a new ChatGPT export parser
+func NewChatGPTExportParser() *ChatGPTExportParser {
+	return &ChatGPTExportParser{}
+}
+
+// chatGPTConversation is one entry of conversations.json
+type chatGPTConversation struct {
+	ID               string                 `json:"id"`
+	ConversationID   string                 `json:"conversation_id"`
+	Title            string                 `json:"title"`
+	CreateTime       *float64               `json:"create_time"`
+	UpdateTime       *float64               `json:"update_time"`
+	CurrentNode      string                 `json:"current_node"`
+	DefaultModelSlug string                 `json:"default_model_slug"`
+	Mapping          map[string]chatGPTNode `json:"mapping"`
+}
+
+// chatGPTNode is a node of a conversation's message tree. Edits and regenerations
+// branch the tree; current_node is the leaf of the branch last shown to the user.
+type chatGPTNode struct {
+	ID       string          `json:"id"`
+	Message  *chatGPTMessage `json:"message"`
+	Parent   *string         `json:"parent"`
+	Children []string        `json:"children"`
+}
+
+type chatGPTMessage struct {
+	Author struct {
+		Role string `json:"role"`
+	} `json:"author"`
+	CreateTime *float64 `json:"create_time"`
+	Content    struct {
+		ContentType string            `json:"content_type"`
+		Parts       []json.RawMessage `json:"parts"`
+		Text        string            `json:"text"`
+	} `json:"content"`
+	Recipient string                 `json:"recipient"`
+	Metadata  map[string]interface{} `json:"metadata"`
+}
+
+// Parse parses conversations.json into one Conversation per chat, following the
+// current branch of each chat and pairing user turns with the assistant replies
+// that follow them
+func (p *ChatGPTExportParser) Parse(source importtypes.ImportSource, content string) (*importtypes.ParsedDocument, error) {
+	var export []chatGPTConversation
+	if err := json.Unmarshal([]byte(content), &export); err != nil {
+		return nil, fmt.Errorf("failed to parse ChatGPT export: %w", err)
+	}
+
+	doc := &importtypes.ParsedDocument{
+		Source:   source,
+		Metadata: map[string]interface{}{"export_format": "chatgpt"},
+	}
+
+	for _, c := range export {
+		conversation := p.parseConversation(c)
+		if len(conversation.Exchanges) == 0 {
+			continue
+		}
+		doc.Conversations = append(doc.Conversations, conversation)
+	}
+
+	doc.Metadata["conversation_count"] = len(doc.Conversations)
+	return doc, nil
+}
+
+// parseConversation pairs the messages on a conversation's current branch into exchanges
+func (p *ChatGPTExportParser) parseConversation(c chatGPTConversation) importtypes.Conversation {
+	id := c.ConversationID
+	if id == "" {
+		id = c.ID
+	}
+	startedAt := unixSeconds(c.CreateTime)
+
+	conversation := importtypes.Conversation{
+		ID:        id,
+		Title:     strings.TrimSpace(c.Title),
+		StartedAt: startedAt,
+		Metadata: map[string]interface{}{
+			"conversation_id": id,
+			"title":           strings.TrimSpace(c.Title),
+		},
+	}
+	if c.DefaultModelSlug != "" {
+		conversation.Metadata["model_slug"] = c.DefaultModelSlug
+	}
+	if updated := unixSeconds(c.UpdateTime); !updated.IsZero() {
+		conversation.Metadata["updated_at"] = updated
+	}
+
+	var current *importtypes.PreExchange
+	var answer []string
+	flush := func() {
+		if current != nil && len(answer) > 0 {
+			current.Answer = strings.Join(answer, "\n\n")
+			conversation.Exchanges = append(conversation.Exchanges, *current)
+		}
+		current, answer = nil, nil
+	}
+
+	for _, msg := range p.currentBranch(c) {
+		text := messageText(msg)
+		if text == "" {
+			continue
+		}
+
+		switch msg.Author.Role {
+		case "user":
+			// A user turn that follows a reply starts a new exchange; consecutive
+			// user turns (e.g. a prompt split across messages) form one question
+			if current != nil && len(answer) > 0 {
+				flush()
+			}
+			if current == nil {
+				timestamp := unixSeconds(msg.CreateTime)
+				if timestamp.IsZero() {
+					timestamp = startedAt
+				}
+				current = &importtypes.PreExchange{
+					Question:  text,
+					Timestamp: timestamp,
+					ModelUsed: c.DefaultModelSlug,
+				}
+			} else {
+				current.Question += "\n\n" + text
+			}
+		case "assistant":
+			// Replies to tools (browsing, code interpreter) are not shown to the user
+			if current == nil || (msg.Recipient != "" && msg.Recipient != "all") {
+				continue
+			}
+			if slug, ok := msg.Metadata["model_slug"].(string); ok && slug != "" {
+				current.ModelUsed = slug
+			}
+			answer = append(answer, text)
+		}
+	}
+	flush()
+
+	return conversation
+}
+
+// currentBranch returns the messages from the root to current_node. Exports
+// without current_node fall back to the most recent child at every fork.
+func (p *ChatGPTExportParser) currentBranch(c chatGPTConversation) []chatGPTMessage {
+	var nodes []chatGPTNode
+
+	if node, ok := c.Mapping[c.CurrentNode]; ok {
+		seen := make(map[string]bool)
+		for {
+			if seen[node.ID] {
+				break // Malformed export with a cycle
+			}
+			seen[node.ID] = true
+			nodes = append(nodes, node)
+			if node.Parent == nil {
+				break
+			}
+			parent, ok := c.Mapping[*node.Parent]
+			if !ok {
+				break
+			}
+			node = parent
+		}
+		for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+			nodes[i], nodes[j] = nodes[j], nodes[i]
+		}
+	} else {
+		for _, node := range c.Mapping {
+			if node.Parent != nil {
+				if _, ok := c.Mapping[*node.Parent]; ok {
+					continue
+				}
+			}
+			seen := make(map[string]bool)
+			for !seen[node.ID] {
+				seen[node.ID] = true
+				nodes = append(nodes, node)
+				if len(node.Children) == 0 {
+					break
+				}
+				next, ok := c.Mapping[node.Children[len(node.Children)-1]]
+				if !ok {
+					break
+				}
+				node = next
+			}
+			break
+		}
+	}
+
+	messages := make([]chatGPTMessage, 0, len(nodes))
+	for _, node := range nodes {
+		if node.Message != nil {
+			messages = append(messages, *node.Message)
+		}
+	}
+	return messages
+}
+
+// messageText returns the visible text of a message, or "" for system prompts,
+// hidden context and non-text content (images, reasoning, tool output)
+func messageText(msg chatGPTMessage) string {
+	if hidden, _ := msg.Metadata["is_visually_hidden_from_conversation"].(bool); hidden {
+		return ""
+	}
+
+	switch msg.Content.ContentType {
+	case "text", "multimodal_text":
+		var parts []string
+		for _, raw := range msg.Content.Parts {
+			// Non-string parts are image and file pointers
+			var part string
+			if err := json.Unmarshal(raw, &part); err == nil && strings.TrimSpace(part) != "" {
+				parts = append(parts, strings.TrimSpace(part))
+			}
+		}
+		return strings.Join(parts, "\n\n")
+	case "code":
+		if text := strings.TrimSpace(msg.Content.Text); text != "" {
+			return "```\n" + text + "\n```"
+		}
+	}
+	return ""
+}
+
+// unixSeconds converts an export timestamp (fractional Unix seconds) to UTC time
+func unixSeconds(seconds *float64) time.Time {
+	if seconds == nil || *seconds <= 0 {
+		return time.Time{}
+	}
+	whole, frac := math.Modf(*seconds)
+	return time.Unix(int64(whole), int64(frac*1e9)).UTC()
+}
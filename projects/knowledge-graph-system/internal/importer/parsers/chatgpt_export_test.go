@@ -0,0 +1,107 @@
+package parsers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/importtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chatGPTExport has one conversation whose second prompt was edited: the
+// original branch (u2/a2) is abandoned and current_node points at a3
+const chatGPTExport = `[
+  {
+    "id": "conv-1",
+    "conversation_id": "conv-1",
+    "title": "Postgres indexing",
+    "create_time": 1700000000.5,
+    "update_time": 1700000300,
+    "current_node": "a3",
+    "default_model_slug": "gpt-4",
+    "mapping": {
+      "root": {"id": "root", "message": null, "parent": null, "children": ["sys"]},
+      "sys": {"id": "sys", "parent": "root", "children": ["u1"],
+        "message": {"author": {"role": "system"}, "content": {"content_type": "text", "parts": [""]},
+          "metadata": {"is_visually_hidden_from_conversation": true}}},
+      "u1": {"id": "u1", "parent": "sys", "children": ["t1"],
+        "message": {"author": {"role": "user"}, "create_time": 1700000010,
+          "content": {"content_type": "text", "parts": ["How do I index JSONB?"]}}},
+      "t1": {"id": "t1", "parent": "u1", "children": ["a1"],
+        "message": {"author": {"role": "assistant"}, "recipient": "python",
+          "content": {"content_type": "code", "text": "print(1)"}, "metadata": {"model_slug": "gpt-4o"}}},
+      "a1": {"id": "a1", "parent": "t1", "children": ["u2", "u3"],
+        "message": {"author": {"role": "assistant"}, "recipient": "all", "create_time": 1700000020,
+          "content": {"content_type": "text", "parts": ["Use a GIN index."]}, "metadata": {"model_slug": "gpt-4o"}}},
+      "u2": {"id": "u2", "parent": "a1", "children": ["a2"],
+        "message": {"author": {"role": "user"}, "content": {"content_type": "text", "parts": ["Abandoned question"]}}},
+      "a2": {"id": "a2", "parent": "u2", "children": [],
+        "message": {"author": {"role": "assistant"}, "content": {"content_type": "text", "parts": ["Abandoned answer"]}}},
+      "u3": {"id": "u3", "parent": "a1", "children": ["a3"],
+        "message": {"author": {"role": "user"}, "create_time": 1700000100,
+          "content": {"content_type": "multimodal_text", "parts": [{"asset_pointer": "file-service://x"}, "What about jsonb_path_ops?"]}}},
+      "a3": {"id": "a3", "parent": "u3", "children": [],
+        "message": {"author": {"role": "assistant"}, "create_time": 1700000110,
+          "content": {"content_type": "text", "parts": ["It is smaller but supports fewer operators."]}}}
+    }
+  },
+  {
+    "id": "conv-2",
+    "title": "Empty",
+    "current_node": "r",
+    "mapping": {"r": {"id": "r", "message": null, "parent": null, "children": []}}
+  }
+]`
+
+func TestChatGPTExportParser_FollowsCurrentBranch(t *testing.T) {
+	parser := NewChatGPTExportParser()
+
+	doc, err := parser.Parse(importtypes.ImportSource{FilePath: "/exports/conversations.json"}, chatGPTExport)
+	require.NoError(t, err)
+
+	require.Len(t, doc.Conversations, 1, "conversations without exchanges are dropped")
+	assert.Equal(t, 1, doc.Metadata["conversation_count"])
+
+	conv := doc.Conversations[0]
+	assert.Equal(t, "conv-1", conv.ID)
+	assert.Equal(t, "Postgres indexing", conv.Title)
+	assert.Equal(t, time.Unix(1700000000, 500000000).UTC(), conv.StartedAt)
+	assert.Equal(t, "gpt-4", conv.Metadata["model_slug"])
+
+	require.Len(t, conv.Exchanges, 2)
+
+	first := conv.Exchanges[0]
+	assert.Equal(t, "How do I index JSONB?", first.Question)
+	assert.Equal(t, "Use a GIN index.", first.Answer, "tool calls are not part of the answer")
+	assert.Equal(t, time.Unix(1700000010, 0).UTC(), first.Timestamp)
+	assert.Equal(t, "gpt-4o", first.ModelUsed)
+
+	second := conv.Exchanges[1]
+	assert.Equal(t, "What about jsonb_path_ops?", second.Question)
+	assert.Equal(t, "It is smaller but supports fewer operators.", second.Answer)
+	assert.Equal(t, "gpt-4", second.ModelUsed, "falls back to the conversation's default model")
+}
+
+func TestChatGPTExportParser_NoCurrentNode(t *testing.T) {
+	parser := NewChatGPTExportParser()
+	content := `[{"id": "c", "title": "t", "mapping": {
+	  "u": {"id": "u", "parent": null, "children": ["a1", "a2"],
+	    "message": {"author": {"role": "user"}, "content": {"content_type": "text", "parts": ["q"]}}},
+	  "a1": {"id": "a1", "parent": "u", "children": [],
+	    "message": {"author": {"role": "assistant"}, "content": {"content_type": "text", "parts": ["first try"]}}},
+	  "a2": {"id": "a2", "parent": "u", "children": [],
+	    "message": {"author": {"role": "assistant"}, "content": {"content_type": "text", "parts": ["regenerated"]}}}
+	}}]`
+
+	doc, err := parser.Parse(importtypes.ImportSource{}, content)
+	require.NoError(t, err)
+	require.Len(t, doc.Conversations, 1)
+	require.Len(t, doc.Conversations[0].Exchanges, 1)
+	assert.Equal(t, "regenerated", doc.Conversations[0].Exchanges[0].Answer)
+}
+
+func TestChatGPTExportParser_InvalidJSON(t *testing.T) {
+	_, err := NewChatGPTExportParser().Parse(importtypes.ImportSource{}, "# not json")
+	assert.Error(t, err)
+}
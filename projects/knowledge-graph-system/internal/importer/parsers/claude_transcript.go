@@ -0,0 +1,267 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/importtypes"
+)
+
+// ClaudeTranscriptParser parses Claude JSONL session transcripts (one event per line)
+type ClaudeTranscriptParser struct{}
+
+// NewClaudeTranscriptParser creates a new Claude transcript parser
+func NewClaudeTranscriptParser() *ClaudeTranscriptParser {
+	return &ClaudeTranscriptParser{}
+}
+
+// claudeEvent is one line of a transcript
+type claudeEvent struct {
+	Type        string         `json:"type"` // "user", "assistant", "summary", ...
+	SessionID   string         `json:"sessionId"`
+	Timestamp   string         `json:"timestamp"`
+	Cwd         string         `json:"cwd"`
+	GitBranch   string         `json:"gitBranch"`
+	IsSidechain bool           `json:"isSidechain"` // Subagent turns, not part of the main conversation
+	IsMeta      bool           `json:"isMeta"`      // Injected context the user did not type
+	Summary     string         `json:"summary"`
+	Message     *claudeMessage `json:"message"`
+}
+
+type claudeMessage struct {
+	Role    string          `json:"role"`
+	Model   string          `json:"model"`
+	Content json.RawMessage `json:"content"` // A string, or a list of content blocks
+}
+
+type claudeContentBlock struct {
+	Type string `json:"type"` // "text", "tool_use", "tool_result", "thinking", ...
+	Text string `json:"text"`
+	Name string `json:"name"` // Tool name for tool_use
+}
+
+// IsClaudeTranscript reports whether r starts like a Claude transcript: its
+// first event is a JSON object with a transcript event type, a session ID, a
+// message or a summary. Other JSONL files (data, logs) are not transcripts.
+// Like Parse, it puts no limit on the length of a line: events carrying large
+// tool results (file reads, images) can be many megabytes.
+func IsClaudeTranscript(r io.Reader) bool {
+	var event claudeEvent
+	if err := json.NewDecoder(r).Decode(&event); err != nil {
+		return false
+	}
+
+	switch event.Type {
+	case "user", "assistant", "summary", "system":
+		return event.SessionID != "" || event.Message != nil || event.Summary != ""
+	}
+	return false
+}
+
+// claudeSession accumulates the exchanges of one sessionId
+type claudeSession struct {
+	conversation importtypes.Conversation
+	current      *importtypes.PreExchange
+	answer       []string
+	toolCalls    map[string]int
+}
+
+// Parse parses a transcript into one Conversation per session. User prompts
+// become questions and the assistant text up to the next prompt their answer;
+// tool calls are counted per tool in the conversation metadata.
+func (p *ClaudeTranscriptParser) Parse(source importtypes.ImportSource, content string) (*importtypes.ParsedDocument, error) {
+	doc := &importtypes.ParsedDocument{
+		Source:   source,
+		Metadata: map[string]interface{}{"export_format": "claude-jsonl"},
+	}
+
+	sessions := make(map[string]*claudeSession)
+	var order []string
+	var title string
+	events, skipped := 0, 0
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var event claudeEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			// A transcript still being written can end in a partial line
+			skipped++
+			continue
+		}
+		events++
+
+		if event.Type == "summary" {
+			if title == "" {
+				title = strings.TrimSpace(event.Summary)
+			}
+			continue
+		}
+		if event.Message == nil || event.IsSidechain || event.IsMeta {
+			continue
+		}
+
+		sessionID := event.SessionID
+		if sessionID == "" {
+			// Transcripts are named after their session
+			sessionID = strings.TrimSuffix(filepath.Base(source.FilePath), filepath.Ext(source.FilePath))
+		}
+
+		session, ok := sessions[sessionID]
+		if !ok {
+			session = &claudeSession{
+				conversation: importtypes.Conversation{
+					ID:        sessionID,
+					StartedAt: parseEventTime(event.Timestamp),
+					Metadata:  make(map[string]interface{}),
+				},
+				toolCalls: make(map[string]int),
+			}
+			sessions[sessionID] = session
+			order = append(order, sessionID)
+		}
+		if event.Cwd != "" {
+			session.conversation.Metadata["cwd"] = event.Cwd
+		}
+		if event.GitBranch != "" {
+			session.conversation.Metadata["git_branch"] = event.GitBranch
+		}
+
+		p.addEvent(session, event)
+	}
+
+	if events == 0 && skipped > 0 {
+		return nil, fmt.Errorf("failed to parse Claude transcript: no valid JSON lines")
+	}
+
+	for _, id := range order {
+		session := sessions[id]
+		session.flush()
+		if len(session.conversation.Exchanges) == 0 {
+			continue
+		}
+
+		conversation := session.conversation
+		conversation.Title = title
+		if len(session.toolCalls) > 0 {
+			total := 0
+			for _, count := range session.toolCalls {
+				total += count
+			}
+			conversation.Metadata["tool_calls"] = session.toolCalls
+			conversation.Metadata["tool_call_count"] = total
+			conversation.Metadata["tools_used"] = sortedKeys(session.toolCalls)
+		}
+		doc.Conversations = append(doc.Conversations, conversation)
+	}
+
+	if skipped > 0 {
+		doc.Metadata["skipped_lines"] = skipped
+	}
+	doc.Metadata["conversation_count"] = len(doc.Conversations)
+	return doc, nil
+}
+
+// addEvent folds one user or assistant event into the session's exchanges
+func (p *ClaudeTranscriptParser) addEvent(session *claudeSession, event claudeEvent) {
+	blocks := contentBlocks(event.Message.Content)
+
+	switch event.Message.Role {
+	case "user":
+		// Tool results come back as user events but are not prompts
+		var text []string
+		for _, block := range blocks {
+			if block.Type == "text" && strings.TrimSpace(block.Text) != "" {
+				text = append(text, strings.TrimSpace(block.Text))
+			}
+		}
+		if len(text) == 0 {
+			return
+		}
+
+		if session.current != nil && len(session.answer) > 0 {
+			session.flush()
+		}
+		if session.current == nil {
+			session.current = &importtypes.PreExchange{
+				Question:  strings.Join(text, "\n\n"),
+				Timestamp: parseEventTime(event.Timestamp),
+			}
+			if session.current.Timestamp.IsZero() {
+				session.current.Timestamp = session.conversation.StartedAt
+			}
+		} else {
+			session.current.Question += "\n\n" + strings.Join(text, "\n\n")
+		}
+
+	case "assistant":
+		if session.current == nil {
+			return
+		}
+		// Locally generated messages (e.g. interruptions) report the model as "<synthetic>"
+		if event.Message.Model != "" && !strings.HasPrefix(event.Message.Model, "<") {
+			session.current.ModelUsed = event.Message.Model
+		}
+		for _, block := range blocks {
+			switch block.Type {
+			case "text":
+				if text := strings.TrimSpace(block.Text); text != "" {
+					session.answer = append(session.answer, text)
+				}
+			case "tool_use":
+				session.toolCalls[block.Name]++
+			}
+		}
+	}
+}
+
+// flush closes the open exchange once it has an answer
+func (s *claudeSession) flush() {
+	if s.current != nil && len(s.answer) > 0 {
+		s.current.Answer = strings.Join(s.answer, "\n\n")
+		s.conversation.Exchanges = append(s.conversation.Exchanges, *s.current)
+	}
+	s.current, s.answer = nil, nil
+}
+
+// contentBlocks normalizes message content, which is either a plain string or
+// a list of typed blocks
+func contentBlocks(raw json.RawMessage) []claudeContentBlock {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return []claudeContentBlock{{Type: "text", Text: text}}
+	}
+
+	var blocks []claudeContentBlock
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return nil
+	}
+	return blocks
+}
+
+// parseEventTime parses an event's RFC 3339 timestamp, returning zero time if absent
+func parseEventTime(timestamp string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
+
+// sortedKeys returns the keys of a count map in alphabetical order
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,87 @@
+package parsers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/importtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var claudeTranscript = strings.Join([]string{
+	`{"type":"summary","summary":"Add parser registry","leafUuid":"x"}`,
+	`{"type":"user","sessionId":"s-1","timestamp":"2025-11-02T10:00:00.000Z","cwd":"/repo","gitBranch":"main","message":{"role":"user","content":"Add a parser registry"}}`,
+	`{"type":"assistant","sessionId":"s-1","timestamp":"2025-11-02T10:00:05.000Z","message":{"role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"thinking","thinking":"hmm"},{"type":"text","text":"Reading the pipeline first."},{"type":"tool_use","id":"t1","name":"Read","input":{"file_path":"pipeline.go"}}]}}`,
+	`{"type":"user","sessionId":"s-1","timestamp":"2025-11-02T10:00:06.000Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"package importer"}]}}`,
+	`{"type":"assistant","sessionId":"s-1","timestamp":"2025-11-02T10:00:09.000Z","message":{"role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"tool_use","id":"t2","name":"Edit","input":{}},{"type":"tool_use","id":"t3","name":"Read","input":{}},{"type":"text","text":"Done: formats now register themselves."}]}}`,
+	`{"type":"user","sessionId":"s-1","isSidechain":true,"timestamp":"2025-11-02T10:01:00.000Z","message":{"role":"user","content":"subagent prompt"}}`,
+	`{"type":"user","sessionId":"s-1","isMeta":true,"timestamp":"2025-11-02T10:01:30.000Z","message":{"role":"user","content":"<command-caveat>"}}`,
+	`{"type":"user","sessionId":"s-1","timestamp":"2025-11-02T10:02:00.000Z","message":{"role":"user","content":[{"type":"text","text":"Now list them in the CLI"}]}}`,
+	`{"type":"assistant","sessionId":"s-1","timestamp":"2025-11-02T10:02:10.000Z","message":{"role":"assistant","model":"claude-opus-4-1","content":[{"type":"text","text":"Added kg import formats."}]}}`,
+	`{"type":"user","sessionId":"s-1","timestamp":"2025-11-02T10:03:00.000Z","message":{"role":"user","content":"Unanswered"}}`,
+	`{"type":"assistant","sessionId":"s-1","message":{"role":"assist`,
+}, "\n")
+
+func TestClaudeTranscriptParser_Parse(t *testing.T) {
+	parser := NewClaudeTranscriptParser()
+
+	doc, err := parser.Parse(importtypes.ImportSource{FilePath: "/sessions/s-1.jsonl"}, claudeTranscript)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, doc.Metadata["skipped_lines"], "a partial last line is skipped")
+	require.Len(t, doc.Conversations, 1)
+
+	conv := doc.Conversations[0]
+	assert.Equal(t, "s-1", conv.ID)
+	assert.Equal(t, "Add parser registry", conv.Title)
+	assert.Equal(t, time.Date(2025, 11, 2, 10, 0, 0, 0, time.UTC), conv.StartedAt)
+	assert.Equal(t, "/repo", conv.Metadata["cwd"])
+	assert.Equal(t, "main", conv.Metadata["git_branch"])
+	assert.Equal(t, map[string]int{"Read": 2, "Edit": 1}, conv.Metadata["tool_calls"])
+	assert.Equal(t, 3, conv.Metadata["tool_call_count"])
+	assert.Equal(t, []string{"Edit", "Read"}, conv.Metadata["tools_used"])
+
+	require.Len(t, conv.Exchanges, 2, "tool results, sidechains, meta events and unanswered prompts are not exchanges")
+
+	first := conv.Exchanges[0]
+	assert.Equal(t, "Add a parser registry", first.Question)
+	assert.Equal(t, "Reading the pipeline first.\n\nDone: formats now register themselves.", first.Answer)
+	assert.Equal(t, "claude-sonnet-4-5", first.ModelUsed)
+	assert.Equal(t, time.Date(2025, 11, 2, 10, 0, 0, 0, time.UTC), first.Timestamp)
+
+	second := conv.Exchanges[1]
+	assert.Equal(t, "Now list them in the CLI", second.Question)
+	assert.Equal(t, "claude-opus-4-1", second.ModelUsed)
+}
+
+func TestClaudeTranscriptParser_SessionIDFromFileName(t *testing.T) {
+	content := `{"type":"user","message":{"role":"user","content":"q"}}
+{"type":"assistant","message":{"role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"a"}]}}`
+
+	doc, err := NewClaudeTranscriptParser().Parse(importtypes.ImportSource{FilePath: "/sessions/abc-123.jsonl"}, content)
+	require.NoError(t, err)
+	require.Len(t, doc.Conversations, 1)
+	assert.Equal(t, "abc-123", doc.Conversations[0].ID)
+	assert.Empty(t, doc.Conversations[0].Exchanges[0].ModelUsed)
+}
+
+func TestClaudeTranscriptParser_NotJSONL(t *testing.T) {
+	_, err := NewClaudeTranscriptParser().Parse(importtypes.ImportSource{}, "# Session Log\n\nnot json")
+	assert.Error(t, err)
+}
+
+func TestIsClaudeTranscript(t *testing.T) {
+	assert.True(t, IsClaudeTranscript(strings.NewReader(claudeTranscript)))
+	assert.True(t, IsClaudeTranscript(strings.NewReader("\n"+`{"type":"user","sessionId":"s-2","message":{"role":"user","content":"hi"}}`)))
+
+	for _, other := range []string{
+		`{"request_id":"user-001","title":"Add search","body":"..."}`,
+		`{"level":"info","type":"request","msg":"GET /health"}`,
+		`not json at all`,
+		``,
+	} {
+		assert.False(t, IsClaudeTranscript(strings.NewReader(other)), other)
+	}
+}
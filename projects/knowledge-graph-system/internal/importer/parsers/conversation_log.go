@@ -50,6 +50,12 @@ func (p *ConversationLogParser) Parse(source importtypes.ImportSource, content s
 		doc.Metadata["title"] = title
 	}
 
+	// Files the session created or changed (links sessions to the commits that touched them)
+	files := p.ExtractFilesModified(content)
+	if changed := append(files["created"], files["modified"]...); len(changed) > 0 {
+		doc.Metadata["files_modified"] = changed
+	}
+
 	return doc, nil
 }
 
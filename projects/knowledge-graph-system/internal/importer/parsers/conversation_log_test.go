@@ -5,13 +5,13 @@ import (
 	"testing"
 	"time"
 
-	"github.com/TheGenXCoder/knowledge-graph/internal/importer"
+	"github.com/TheGenXCoder/knowledge-graph/internal/importtypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 // countAllSections counts sections recursively including children
-func countAllSections(sections []importer.Section) int {
+func countAllSections(sections []importtypes.Section) int {
 	count := len(sections)
 	for _, section := range sections {
 		count += countAllSections(section.Children)
@@ -96,7 +96,7 @@ User requested proactive context preservation system.
 **Context Usage:** ~48%
 `
 
-	source := importer.ImportSource{
+	source := importtypes.ImportSource{
 		FilePath: "/test/session-2025-10-15-1600.md",
 		FileType: "conversation-log",
 	}
@@ -130,12 +130,12 @@ User requested proactive context preservation system.
 	assert.Greater(t, len(doc.Sections), 0)
 
 	// Find specific sections (they are nested under H1)
-	var sessionGoalSection *importer.Section
-	var milestoneSection *importer.Section
+	var sessionGoalSection *importtypes.Section
+	var milestoneSection *importtypes.Section
 
 	// Iterate through all sections and their children
-	var findSections func(sections []importer.Section)
-	findSections = func(sections []importer.Section) {
+	var findSections func(sections []importtypes.Section)
+	findSections = func(sections []importtypes.Section) {
 		for i := range sections {
 			section := &sections[i]
 			if section.Title == "Session Goal" {
@@ -173,7 +173,7 @@ func TestConversationLogParser_ParseRealFile(t *testing.T) {
 	require.NoError(t, err)
 
 	parser := NewConversationLogParser()
-	source := importer.ImportSource{
+	source := importtypes.ImportSource{
 		FilePath: filePath,
 		FileType: "conversation-log",
 	}
@@ -345,7 +345,7 @@ func TestConversationLogParser_DateParsing(t *testing.T) {
 func TestConversationLogParser_BuildHierarchy(t *testing.T) {
 	parser := NewConversationLogParser()
 
-	flatSections := []importer.Section{
+	flatSections := []importtypes.Section{
 		{Level: 1, Title: "Title", Content: ""},
 		{Level: 2, Title: "Section 1", Content: "Content 1"},
 		{Level: 3, Title: "Subsection 1.1", Content: "Content 1.1"},
@@ -433,7 +433,7 @@ func TestConversationLogParser_HandleVariations(t *testing.T) {
 Do something.
 `
 
-	source := importer.ImportSource{
+	source := importtypes.ImportSource{
 		FilePath: "/test/minimal.md",
 		FileType: "conversation-log",
 	}
@@ -465,7 +465,7 @@ Completed.
 func TestConversationLogParser_EmptyContent(t *testing.T) {
 	parser := NewConversationLogParser()
 
-	source := importer.ImportSource{
+	source := importtypes.ImportSource{
 		FilePath: "/test/empty.md",
 		FileType: "conversation-log",
 	}
@@ -502,7 +502,7 @@ More content.
 New phase.
 `
 
-	doc, err := parser.Parse(importer.ImportSource{FilePath: "/test/nested.md"}, testContent)
+	doc, err := parser.Parse(importtypes.ImportSource{FilePath: "/test/nested.md"}, testContent)
 	require.NoError(t, err)
 	require.NotNil(t, doc)
 
@@ -510,9 +510,9 @@ New phase.
 	assert.Greater(t, len(doc.Sections), 0)
 
 	// Find Phase 1 section using helper function
-	var phase1 *importer.Section
-	var findPhase1 func(sections []importer.Section)
-	findPhase1 = func(sections []importer.Section) {
+	var phase1 *importtypes.Section
+	var findPhase1 func(sections []importtypes.Section)
+	findPhase1 = func(sections []importtypes.Section) {
 		for i := range sections {
 			section := &sections[i]
 			if section.Title == "13:00 - Phase 1" {
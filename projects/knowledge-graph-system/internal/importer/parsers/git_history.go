@@ -0,0 +1,153 @@
+package parsers
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/importtypes"
+)
+
+// GitHistoryParser reads a repository's commit history through the git CLI.
+// Its sources are .git directories rather than files.
+type GitHistoryParser struct {
+	GitPath    string // git binary (default: "git" from PATH)
+	MaxCommits int    // Most recent commits to read (0 reads the whole history)
+}
+
+// NewGitHistoryParser creates a new git history parser
+func NewGitHistoryParser() *GitHistoryParser {
+	return &GitHistoryParser{GitPath: "git"}
+}
+
+// Record and field separators for the log format; neither appears in commit messages
+const (
+	gitRecordSep = "\x1e"
+	gitFieldSep  = "\x1f"
+)
+
+// gitLogFormat prints hash, author, email, author date, subject and body per commit;
+// --name-only appends the touched files after the body
+var gitLogFormat = "--format=" + gitRecordSep + strings.Join([]string{"%H", "%an", "%ae", "%aI", "%s", "%b"}, gitFieldSep) + gitFieldSep
+
+// Version returns the commit HEAD points at, which changes whenever the history does
+func (p *GitHistoryParser) Version(gitDir string) (string, error) {
+	out, err := p.git(gitDir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Parse reads the history of the repository whose .git directory is the source;
+// the content of a directory source is always empty
+func (p *GitHistoryParser) Parse(source importtypes.ImportSource, content string) (*importtypes.ParsedDocument, error) {
+	return p.ParseDirectory(source)
+}
+
+// ParseDirectory reads the non-merge commits of the current branch, oldest first,
+// with the files each one touched
+func (p *GitHistoryParser) ParseDirectory(source importtypes.ImportSource) (*importtypes.ParsedDocument, error) {
+	args := []string{"log", "--no-merges", "--name-only", "--no-renames", gitLogFormat}
+	if p.MaxCommits > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", p.MaxCommits))
+	}
+
+	out, err := p.git(source.FilePath, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := parseGitLog(out)
+	if err != nil {
+		return nil, err
+	}
+
+	// git log lists newest first
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+
+	repository := filepath.Dir(source.FilePath)
+	doc := &importtypes.ParsedDocument{
+		Source: source,
+		Metadata: map[string]interface{}{
+			"repository":   repository,
+			"title":        filepath.Base(repository),
+			"commit_count": len(commits),
+		},
+		Commits: commits,
+	}
+	if source.FileHash != "" {
+		doc.Metadata["head"] = source.FileHash
+	}
+
+	return doc, nil
+}
+
+// parseGitLog splits the output of git log with gitLogFormat into commits
+func parseGitLog(out string) ([]importtypes.Commit, error) {
+	var commits []importtypes.Commit
+
+	for _, record := range strings.Split(out, gitRecordSep) {
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, gitFieldSep, 7)
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("unexpected git log record: %q", truncateRecord(record))
+		}
+
+		date, err := time.Parse(time.RFC3339, strings.TrimSpace(fields[3]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid date for commit %s: %w", fields[0], err)
+		}
+
+		commit := importtypes.Commit{
+			Hash:        strings.TrimSpace(fields[0]),
+			Author:      fields[1],
+			AuthorEmail: fields[2],
+			Time:        date.UTC(),
+			Subject:     strings.TrimSpace(fields[4]),
+			Body:        strings.TrimSpace(fields[5]),
+			Files:       []string{},
+		}
+		for _, line := range strings.Split(fields[6], "\n") {
+			if file := strings.TrimSpace(line); file != "" {
+				commit.Files = append(commit.Files, file)
+			}
+		}
+		commits = append(commits, commit)
+	}
+
+	return commits, nil
+}
+
+// git runs a git command against gitDir and returns its standard output
+func (p *GitHistoryParser) git(gitDir string, args ...string) (string, error) {
+	gitPath := p.GitPath
+	if gitPath == "" {
+		gitPath = "git"
+	}
+
+	cmd := exec.Command(gitPath, append([]string{"--git-dir", gitDir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// truncateRecord shortens a malformed log record for error messages
+func truncateRecord(record string) string {
+	if len(record) > 80 {
+		return record[:80]
+	}
+	return record
+}
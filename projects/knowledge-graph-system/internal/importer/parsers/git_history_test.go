@@ -0,0 +1,97 @@
+package parsers
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/importtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commitFiles writes files into a test repository and commits them at date
+func commitFiles(t *testing.T, repo, message string, date time.Time, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(repo, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	runGit(t, repo, date, "add", "-A")
+	runGit(t, repo, date, "commit", "-q", "-m", message)
+}
+
+func runGit(t *testing.T, repo string, date time.Time, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Ada", "GIT_AUTHOR_EMAIL=ada@example.com",
+		"GIT_COMMITTER_NAME=Ada", "GIT_COMMITTER_EMAIL=ada@example.com",
+		"GIT_AUTHOR_DATE="+date.Format(time.RFC3339), "GIT_COMMITTER_DATE="+date.Format(time.RFC3339),
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func TestGitHistoryParser_ParseDirectory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	runGit(t, repo, time.Now(), "init", "-q")
+
+	start := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	commitFiles(t, repo, "feat(importer): add git history\n\nReads commits with git log.", start,
+		map[string]string{"internal/importer/git.go": "package importer", "README.md": "# Repo"})
+	commitFiles(t, repo, "Fix typo", start.Add(time.Hour), map[string]string{"README.md": "# Repo!"})
+
+	parser := NewGitHistoryParser()
+	gitDir := filepath.Join(repo, ".git")
+
+	head, err := parser.Version(gitDir)
+	require.NoError(t, err)
+	assert.Len(t, head, 40)
+
+	doc, err := parser.ParseDirectory(importtypes.ImportSource{FilePath: gitDir, FileHash: head})
+	require.NoError(t, err)
+
+	assert.Equal(t, repo, doc.Metadata["repository"])
+	assert.Equal(t, head, doc.Metadata["head"])
+	assert.Equal(t, 2, doc.Metadata["commit_count"])
+	require.Len(t, doc.Commits, 2)
+
+	first := doc.Commits[0]
+	assert.Equal(t, "feat(importer): add git history", first.Subject, "commits are oldest first")
+	assert.Equal(t, "Reads commits with git log.", first.Body)
+	assert.Equal(t, "Ada", first.Author)
+	assert.Equal(t, "ada@example.com", first.AuthorEmail)
+	assert.Equal(t, start, first.Time)
+	assert.ElementsMatch(t, []string{"README.md", "internal/importer/git.go"}, first.Files)
+
+	second := doc.Commits[1]
+	assert.Equal(t, head, second.Hash)
+	assert.Empty(t, second.Body)
+	assert.Equal(t, []string{"README.md"}, second.Files)
+
+	parser.MaxCommits = 1
+	doc, err = parser.ParseDirectory(importtypes.ImportSource{FilePath: gitDir})
+	require.NoError(t, err)
+	require.Len(t, doc.Commits, 1)
+	assert.Equal(t, "Fix typo", doc.Commits[0].Subject, "MaxCommits keeps the most recent commits")
+}
+
+func TestGitHistoryParser_EmptyRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	runGit(t, repo, time.Now(), "init", "-q")
+
+	_, err := NewGitHistoryParser().Version(filepath.Join(repo, ".git"))
+	assert.Error(t, err, "a repository without commits has no version")
+}
@@ -0,0 +1,205 @@
+package importer
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// PIIFinding counts the matches of one kind of sensitive data in a source
+type PIIFinding struct {
+	Kind   string `json:"kind"`   // "email", "phone", "github-token", "private-key", ...
+	Secret bool   `json:"secret"` // A credential rather than personal data
+	Count  int    `json:"count"`
+}
+
+// detector finds one kind of sensitive data. When the pattern has a capture
+// group only the group is sensitive (e.g. the password of a connection string);
+// otherwise the whole match is.
+type detector struct {
+	kind   string
+	secret bool
+	re     *regexp.Regexp
+	allow  func(match string) bool // Optional: true for known-harmless matches
+}
+
+// piiDetectors run in order over the text the earlier ones have already
+// redacted, so a match is only counted once: a connection string's
+// "user:password@host" is not also reported as an email address.
+var piiDetectors = []detector{
+	{kind: "private-key", secret: true, re: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----(?s:.*?)-----END [A-Z ]*PRIVATE KEY-----|-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{kind: "jwt", secret: true, re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	{kind: "aws-access-key", secret: true, re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{kind: "aws-secret-key", secret: true, re: regexp.MustCompile(`(?i)aws_secret_access_key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})`)},
+	{kind: "github-token", secret: true, re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{kind: "slack-token", secret: true, re: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{kind: "api-key", secret: true, re: regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`), allow: placeholderSecret},
+	{kind: "api-key", secret: true, re: regexp.MustCompile(`(?i)\b(?:api[_-]?key|secret[_-]?key|access[_-]?token|auth[_-]?token)["']?\s*[:=]\s*["']?([A-Za-z0-9_\-]{20,})`), allow: placeholderSecret},
+	{kind: "connection-string", secret: true, re: regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^\s:/@]+:([^\s@/]+)@`)},
+	{kind: "connection-string", secret: true, re: regexp.MustCompile(`(?i)\b(?:host|server|data source)=[^\n]*?\b(?:password|pwd)=([^\s'";]+)`)},
+	{kind: "email", re: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`), allow: harmlessEmail},
+	{kind: "phone", re: regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{3}\)\s?|\b\d{3}[\s.-])\d{3}[\s.-]\d{4}\b`)},
+}
+
+// harmlessEmail reports whether an address is a placeholder or machine address
+// (documentation examples, git SSH remotes, noreply senders) rather than a person's
+func harmlessEmail(address string) bool {
+	local, domain, _ := strings.Cut(strings.ToLower(address), "@")
+	if local == "git" || local == "your" || strings.Contains(local, "noreply") || strings.Contains(local, "no-reply") {
+		return true
+	}
+	for _, d := range []string{"example.com", "example.org", "example.net", "email.com", "users.noreply.github.com"} {
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// placeholderSecret reports whether a key is a documentation placeholder such
+// as "sk-acme-xxxxx"
+func placeholderSecret(key string) bool {
+	return strings.Contains(strings.ToLower(key), "xxxx")
+}
+
+// ScanPII returns the sensitive data found in content, one finding per kind in
+// detector order
+func ScanPII(content string) []PIIFinding {
+	_, counts := RedactPII(content)
+	if len(counts) == 0 {
+		return nil
+	}
+
+	var findings []PIIFinding
+	for _, d := range piiDetectors {
+		if n := counts[d.kind]; n > 0 {
+			findings = append(findings, PIIFinding{Kind: d.kind, Secret: d.secret, Count: n})
+			delete(counts, d.kind)
+		}
+	}
+	return findings
+}
+
+// RedactPII replaces the sensitive data in text with [REDACTED:<kind>] markers,
+// returning the redacted text and the number of replacements per kind
+func RedactPII(text string) (string, map[string]int) {
+	return redactWith(piiDetectors, text)
+}
+
+// redactWith applies detectors to text in order
+func redactWith(detectors []detector, text string) (string, map[string]int) {
+	counts := make(map[string]int)
+	for _, d := range detectors {
+		text = d.redact(text, counts)
+	}
+	return text, counts
+}
+
+// redact replaces the detector's matches in text, counting them
+func (d detector) redact(text string, counts map[string]int) string {
+	matches := d.re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if len(m) > 2 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		if d.allow != nil && d.allow(text[start:end]) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString("[REDACTED:" + d.kind + "]")
+		last = end
+		counts[d.kind]++
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// scanSourcePII scans the whole of a source file. Classification only reads the
+// head of a file, but a secret can be anywhere in it. Directory sources are
+// not scanned.
+func scanSourcePII(filePath string) ([]PIIFinding, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return ScanPII(string(content)), nil
+}
+
+// applyPIIFindings marks a source holding sensitive data and keeps it out of
+// public visibility, noting the override in result
+func applyPIIFindings(source *ImportSource, findings []PIIFinding, orgID *uuid.UUID, result *ClassificationResult) {
+	if len(findings) == 0 {
+		return
+	}
+	result.PII = findings
+
+	source.ContainsPII = true
+	if source.Metadata == nil {
+		source.Metadata = make(map[string]interface{})
+	}
+	counts := make(map[string]int, len(findings))
+	for _, f := range findings {
+		counts[f.Kind] = f.Count
+	}
+	source.Metadata["pii"] = counts
+
+	if source.Visibility == "public" {
+		source.Visibility = "org-private"
+		source.OrganizationID = orgID
+		result.Explanation = strings.TrimSpace(result.Explanation + " Sensitive data found, so kept org-private instead of public.")
+	}
+}
+
+// markPII records on a block that its source holds sensitive data and, when
+// redact is set, replaces that data in the block's text before it is embedded
+// or stored
+func markPII(pb *PreBlock, source *ImportSource, redact bool) {
+	if !source.ContainsPII && !redact {
+		return
+	}
+	if pb.Metadata == nil {
+		pb.Metadata = make(map[string]interface{})
+	}
+	if source.ContainsPII {
+		pb.Metadata["contains_pii"] = true
+	}
+	if !redact {
+		return
+	}
+
+	total := make(map[string]int)
+	add := func(text string) string {
+		redacted, counts := RedactPII(text)
+		for kind, n := range counts {
+			total[kind] += n
+		}
+		return redacted
+	}
+
+	pb.Topic = add(pb.Topic)
+	for i := range pb.Exchanges {
+		pb.Exchanges[i].Question = add(pb.Exchanges[i].Question)
+		pb.Exchanges[i].Answer = add(pb.Exchanges[i].Answer)
+	}
+	if len(total) > 0 {
+		pb.Metadata["contains_pii"] = true
+		pb.Metadata["pii_redacted"] = total
+	}
+}
@@ -1,15 +1,15 @@
 package importer
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/TheGenXCoder/knowledge-graph/internal/core"
-	"github.com/TheGenXCoder/knowledge-graph/internal/importer/parsers"
+	"github.com/TheGenXCoder/knowledge-graph/internal/db"
 	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
 	"github.com/google/uuid"
 )
@@ -36,6 +36,23 @@ func RunImport(ctx context.Context, kg core.KnowledgeGraph, opts ImportOptions)
 		fmt.Printf("✓ Found %d files\n\n", len(sources))
 	}
 
+	return importSources(ctx, kg, sources, opts, report)
+}
+
+// ImportSources runs classification through import for sources that were already
+// discovered (e.g. the changed files reported by Watch)
+func ImportSources(ctx context.Context, kg core.KnowledgeGraph, sources []ImportSource, opts ImportOptions) (*ImportReport, error) {
+	report := &ImportReport{
+		StartedAt:    time.Now(),
+		SourcesFound: len(sources),
+		Errors:       []ImportError{},
+	}
+
+	return importSources(ctx, kg, sources, opts, report)
+}
+
+// importSources runs stages 2-6 of the pipeline, filling in report
+func importSources(ctx context.Context, kg core.KnowledgeGraph, sources []ImportSource, opts ImportOptions, report *ImportReport) (*ImportReport, error) {
 	if len(sources) == 0 {
 		report.CompletedAt = time.Now()
 		return report, nil
@@ -46,112 +63,120 @@ func RunImport(ctx context.Context, kg core.KnowledgeGraph, opts ImportOptions)
 		fmt.Println("Classifying sources...")
 	}
 
+	visibility := opts.Visibility
+	if visibility == "" {
+		visibility = "auto"
+	}
+
 	for i := range sources {
-		if err := ClassifySource(&sources[i], "auto", nil); err != nil {
+		if err := ClassifySource(&sources[i], visibility, nil); err != nil {
 			report.Errors = append(report.Errors, ImportError{
 				Source:  sources[i],
 				Stage:   "classification",
 				Message: "Failed to classify source",
 				Error:   err,
 			})
+			continue
 		}
-	}
 
-	if opts.ShowProgress {
-		fmt.Printf("✓ Classified %d sources\n\n", len(sources))
+		// Explicit visibility overrides auto-detection, but never publishes sensitive data
+		if visibility != "auto" && !(visibility == "public" && sources[i].ContainsPII) {
+			sources[i].Visibility = visibility
+		}
 	}
 
-	// Stage 3: Parsing
 	if opts.ShowProgress {
-		fmt.Println("Parsing documents...")
+		fmt.Printf("✓ Classified %d sources\n\n", len(sources))
 	}
 
-	var parsedDocs []ParsedDocument
-	processed := 0
-	for _, source := range sources {
-		if opts.ShowProgress && processed%10 == 0 {
-			progress := float64(processed) / float64(len(sources)) * 100
-			bar := progressBar(progress, 20)
-			fmt.Printf("\r[%s] %.0f%% (%d/%d files)", bar, progress, processed, len(sources))
+	// Resume: skip files already imported and recover interrupted batches
+	var resumable map[string]db.ImportHistoryRecord
+	if opts.Resume {
+		if opts.DryRun {
+			return nil, fmt.Errorf("resume cannot be combined with dry-run")
+		}
+		store, ok := kg.(resumeStore)
+		if !ok {
+			return nil, fmt.Errorf("knowledge graph does not support resuming imports")
 		}
 
-		doc, err := parseSource(source)
+		var err error
+		sources, resumable, err = prepareResume(ctx, store, sources, report)
 		if err != nil {
-			report.Errors = append(report.Errors, ImportError{
-				Source:  source,
-				Stage:   "parse",
-				Message: "Failed to parse source",
-				Error:   err,
-			})
-			report.Failed++
-			processed++
-			continue
+			return nil, fmt.Errorf("resume failed: %w", err)
 		}
 
-		parsedDocs = append(parsedDocs, *doc)
-		report.SourcesParsed++
-		processed++
-	}
-
-	if opts.ShowProgress {
-		bar := progressBar(100, 20)
-		fmt.Printf("\r[%s] 100%% (%d/%d files)\n", bar, len(parsedDocs), len(sources))
-		fmt.Printf("✓ Parsed %d documents\n\n", len(parsedDocs))
+		if opts.ShowProgress {
+			fmt.Printf("✓ Resuming: %d files already imported, %d interrupted batches to finish, %d rolled back\n\n",
+				report.SourcesSkipped, len(resumable), len(report.Recovered))
+		}
 	}
 
-	// Stage 4: Chunking
+	// Stages 3-6: parse → chunk + dedup → embed → write, each on its own worker pool
 	if opts.ShowProgress {
-		fmt.Println("Chunking into blocks...")
-	}
-
-	chunkOpts := DefaultChunkOptions()
-	var preBlocks []PreBlock
-
-	for _, doc := range parsedDocs {
-		blocks, err := ChunkDocument(&doc, chunkOpts)
-		if err != nil {
-			report.Errors = append(report.Errors, ImportError{
-				Source:  doc.Source,
-				Stage:   "chunk",
-				Message: "Failed to chunk document",
-				Error:   err,
-			})
-			continue
+		if opts.DryRun {
+			fmt.Println("Parsing, chunking and deduplicating...")
+		} else {
+			fmt.Printf("Importing (%d parse, %d chunk, %d embed workers)...\n",
+				max(opts.ParseWorkers, 1), max(opts.ChunkWorkers, 1), max(opts.EmbedWorkers, 1))
 		}
-		preBlocks = append(preBlocks, blocks...)
 	}
 
-	report.BlocksCreated = len(preBlocks)
-
+	jobs, err := runStages(ctx, kg, sources, resumable, opts, func(done int) {
+		if opts.ShowProgress {
+			progress := float64(done) / float64(len(sources)) * 100
+			fmt.Printf("\r[%s] %.0f%% (%d/%d files)", progressBar(progress, 20), progress, done, len(sources))
+		}
+	})
 	if opts.ShowProgress {
-		fmt.Printf("✓ Created %d blocks from %d files\n\n", len(preBlocks), len(parsedDocs))
+		fmt.Println()
 	}
-
-	// Stage 5: Deduplication
-	if opts.ShowProgress {
-		fmt.Println("Deduplication...")
+	if err != nil {
+		return nil, err
 	}
 
-	decisions, err := DeduplicateBlocks(ctx, kg, preBlocks)
-	if err != nil {
-		return nil, fmt.Errorf("deduplication failed: %w", err)
+	// Assemble the report in discovery order
+	imported := 0
+	updatedFiles := 0
+	for _, job := range jobs {
+		report.Errors = append(report.Errors, job.errors...)
+		report.Failed += job.failed
+		if job.doc != nil {
+			report.SourcesParsed++
+		}
+		report.BlocksCreated += len(job.blocks)
+		report.Decisions = append(report.Decisions, job.decisions...)
+		report.Replaced += job.replaced
+		imported += job.imported
+		if job.recovered != nil {
+			report.Recovered = append(report.Recovered, *job.recovered)
+		}
+		if job.replace && job.failed == 0 {
+			updatedFiles++
+		}
 	}
 
-	report.Decisions = decisions
-	for _, d := range decisions {
+	for _, d := range report.Decisions {
 		switch d.Action {
 		case "insert":
 			report.Inserted++
 		case "update":
 			report.Updated++
+		case "append":
+			report.Appended++
 		case "skip":
 			report.Skipped++
 		}
 	}
 
 	if opts.ShowProgress {
+		fmt.Printf("✓ Parsed %d documents\n", report.SourcesParsed)
+		fmt.Printf("✓ Created %d blocks\n", report.BlocksCreated)
 		fmt.Printf("✓ %d new blocks\n", report.Inserted)
 		fmt.Printf("✓ %d updates\n", report.Updated)
+		if report.Appended > 0 {
+			fmt.Printf("✓ %d appended to growing session logs\n", report.Appended)
+		}
 		fmt.Printf("✓ %d skipped (unchanged)\n\n", report.Skipped)
 	}
 
@@ -159,52 +184,39 @@ func RunImport(ctx context.Context, kg core.KnowledgeGraph, opts ImportOptions)
 	if opts.DryRun {
 		if opts.ShowProgress {
 			fmt.Println("=== DRY RUN - No changes made ===")
-			showPreview(decisions, opts.PreviewCount)
+			showPreview(report.Decisions, opts.PreviewCount)
 			showClassificationSummary(sources)
 		}
 		report.CompletedAt = time.Now()
 		return report, nil
 	}
 
-	// Stage 6: Import to database
-	if opts.ShowProgress {
-		fmt.Println("Importing to database...")
-	}
-
-	imported := 0
-	total := report.Inserted + report.Updated
-
-	for i, decision := range decisions {
-		if decision.Action == "skip" {
-			continue
-		}
-
-		if opts.ShowProgress && i%10 == 0 && total > 0 {
-			progress := float64(imported) / float64(total) * 100
-			bar := progressBar(progress, 20)
-			fmt.Printf("\r[%s] %.0f%% (%d/%d blocks)", bar, progress, imported, total)
+	// Relate commits to the sessions that planned them once either side changed
+	if linksChanged(jobs) {
+		if store, ok := kg.(relationshipStore); ok {
+			linked, err := linkCommitsToSessions(ctx, store, jobs)
+			if err != nil {
+				report.Errors = append(report.Errors, ImportError{
+					Stage:   "link",
+					Message: "Failed to link git history to session logs",
+					Error:   err,
+				})
+			}
+			report.Linked = linked
 		}
+	}
 
-		if err := importBlock(ctx, kg, decision); err != nil {
+	// Relate the new blocks to existing ones
+	if store, ok := kg.(relationshipInferrer); ok && !opts.Inference.Disabled {
+		inferred, err := inferRelationships(ctx, store, jobs, opts.Inference)
+		if err != nil {
 			report.Errors = append(report.Errors, ImportError{
-				Source:  ImportSource{FilePath: decision.PreBlock.SourceFile},
-				Stage:   "import",
-				Message: fmt.Sprintf("Failed to import block: %s", decision.PreBlock.Topic),
+				Stage:   "infer",
+				Message: "Failed to infer relationships for imported blocks",
 				Error:   err,
 			})
-			report.Failed++
-			continue
 		}
-
-		imported++
-	}
-
-	if opts.ShowProgress {
-		progress := 100.0
-		if total > 0 {
-			progress = float64(imported) / float64(total) * 100
-		}
-		fmt.Printf("\r[%s] %.0f%% (%d/%d blocks)\n\n", progressBar(progress, 20), progress, imported, total)
+		report.Inferred = inferred
 	}
 
 	report.CompletedAt = time.Now()
@@ -214,6 +226,20 @@ func RunImport(ctx context.Context, kg core.KnowledgeGraph, opts ImportOptions)
 		elapsed := report.CompletedAt.Sub(report.StartedAt)
 		fmt.Printf("Time: %.1fs\n", elapsed.Seconds())
 		fmt.Printf("Blocks: %d\n", imported)
+		if updatedFiles > 0 {
+			fmt.Printf("Added: %d, replaced: %d stale blocks across %d changed files\n",
+				report.Inserted, report.Replaced, updatedFiles)
+		}
+		if report.Linked > 0 {
+			fmt.Printf("Linked: %d commit/session relationships\n", report.Linked)
+		}
+		if report.Inferred > 0 {
+			fmt.Printf("Inferred: %d relationships\n", report.Inferred)
+		}
+		for _, r := range report.Recovered {
+			fmt.Printf("Recovered %s: %s (kept %d, added %d, discarded %d) - %s\n",
+				filepath.Base(r.SourceFile), r.Action, r.BlocksKept, r.BlocksAdded, r.BlocksDiscarded, r.Reason)
+		}
 		if len(report.Errors) > 0 {
 			fmt.Printf("\n⚠️  %d errors occurred (see report for details)\n", len(report.Errors))
 		}
@@ -222,20 +248,40 @@ func RunImport(ctx context.Context, kg core.KnowledgeGraph, opts ImportOptions)
 	return report, nil
 }
 
+// linksChanged reports whether blocks were written for a source type the
+// commit/session linker reads
+func linksChanged(jobs []*sourceJob) bool {
+	for _, job := range jobs {
+		if job.imported > 0 && (job.source.FileType == "git-history" || job.source.FileType == "conversation-log") {
+			return true
+		}
+	}
+	return false
+}
+
 // parseSource parses a file into a structured document
 func parseSource(source ImportSource) (*ParsedDocument, error) {
+	format, ok := LookupFormat(source.FileType)
+	if ok {
+		if dirParser, isDir := format.Parser.(DirectoryParser); isDir {
+			return dirParser.ParseDirectory(source)
+		}
+	}
+
 	content, err := readFileForParsing(source.FilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	// Use specialized parser for conversation logs
-	if source.FileType == "conversation-log" {
-		parser := parsers.NewConversationLogParser()
-		return parser.Parse(source, content)
+	// Use the parser registered for the file type, falling back to generic markdown
+	if ok {
+		return format.Parser.Parse(source, content)
 	}
+	return parseMarkdown(source, content)
+}
 
-	// Fallback to generic markdown parser
+// parseMarkdown parses a file into sections by its markdown headings
+func parseMarkdown(source ImportSource, content string) (*ParsedDocument, error) {
 	doc := &ParsedDocument{
 		Source:   source,
 		Metadata: make(map[string]interface{}),
@@ -343,67 +389,107 @@ func extractFrontmatter(content string) map[string]interface{} {
 	return metadata
 }
 
-// importBlock saves a PreBlock to the database
-func importBlock(ctx context.Context, kg core.KnowledgeGraph, decision ImportDecision) error {
-	pb := decision.PreBlock
+// sourceImporter is the subset of PostgresDB the import stage writes through
+type sourceImporter interface {
+	CreateImportBatch(ctx context.Context, sourceFile, fileHash string, fileSize int64, importType, visibility, sourceClass string, orgID *uuid.UUID) (uuid.UUID, error)
+	ImportBlock(ctx context.Context, preBlock interface{}, batchID uuid.UUID) (*types.Block, error)
+	CompleteImportBatch(ctx context.Context, batchID uuid.UUID) error
+	FailImportBatch(ctx context.Context, batchID uuid.UUID, errorMessage string) error
+	ReplaceSourceBlocks(ctx context.Context, sourceFile string, preBlocks []interface{}, batchID uuid.UUID) (*db.ReplaceResult, error)
+}
 
-	// Get or create project
-	project, err := kg.GetOrCreateProject(ctx, extractProjectName(pb.ProjectPath), pb.ProjectPath)
+// importSource writes the PreBlocks of a new file as one import batch.
+// The batch is completed only if every block was stored; otherwise it is marked
+// failed so the next run retries the file (discarding this attempt's blocks).
+func importSource(ctx context.Context, store sourceImporter, source ImportSource, preBlocks []PreBlock) (int, []ImportError) {
+	first := preBlocks[0]
+	batchID, err := store.CreateImportBatch(ctx, first.SourceFile, first.SourceHash, source.FileSize, first.SourceType,
+		first.Visibility, source.SourceClass, first.OrganizationID)
 	if err != nil {
-		return fmt.Errorf("failed to get/create project: %w", err)
-	}
-
-	// Create Block
-	block := &types.Block{
-		ID:            uuid.New(),
-		ProjectID:     project.ID,
-		Topic:         pb.Topic,
-		StartedAt:     pb.StartedAt,
-		CompletedAt:   pb.CompletedAt,
-		ExchangeCount: len(pb.Exchanges),
-		Metadata: map[string]interface{}{
-			"source_file": pb.SourceFile,
-			"source_type": pb.SourceType,
-			"source_hash": pb.SourceHash,
-			"tags":        pb.Tags,
-		},
-	}
-
-	// Add visibility metadata
-	if pb.Visibility != "" {
-		block.Metadata["visibility"] = pb.Visibility
-	}
-	if pb.OrganizationID != nil {
-		block.Metadata["organization_id"] = pb.OrganizationID.String()
-	}
-	if pb.SourceURL != "" {
-		block.Metadata["source_url"] = pb.SourceURL
-		block.Metadata["source_attribution"] = pb.SourceAttribution
-	}
-
-	// Save block (this will generate embedding)
-	if err := kg.SaveBlock(ctx, block); err != nil {
-		return fmt.Errorf("failed to save block: %w", err)
-	}
-
-	// Save exchanges
-	for i, ex := range pb.Exchanges {
-		exchange := &types.Exchange{
-			ID:        uuid.New(),
-			BlockID:   block.ID,
-			Sequence:  i + 1,
-			Question:  ex.Question,
-			Answer:    ex.Answer,
-			Timestamp: ex.Timestamp,
-			ModelUsed: ex.ModelUsed,
+		return 0, []ImportError{{
+			Source:  source,
+			Stage:   "import",
+			Message: "Failed to create import batch",
+			Error:   err,
+		}}
+	}
+
+	return importBatchBlocks(ctx, store, source, preBlocks, batchID)
+}
+
+// importBatchBlocks stores preBlocks under an existing batch, then completes the
+// batch or marks it failed if any block could not be stored
+func importBatchBlocks(ctx context.Context, store sourceImporter, source ImportSource, preBlocks []PreBlock, batchID uuid.UUID) (int, []ImportError) {
+	var errs []ImportError
+	imported := 0
+	for i := range preBlocks {
+		if _, err := store.ImportBlock(ctx, &preBlocks[i], batchID); err != nil {
+			errs = append(errs, ImportError{
+				Source:  source,
+				Stage:   "import",
+				Message: fmt.Sprintf("Failed to import block: %s", preBlocks[i].Topic),
+				Error:   err,
+			})
+			continue
+		}
+		imported++
+	}
+
+	if len(errs) > 0 {
+		message := fmt.Sprintf("%d of %d blocks failed: %v", len(errs), len(preBlocks), errs[0].Error)
+		if err := store.FailImportBatch(ctx, batchID, message); err != nil {
+			errs = append(errs, ImportError{Source: source, Stage: "import", Message: "Failed to mark import batch failed", Error: err})
 		}
+		return imported, errs
+	}
+
+	if err := store.CompleteImportBatch(ctx, batchID); err != nil {
+		return imported, []ImportError{{
+			Source:  source,
+			Stage:   "import",
+			Message: "Failed to complete import batch",
+			Error:   err,
+		}}
+	}
+
+	return imported, nil
+}
+
+// replaceSource swaps all stale blocks of a changed file for its new PreBlocks
+// (none when the file no longer yields any) in one transaction and records the
+// new file hash in import_history
+func replaceSource(ctx context.Context, store sourceImporter, source ImportSource, preBlocks []PreBlock) (*db.ReplaceResult, error) {
+	first := PreBlock{
+		SourceFile:     source.FilePath,
+		SourceType:     source.FileType,
+		SourceHash:     source.FileHash,
+		Visibility:     source.Visibility,
+		OrganizationID: source.OrganizationID,
+	}
+	if len(preBlocks) > 0 {
+		first = preBlocks[0]
+	}
+
+	batchID, err := store.CreateImportBatch(ctx, first.SourceFile, first.SourceHash, source.FileSize, first.SourceType,
+		first.Visibility, source.SourceClass, first.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]interface{}, len(preBlocks))
+	for i := range preBlocks {
+		items[i] = &preBlocks[i]
+	}
 
-		if err := kg.SaveExchange(ctx, exchange); err != nil {
-			return fmt.Errorf("failed to save exchange %d: %w", i+1, err)
+	result, err := store.ReplaceSourceBlocks(ctx, first.SourceFile, items, batchID)
+	if err != nil {
+		if failErr := store.FailImportBatch(ctx, batchID, err.Error()); failErr != nil {
+			return nil, fmt.Errorf("%w (also failed to mark batch failed: %v)", err, failErr)
 		}
+		return nil, err
 	}
 
-	return nil
+	return result, nil
 }
 
 // extractProjectName extracts a project name from a path
@@ -437,7 +523,7 @@ func showPreview(decisions []ImportDecision, count int) {
 			break
 		}
 
-		if d.Action == "insert" || d.Action == "update" {
+		if d.Action == "insert" || d.Action == "update" || d.Action == "append" {
 			pb := d.PreBlock
 			fmt.Printf("Topic: %s\n", pb.Topic)
 			fmt.Printf("Source: %s\n", pb.SourceFile)
@@ -477,29 +563,18 @@ func truncate(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
-// readFileForParsing reads file content for parsing
+// readFileForParsing reads file content for parsing, with line endings
+// normalized to \n. Lines may be of any length: chat exports are often a
+// single minified JSON line of many megabytes.
 func readFileForParsing(filePath string) (string, error) {
-	file, err := os.Open(filePath)
+	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return "", err
 	}
-	defer file.Close()
-
-	var content strings.Builder
-	scanner := bufio.NewScanner(file)
 
-	// Increase buffer size for large files
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024) // 1MB max line
-
-	for scanner.Scan() {
-		content.WriteString(scanner.Text())
-		content.WriteString("\n")
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
 	}
-
-	if err := scanner.Err(); err != nil {
-		return "", err
-	}
-
-	return content.String(), nil
+	return content, nil
 }
@@ -0,0 +1,78 @@
+package importer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseSource_SingleLineChatGPTExport ensures a minified export longer
+// than any line buffer is parsed
+func TestParseSource_SingleLineChatGPTExport(t *testing.T) {
+	answer := strings.Repeat("Use a GIN index. ", 128*1024) + "Done." // ~2MB
+	export, err := json.Marshal([]map[string]interface{}{{
+		"id":           "conv-1",
+		"title":        "Postgres indexing",
+		"create_time":  1700000000,
+		"current_node": "a1",
+		"mapping": map[string]interface{}{
+			"u1": map[string]interface{}{"id": "u1", "children": []string{"a1"}, "message": map[string]interface{}{
+				"author":  map[string]string{"role": "user"},
+				"content": map[string]interface{}{"content_type": "text", "parts": []string{"How do I index JSONB?"}},
+			}},
+			"a1": map[string]interface{}{"id": "a1", "parent": "u1", "message": map[string]interface{}{
+				"author":  map[string]string{"role": "assistant"},
+				"content": map[string]interface{}{"content_type": "text", "parts": []string{answer}},
+			}},
+		},
+	}})
+	require.NoError(t, err)
+	require.Greater(t, len(export), 1024*1024)
+	require.NotContains(t, string(export), "\n")
+
+	path := filepath.Join(t.TempDir(), "conversations.json")
+	require.NoError(t, os.WriteFile(path, export, 0644))
+
+	doc, err := parseSource(ImportSource{FilePath: path, FileType: "chatgpt-export"})
+	require.NoError(t, err)
+	require.Len(t, doc.Conversations, 1)
+	require.Len(t, doc.Conversations[0].Exchanges, 1)
+	assert.Equal(t, answer, doc.Conversations[0].Exchanges[0].Answer)
+}
+
+// TestParseSource_TranscriptWithLargeToolResult ensures a transcript whose events
+// carry multi-megabyte tool results is discovered and parsed whole
+func TestParseSource_TranscriptWithLargeToolResult(t *testing.T) {
+	fileRead := strings.Repeat("package importer\n", 128*1024) // ~2MB
+	toolResult, err := json.Marshal(fileRead)
+	require.NoError(t, err)
+
+	transcript := strings.Join([]string{
+		`{"type":"user","sessionId":"s-1","timestamp":"2025-11-02T10:00:00.000Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t0","content":` + string(toolResult) + `}]}}`,
+		`{"type":"user","sessionId":"s-1","timestamp":"2025-11-02T10:00:01.000Z","message":{"role":"user","content":"Summarize pipeline.go"}}`,
+		`{"type":"assistant","sessionId":"s-1","timestamp":"2025-11-02T10:00:05.000Z","message":{"role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"text","text":"It runs the import stages."}]}}`,
+	}, "\n") + "\n"
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "s-1.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(transcript), 0644))
+
+	opts := DefaultImportOptions()
+	opts.RootDir = dir
+	source, err := DiscoverFile(opts, path)
+	require.NoError(t, err)
+	require.NotNil(t, source)
+	require.Equal(t, "claude-transcript", source.FileType)
+
+	doc, err := parseSource(*source)
+	require.NoError(t, err)
+	assert.Nil(t, doc.Metadata["skipped_lines"])
+	require.Len(t, doc.Conversations, 1)
+	require.Len(t, doc.Conversations[0].Exchanges, 1)
+	assert.Equal(t, "It runs the import stages.", doc.Conversations[0].Exchanges[0].Answer)
+}
@@ -0,0 +1,399 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// ClassificationPolicy is an ordered set of rules deciding the visibility of
+// imported sources. Rules are tried from the highest priority down (file order
+// breaks ties); the first rule whose conditions all hold classifies the source.
+type ClassificationPolicy struct {
+	Organization    string               `yaml:"organization" json:"organization"`
+	IncludeDefaults bool                 `yaml:"include_defaults" json:"include_defaults"` // Add the built-in rules not redefined by name
+	Rules           []ClassificationRule `yaml:"rules" json:"rules"`
+	GitRemotes      *GitRemotePolicy     `yaml:"git_remotes,omitempty" json:"git_remotes,omitempty"` // Overrides KG_*_GIT_* settings
+	Redaction       *RedactionPolicy     `yaml:"redaction,omitempty" json:"redaction,omitempty"`     // Applied to blocks made public or anonymized
+}
+
+// ClassificationRule classifies the sources matching all of its conditions
+type ClassificationRule struct {
+	Name        string    `yaml:"name" json:"name"`
+	Priority    int       `yaml:"priority" json:"priority"`
+	Explanation string    `yaml:"explanation" json:"explanation"` // Why the rule exists, shown by kg classify
+	Match       RuleMatch `yaml:"match" json:"match"`
+
+	Visibility         string   `yaml:"visibility" json:"visibility"` // "public", "anonymized", "org-private", "individual"
+	SourceClass        string   `yaml:"source_class" json:"source_class"`
+	ContainsPII        bool     `yaml:"contains_pii" json:"contains_pii"`
+	AssignOrganization bool     `yaml:"assign_organization" json:"assign_organization"` // Scope matches to the importing organization
+	RequiresCompliance []string `yaml:"requires_compliance" json:"requires_compliance"`
+
+	urls  []*regexp.Regexp
+	paths []*regexp.Regexp
+}
+
+// RuleMatch lists a rule's conditions. Every non-empty condition must hold;
+// a list holds when any of its entries matches.
+type RuleMatch struct {
+	Tags          []string `yaml:"tags" json:"tags"`                       // Tags in the content, e.g. "#public"
+	URLs          []string `yaml:"urls" json:"urls"`                       // Regexps tried against URLs in the content
+	Paths         []string `yaml:"paths" json:"paths"`                     // Regexps tried against the file path
+	PublicGitRepo bool     `yaml:"public_git_repo" json:"public_git_repo"` // File is in a checkout listed as public (see GitRemotePolicy)
+}
+
+// ClassificationResult explains which rule classified a source
+type ClassificationResult struct {
+	Rule        string // Rule name, or "default" when no rule matched
+	Explanation string
+	Matched     string       // The tag, URL, path pattern or repository that matched
+	PII         []PIIFinding // Sensitive data found in the content (see ScanPII)
+}
+
+// DefaultClassificationPolicy returns the built-in rules
+func DefaultClassificationPolicy() *ClassificationPolicy {
+	policy := &ClassificationPolicy{Rules: []ClassificationRule{
+		{
+			Name:        "public-tag",
+			Priority:    100,
+			Explanation: "The author tagged the file #public",
+			Match:       RuleMatch{Tags: []string{"#public"}},
+			Visibility:  "public",
+			SourceClass: "user-contributed",
+		},
+		{
+			Name:        "private-tag",
+			Priority:    90,
+			Explanation: "The author tagged the file #private or #confidential",
+			Match:       RuleMatch{Tags: []string{"#private", "#confidential"}},
+			Visibility:  "org-private",
+			SourceClass: "confidential",
+			ContainsPII: true,
+		},
+		{
+			Name:        "paywall-urls",
+			Priority:    80,
+			Explanation: "Content cites paywalled sources, which must not be republished",
+			Match: RuleMatch{URLs: []string{
+				`patreon\.com`,
+				`medium\.com/.*membership`,
+				`substack\.com/subscribe`,
+			}},
+			Visibility:  "org-private",
+			SourceClass: "paywall-content",
+		},
+		{
+			Name:        "public-web-urls",
+			Priority:    70,
+			Explanation: "Content cites public documentation, Q&A sites, repositories or blogs",
+			Match: RuleMatch{URLs: []string{
+				// Documentation sites
+				`https?://.*\.readthedocs\.io`,
+				`https?://docs\.`,
+				`https?://.*\.github\.io`,
+
+				// Q&A and community
+				`https?://stackoverflow\.com`,
+				`https?://stackexchange\.com`,
+				`https?://serverfault\.com`,
+				`https?://superuser\.com`,
+
+				// Public repositories
+				`https?://github\.com/[^/]+/[^/]+(?:/|$)`, // Public GitHub repos (not enterprise)
+				`https?://gitlab\.com/[^/]+/[^/]+(?:/|$)`,
+
+				// Technical blogs and resources
+				`https?://medium\.com`,
+				`https?://dev\.to`,
+				`https?://hashnode\.com`,
+
+				// Official documentation
+				`https?://developer\.mozilla\.org`,
+				`https?://golang\.org`,
+				`https?://python\.org`,
+				`https?://rust-lang\.org`,
+			}},
+			Visibility:  "public",
+			SourceClass: "public-web",
+		},
+		{
+			Name:               "client-directories",
+			Priority:           60,
+			Explanation:        "Client work stays inside the organization",
+			Match:              RuleMatch{Paths: []string{`/work/`, `/client-`, `/clients/`}},
+			Visibility:         "org-private",
+			SourceClass:        "client-data",
+			AssignOrganization: true,
+		},
+		{
+			Name:        "public-git-checkout",
+			Priority:    50,
+			Explanation: "The file is in a checkout of a git repository listed as public",
+			Match:       RuleMatch{PublicGitRepo: true},
+			Visibility:  "public",
+			SourceClass: "public-repo",
+		},
+		{
+			Name:        "personal-directories",
+			Priority:    40,
+			Explanation: "Files under home and personal directories belong to their owner",
+			Match:       RuleMatch{Paths: []string{`/personal/`, `/home/`, `/Users/[^/]+/Documents`}},
+			Visibility:  "individual",
+			SourceClass: "personal",
+		},
+	}}
+
+	if err := policy.compile(); err != nil {
+		panic(err)
+	}
+	return policy
+}
+
+// LoadClassificationPolicy reads a policy from a YAML or JSON (.json) file
+func LoadClassificationPolicy(path string) (*ClassificationPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read classification policy: %w", err)
+	}
+
+	policy := &ClassificationPolicy{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, policy)
+	} else {
+		err = yaml.Unmarshal(data, policy)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse classification policy %s: %w", path, err)
+	}
+
+	if policy.IncludeDefaults {
+		defined := make(map[string]bool)
+		for _, rule := range policy.Rules {
+			defined[rule.Name] = true
+		}
+		for _, rule := range DefaultClassificationPolicy().Rules {
+			if !defined[rule.Name] {
+				policy.Rules = append(policy.Rules, rule)
+			}
+		}
+	}
+
+	if err := policy.compile(); err != nil {
+		return nil, fmt.Errorf("invalid classification policy %s: %w", path, err)
+	}
+	if policy.Redaction != nil {
+		if err := policy.Redaction.compile(filepath.Dir(path)); err != nil {
+			return nil, fmt.Errorf("invalid classification policy %s: %w", path, err)
+		}
+	}
+	return policy, nil
+}
+
+// compile validates the rules, compiles their patterns and sorts them by priority
+func (p *ClassificationPolicy) compile() error {
+	seen := make(map[string]bool)
+
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if seen[rule.Name] {
+			return fmt.Errorf("rule %s is defined twice", rule.Name)
+		}
+		seen[rule.Name] = true
+
+		switch rule.Visibility {
+		case "public", "anonymized", "org-private", "individual":
+		default:
+			return fmt.Errorf("rule %s: invalid visibility %q (expected public, anonymized, org-private or individual)", rule.Name, rule.Visibility)
+		}
+		if rule.SourceClass == "" {
+			return fmt.Errorf("rule %s has no source_class", rule.Name)
+		}
+
+		m := rule.Match
+		if len(m.Tags) == 0 && len(m.URLs) == 0 && len(m.Paths) == 0 && !m.PublicGitRepo {
+			return fmt.Errorf("rule %s has no match conditions", rule.Name)
+		}
+
+		var err error
+		if rule.urls, err = compilePatterns(m.URLs); err != nil {
+			return fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+		if rule.paths, err = compilePatterns(m.Paths); err != nil {
+			return fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+	}
+
+	sort.SliceStable(p.Rules, func(i, j int) bool {
+		return p.Rules[i].Priority > p.Rules[j].Priority
+	})
+	return nil
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// contentURLRe finds URLs in content, stopping at markdown and quoting delimiters
+var contentURLRe = regexp.MustCompile("https?://[^\\s<>()\\[\\]\"'`]+")
+
+// match reports whether the rule's conditions hold for a source, returning what
+// matched and the URLs to attribute when the rule makes the source public
+func (r *ClassificationRule) match(path, content string, urls []string) (bool, string, []string) {
+	var matched []string
+	var attributed []string
+
+	if len(r.Match.Tags) > 0 {
+		tag := ""
+		for _, t := range r.Match.Tags {
+			if hasTag(content, t) {
+				tag = t
+				break
+			}
+		}
+		if tag == "" {
+			return false, "", nil
+		}
+		matched = append(matched, "tag "+tag)
+	}
+
+	if len(r.urls) > 0 {
+		var hits []string
+		for _, u := range urls {
+			for _, re := range r.urls {
+				if re.MatchString(u) {
+					hits = append(hits, u)
+					break
+				}
+			}
+		}
+		if len(hits) == 0 {
+			return false, "", nil
+		}
+		matched = append(matched, "url "+hits[0])
+		attributed = hits
+	}
+
+	if len(r.paths) > 0 {
+		pattern := ""
+		for _, re := range r.paths {
+			if re.MatchString(path) {
+				pattern = re.String()
+				break
+			}
+		}
+		if pattern == "" {
+			return false, "", nil
+		}
+		matched = append(matched, "path "+pattern)
+	}
+
+	if r.Match.PublicGitRepo {
+		repoURL, ok := isPublicGitRepo(path)
+		if !ok {
+			return false, "", nil
+		}
+		matched = append(matched, "repository "+repoURL)
+		attributed = append([]string{repoURL}, attributed...)
+	}
+
+	return true, strings.Join(matched, ", "), attributed
+}
+
+// classify applies the first matching rule to source
+func (p *ClassificationPolicy) classify(source *ImportSource, content string, orgID *uuid.UUID) (ClassificationResult, bool) {
+	urls := uniqueStrings(contentURLRe.FindAllString(content, -1))
+
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		ok, matched, attributed := rule.match(source.FilePath, content, urls)
+		if !ok {
+			continue
+		}
+
+		source.Visibility = rule.Visibility
+		source.SourceClass = rule.SourceClass
+		source.ContainsPII = rule.ContainsPII
+		if len(rule.RequiresCompliance) > 0 {
+			source.RequiresCompliance = rule.RequiresCompliance
+		}
+		if rule.AssignOrganization {
+			source.OrganizationID = orgID
+		}
+		if (rule.Visibility == "public" || rule.Visibility == "anonymized") && len(attributed) > 0 {
+			if source.Metadata == nil {
+				source.Metadata = make(map[string]interface{})
+			}
+			source.Metadata["source_urls"] = attributed
+			if rule.Match.PublicGitRepo {
+				source.Metadata["repository_url"] = attributed[0]
+			}
+		}
+
+		return ClassificationResult{Rule: rule.Name, Explanation: rule.Explanation, Matched: matched}, true
+	}
+
+	return ClassificationResult{}, false
+}
+
+// uniqueStrings drops repeated values, keeping first occurrences in order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+var (
+	policyMu             sync.RWMutex
+	classificationPolicy = DefaultClassificationPolicy()
+)
+
+// SetClassificationPolicy replaces the rules ClassifySource applies. A policy
+// with git_remotes also replaces the git remote policy.
+func SetClassificationPolicy(policy *ClassificationPolicy) {
+	policyMu.Lock()
+	classificationPolicy = policy
+	policyMu.Unlock()
+
+	if policy.GitRemotes != nil {
+		SetGitRemotePolicy(*policy.GitRemotes)
+	}
+}
+
+// activeClassificationPolicy returns the policy set by SetClassificationPolicy
+func activeClassificationPolicy() *ClassificationPolicy {
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	return classificationPolicy
+}
+
+// activeRedactionPolicy returns the redaction rules of the active policy (nil
+// when it has none)
+func activeRedactionPolicy() *RedactionPolicy {
+	return activeClassificationPolicy().Redaction
+}
@@ -0,0 +1,156 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeClassified writes a file for classification tests and returns its path
+func writeClassified(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestExplainClassification_DefaultRules(t *testing.T) {
+	dir := t.TempDir()
+	orgID := uuid.New()
+
+	tests := []struct {
+		name, file, content string
+		visibility, class   string
+		rule                string
+	}{
+		{"public tag", "a.md", "# Notes\n#public\n", "public", "user-contributed", "public-tag"},
+		{"private tag wins over URLs", "b.md", "#confidential\nhttps://stackoverflow.com/q/1\n", "org-private", "confidential", "private-tag"},
+		{"paywall", "c.md", "See https://www.patreon.com/posts/123 and https://dev.to/x\n", "org-private", "paywall-content", "paywall-urls"},
+		{"public web", "d.md", "From [the docs](https://docs.python.org/3/library/re.html).\n", "public", "public-web", "public-web-urls"},
+		{"client directory", "clients/acme/e.md", "# Plan\n", "org-private", "client-data", "client-directories"},
+		{"default", "f.md", "# Plain\n", "org-private", "private-repo", "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := ImportSource{FilePath: writeClassified(t, dir, tt.file, tt.content)}
+			result, err := ExplainClassification(&source, "auto", &orgID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.rule, result.Rule)
+			assert.Equal(t, tt.visibility, source.Visibility)
+			assert.Equal(t, tt.class, source.SourceClass)
+		})
+	}
+
+	source := ImportSource{FilePath: filepath.Join(dir, "d.md")}
+	result, err := ExplainClassification(&source, "auto", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "url https://docs.python.org/3/library/re.html", result.Matched)
+	assert.Equal(t, []string{"https://docs.python.org/3/library/re.html"}, source.Metadata["source_urls"],
+		"URLs end at markdown delimiters")
+	assert.NotEmpty(t, result.Explanation)
+
+	source = ImportSource{FilePath: filepath.Join(dir, "clients/acme/e.md")}
+	_, err = ExplainClassification(&source, "auto", &orgID)
+	require.NoError(t, err)
+	assert.Equal(t, &orgID, source.OrganizationID, "client data is scoped to the organization")
+}
+
+func TestLoadClassificationPolicy(t *testing.T) {
+	defer SetClassificationPolicy(DefaultClassificationPolicy())
+
+	dir := t.TempDir()
+	policyFile := writeClassified(t, dir, "acme.yaml", `
+organization: acme
+include_defaults: true
+rules:
+  - name: client-directories
+    priority: 95
+    explanation: Everything under a client folder is client data, even with public links
+    match:
+      paths: ["/clients/", "/uta/"]
+    visibility: org-private
+    source_class: client-data
+    assign_organization: true
+  - name: health-records
+    priority: 200
+    explanation: Patient data needs HIPAA handling
+    match:
+      tags: ["#phi"]
+      paths: ["/clinic/"]
+    visibility: org-private
+    source_class: client-data
+    contains_pii: true
+    requires_compliance: [hipaa]
+git_remotes:
+  public_hosts: [github.com]
+  private_orgs: [acme]
+`)
+
+	policy, err := LoadClassificationPolicy(policyFile)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", policy.Organization)
+	require.NotNil(t, policy.GitRemotes)
+	assert.Equal(t, []string{"acme"}, policy.GitRemotes.PrivateOrgs)
+
+	var names []string
+	for _, rule := range policy.Rules {
+		names = append(names, rule.Name)
+	}
+	assert.Equal(t, []string{
+		"health-records", "public-tag", "client-directories", "private-tag", "paywall-urls",
+		"public-web-urls", "public-git-checkout", "personal-directories",
+	}, names, "rules run by priority; redefined built-ins are replaced")
+
+	SetClassificationPolicy(policy)
+
+	source := ImportSource{FilePath: writeClassified(t, dir, "uta/notes.md", "https://stackoverflow.com/q/1\n")}
+	result, err := ExplainClassification(&source, "auto", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "client-directories", result.Rule)
+	assert.Equal(t, "path /uta/", result.Matched)
+
+	source = ImportSource{FilePath: writeClassified(t, dir, "clinic/visit.md", "#phi\n")}
+	result, err = ExplainClassification(&source, "auto", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "health-records", result.Rule)
+	assert.Equal(t, "tag #phi, path /clinic/", result.Matched)
+	assert.True(t, source.ContainsPII)
+	assert.Equal(t, []string{"hipaa"}, source.RequiresCompliance)
+
+	source = ImportSource{FilePath: writeClassified(t, dir, "other/visit.md", "#phi\n")}
+	result, err = ExplainClassification(&source, "individual", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "default", result.Rule, "all conditions of a rule must hold")
+	assert.Equal(t, "individual", source.Visibility)
+}
+
+func TestLoadClassificationPolicy_JSONAndValidation(t *testing.T) {
+	dir := t.TempDir()
+
+	policy, err := LoadClassificationPolicy(writeClassified(t, dir, "p.json",
+		`{"rules": [{"name": "drafts", "match": {"paths": ["/drafts/"]}, "visibility": "individual", "source_class": "draft"}]}`))
+	require.NoError(t, err)
+	require.Len(t, policy.Rules, 1, "built-in rules are only added with include_defaults")
+
+	invalid := map[string]string{
+		"no name":       `rules: [{match: {tags: ["#x"]}, visibility: public, source_class: x}]`,
+		"duplicate":     `rules: [{name: a, match: {tags: ["#x"]}, visibility: public, source_class: x}, {name: a, match: {tags: ["#y"]}, visibility: public, source_class: x}]`,
+		"visibility":    `rules: [{name: a, match: {tags: ["#x"]}, visibility: everyone, source_class: x}]`,
+		"no class":      `rules: [{name: a, match: {tags: ["#x"]}, visibility: public}]`,
+		"no conditions": `rules: [{name: a, visibility: public, source_class: x}]`,
+		"bad regexp":    `rules: [{name: a, match: {paths: ["("]}, visibility: public, source_class: x}]`,
+	}
+	for name, content := range invalid {
+		_, err := LoadClassificationPolicy(writeClassified(t, dir, "invalid.yaml", content))
+		assert.Error(t, err, name)
+	}
+
+	_, err = LoadClassificationPolicy(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,212 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RedactionPolicy lists what an organization removes from blocks before they
+// are made public: client names, internal hostnames and the like. The built-in
+// personal data and secret detectors (see ScanPII) always run first.
+type RedactionPolicy struct {
+	Dictionaries []RedactionDictionary `yaml:"dictionaries" json:"dictionaries"`
+	Patterns     []RedactionPattern    `yaml:"patterns" json:"patterns"`
+
+	detectors []detector
+}
+
+// RedactionDictionary is a list of terms replaced by [REDACTED:<name>]
+type RedactionDictionary struct {
+	Name  string   `yaml:"name" json:"name"`   // e.g. "client", "host"
+	Terms []string `yaml:"terms" json:"terms"` // Matched case-insensitively as whole words
+	File  string   `yaml:"file" json:"file"`   // One term per line ('#' comments), relative to the policy file
+}
+
+// RedactionPattern is a regexp replaced by [REDACTED:<name>]. When it has a
+// capture group only the group is replaced.
+type RedactionPattern struct {
+	Name  string `yaml:"name" json:"name"`
+	Regex string `yaml:"regex" json:"regex"`
+}
+
+// compile builds the policy's detectors, reading dictionary files relative to
+// baseDir. Patterns run before dictionaries, so a hostname pattern replaces the
+// whole name rather than a client term inside it.
+func (p *RedactionPolicy) compile(baseDir string) error {
+	p.detectors = append([]detector(nil), piiDetectors...)
+
+	for i, pattern := range p.Patterns {
+		if pattern.Name == "" {
+			return fmt.Errorf("redaction pattern %d has no name", i+1)
+		}
+		re, err := regexp.Compile(pattern.Regex)
+		if err != nil {
+			return fmt.Errorf("redaction pattern %s: invalid regex %q: %w", pattern.Name, pattern.Regex, err)
+		}
+		p.detectors = append(p.detectors, detector{kind: pattern.Name, re: re})
+	}
+
+	for i, dict := range p.Dictionaries {
+		if dict.Name == "" {
+			return fmt.Errorf("redaction dictionary %d has no name", i+1)
+		}
+
+		terms := dict.Terms
+		if dict.File != "" {
+			path := dict.File
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(baseDir, path)
+			}
+			fileTerms, err := readTermsFile(path)
+			if err != nil {
+				return fmt.Errorf("redaction dictionary %s: %w", dict.Name, err)
+			}
+			terms = append(append([]string(nil), terms...), fileTerms...)
+		}
+		terms = cleanTerms(terms)
+		if len(terms) == 0 {
+			return fmt.Errorf("redaction dictionary %s has no terms", dict.Name)
+		}
+
+		p.detectors = append(p.detectors, detector{kind: dict.Name, re: termsPattern(terms)})
+	}
+
+	return nil
+}
+
+// termsPattern matches any of terms case-insensitively, longest first so
+// "Acme Corp" wins over "Acme". Terms are bounded by word boundaries where they
+// start or end with a word character.
+func termsPattern(terms []string) *regexp.Regexp {
+	sorted := append([]string(nil), terms...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	alternatives := make([]string, 0, len(sorted))
+	for _, term := range sorted {
+		alt := regexp.QuoteMeta(term)
+		if first, _ := utf8.DecodeRuneInString(term); isWordRune(first) {
+			alt = `\b` + alt
+		}
+		if last, _ := utf8.DecodeLastRuneInString(term); isWordRune(last) {
+			alt += `\b`
+		}
+		alternatives = append(alternatives, alt)
+	}
+	return regexp.MustCompile(`(?i)(?:` + strings.Join(alternatives, "|") + `)`)
+}
+
+// cleanTerms trims terms and drops empty and repeated ones
+func cleanTerms(terms []string) []string {
+	cleaned := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if term = strings.TrimSpace(term); term != "" {
+			cleaned = append(cleaned, term)
+		}
+	}
+	return uniqueStrings(cleaned)
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// readTermsFile reads one term per line, skipping blank lines and # comments
+func readTermsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var terms []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			terms = append(terms, line)
+		}
+	}
+	return terms, scanner.Err()
+}
+
+// Redact replaces the policy's terms and patterns, and any personal data or
+// secrets, in text. A nil policy only removes personal data and secrets.
+func (p *RedactionPolicy) Redact(text string) (string, map[string]int) {
+	if p == nil || p.detectors == nil {
+		return RedactPII(text)
+	}
+	return redactWith(p.detectors, text)
+}
+
+// redactBlock redacts a block's topic and exchanges in place, adding the
+// replacement counts to its "redactions" metadata
+func (p *RedactionPolicy) redactBlock(pb *PreBlock) {
+	total := make(map[string]int)
+	redact := func(text string) string {
+		redacted, counts := p.Redact(text)
+		for kind, n := range counts {
+			total[kind] += n
+		}
+		return redacted
+	}
+
+	pb.Topic = redact(pb.Topic)
+	for i := range pb.Exchanges {
+		pb.Exchanges[i].Question = redact(pb.Exchanges[i].Question)
+		pb.Exchanges[i].Answer = redact(pb.Exchanges[i].Answer)
+	}
+
+	if pb.Metadata == nil {
+		pb.Metadata = make(map[string]interface{})
+	}
+	pb.Metadata["redacted"] = true
+	if len(total) > 0 {
+		pb.Metadata["redactions"] = total
+	}
+}
+
+// anonymizedCopy returns a redacted copy of pb to publish in place of the
+// original, which is kept org-private
+func (p *RedactionPolicy) anonymizedCopy(pb PreBlock) PreBlock {
+	cp := pb
+	cp.Exchanges = make([]PreExchange, len(pb.Exchanges))
+	copy(cp.Exchanges, pb.Exchanges)
+	cp.Metadata = make(map[string]interface{}, len(pb.Metadata)+1)
+	for k, v := range pb.Metadata {
+		cp.Metadata[k] = v
+	}
+	cp.Tags = append([]string(nil), pb.Tags...)
+
+	cp.Visibility = "anonymized"
+	cp.Metadata["anonymized"] = true
+	p.redactBlock(&cp)
+	return cp
+}
+
+// redactForPublic applies the redaction policy to the job's blocks before they
+// are embedded. Public blocks are redacted in place when redaction is enabled;
+// an anonymized source keeps its blocks org-private and gains a redacted copy
+// of each that is visible to everyone.
+func redactForPublic(job *sourceJob, policy *RedactionPolicy, enabled bool) {
+	switch {
+	case job.source.Visibility == "anonymized":
+		copies := make([]PreBlock, 0, len(job.blocks))
+		for i := range job.blocks {
+			job.blocks[i].Visibility = "org-private"
+			copies = append(copies, policy.anonymizedCopy(job.blocks[i]))
+		}
+		job.blocks = append(job.blocks, copies...)
+
+	case enabled && job.source.Visibility == "public":
+		for i := range job.blocks {
+			policy.redactBlock(&job.blocks[i])
+		}
+	}
+}
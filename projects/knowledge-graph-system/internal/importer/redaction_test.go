@@ -0,0 +1,110 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadClassificationPolicy_Redaction(t *testing.T) {
+	dir := t.TempDir()
+	writeClassified(t, dir, "clients.txt", "# Current clients\nGlobex\n\nInitech\n")
+	policyFile := writeClassified(t, dir, "acme.yaml", `
+organization: acme
+rules:
+  - name: shared-notes
+    match:
+      paths: ["/shared/"]
+    visibility: anonymized
+    source_class: user-contributed
+redaction:
+  dictionaries:
+    - name: client
+      terms: [Acme Corp, Acme]
+      file: clients.txt
+  patterns:
+    - name: host
+      regex: '\b[a-z0-9-]+\.corp\.acme\.com\b'
+`)
+
+	policy, err := LoadClassificationPolicy(policyFile)
+	require.NoError(t, err)
+	require.NotNil(t, policy.Redaction)
+
+	text, counts := policy.Redaction.Redact("Acme Corp and globex moved db01.corp.acme.com; mail jane@initech.io. Acmeville is unrelated.")
+	assert.Equal(t, "[REDACTED:client] and [REDACTED:client] moved [REDACTED:host]; mail [REDACTED:email]. Acmeville is unrelated.", text,
+		"longest term wins, terms match whole words only, personal data goes first")
+	assert.Equal(t, map[string]int{"client": 2, "host": 1, "email": 1}, counts)
+
+	for _, bad := range []string{
+		"redaction:\n  dictionaries:\n    - name: client\n      terms: ['  ']\n",
+		"redaction:\n  dictionaries:\n    - terms: [Acme]\n",
+		"redaction:\n  dictionaries:\n    - name: client\n      file: missing.txt\n",
+		"redaction:\n  patterns:\n    - name: host\n      regex: '('\n",
+	} {
+		_, err := LoadClassificationPolicy(writeClassified(t, dir, "bad.yaml", bad))
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRedactForPublic(t *testing.T) {
+	policy := &RedactionPolicy{Dictionaries: []RedactionDictionary{{Name: "client", Terms: []string{"Globex"}}}}
+	require.NoError(t, policy.compile(t.TempDir()))
+
+	newJob := func(visibility string) *sourceJob {
+		return &sourceJob{
+			source: ImportSource{Visibility: visibility},
+			blocks: []PreBlock{{
+				Topic:      "Globex migration",
+				Visibility: visibility,
+				Metadata:   map[string]interface{}{"chunk_index": 1},
+				Exchanges:  []PreExchange{{Question: "Which cluster does Globex use?", Answer: "The east one"}},
+			}},
+		}
+	}
+
+	t.Run("anonymized keeps the original private", func(t *testing.T) {
+		job := newJob("anonymized")
+		redactForPublic(job, policy, false)
+
+		require.Len(t, job.blocks, 2)
+		original, anon := job.blocks[0], job.blocks[1]
+		assert.Equal(t, "org-private", original.Visibility)
+		assert.Equal(t, "Globex migration", original.Topic)
+		assert.Equal(t, "Which cluster does Globex use?", original.Exchanges[0].Question)
+		assert.Nil(t, original.Metadata["anonymized"])
+
+		assert.Equal(t, "anonymized", anon.Visibility)
+		assert.Equal(t, "[REDACTED:client] migration", anon.Topic)
+		assert.Equal(t, "Which cluster does [REDACTED:client] use?", anon.Exchanges[0].Question)
+		assert.Equal(t, true, anon.Metadata["anonymized"])
+		assert.Equal(t, map[string]int{"client": 2}, anon.Metadata["redactions"])
+		assert.Equal(t, 1, anon.Metadata["chunk_index"])
+	})
+
+	t.Run("public blocks are redacted only when enabled", func(t *testing.T) {
+		job := newJob("public")
+		redactForPublic(job, policy, false)
+		assert.Equal(t, "Globex migration", job.blocks[0].Topic)
+
+		redactForPublic(job, policy, true)
+		require.Len(t, job.blocks, 1)
+		assert.Equal(t, "[REDACTED:client] migration", job.blocks[0].Topic)
+		assert.Equal(t, true, job.blocks[0].Metadata["redacted"])
+	})
+
+	t.Run("private blocks are left alone", func(t *testing.T) {
+		job := newJob("org-private")
+		redactForPublic(job, policy, true)
+		assert.Equal(t, "Globex migration", job.blocks[0].Topic)
+	})
+
+	t.Run("without a policy only personal data is removed", func(t *testing.T) {
+		job := newJob("public")
+		job.blocks[0].Exchanges[0].Answer = "Ask ops@globex.io"
+		redactForPublic(job, nil, true)
+		assert.Equal(t, "Globex migration", job.blocks[0].Topic)
+		assert.Equal(t, "Ask [REDACTED:email]", job.blocks[0].Exchanges[0].Answer)
+	})
+}
@@ -0,0 +1,214 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/importer/parsers"
+)
+
+// Parser turns the content of a source file into a ParsedDocument
+type Parser interface {
+	Parse(source ImportSource, content string) (*ParsedDocument, error)
+}
+
+// Chunker splits a ParsedDocument into PreBlocks
+type Chunker interface {
+	Chunk(doc *ParsedDocument, opts ChunkOptions) ([]PreBlock, error)
+}
+
+// DirectoryParser is implemented by parsers whose sources are directories, such
+// as a repository's .git directory, rather than files. Discovery matches the
+// format's patterns against directory names and asks Version for the hash that
+// import history compares between runs; the pipeline then calls ParseDirectory
+// instead of reading the source.
+type DirectoryParser interface {
+	Parser
+	Version(dir string) (string, error)
+	ParseDirectory(source ImportSource) (*ParsedDocument, error)
+}
+
+// ParserFunc adapts a plain function to the Parser interface
+type ParserFunc func(source ImportSource, content string) (*ParsedDocument, error)
+
+func (f ParserFunc) Parse(source ImportSource, content string) (*ParsedDocument, error) {
+	return f(source, content)
+}
+
+// ChunkerFunc adapts a plain function to the Chunker interface
+type ChunkerFunc func(doc *ParsedDocument, opts ChunkOptions) ([]PreBlock, error)
+
+func (f ChunkerFunc) Chunk(doc *ParsedDocument, opts ChunkOptions) ([]PreBlock, error) {
+	return f(doc, opts)
+}
+
+// Format describes how one source file type is discovered, parsed and chunked
+type Format struct {
+	FileType    string   // ImportSource.FileType, e.g. "conversation-log"
+	Description string   // One line shown by `kg import formats`
+	Types       []string // --types names that select this format, e.g. "logs"
+	Patterns    []string // File name globs (filepath.Match), tried in order
+	Parser      Parser   // A DirectoryParser makes the patterns match directories
+	Chunker     Chunker
+	OptIn       bool                   // Selected only by name, never by "all"
+	Detect      func(path string) bool // Checks the content of a file matching Patterns (nil: any match)
+}
+
+// isDirectoryFormat reports whether the format imports directories
+func isDirectoryFormat(f Format) bool {
+	_, ok := f.Parser.(DirectoryParser)
+	return ok
+}
+
+// formatRegistry holds registered formats in registration order, which is also
+// the order patterns are tried in when several formats are selected
+type formatRegistry struct {
+	mu      sync.RWMutex
+	formats []Format
+}
+
+var registry = &formatRegistry{}
+
+// RegisterFormat adds a source format to the importer. Formats are usually
+// registered from an init function; registering a FileType twice is an error.
+func RegisterFormat(f Format) error {
+	if f.FileType == "" {
+		return fmt.Errorf("format has no file type")
+	}
+	if f.Parser == nil || f.Chunker == nil {
+		return fmt.Errorf("format %s needs both a parser and a chunker", f.FileType)
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	for _, existing := range registry.formats {
+		if existing.FileType == f.FileType {
+			return fmt.Errorf("format %s is already registered", f.FileType)
+		}
+	}
+	registry.formats = append(registry.formats, f)
+	return nil
+}
+
+// Formats returns the registered formats in registration order
+func Formats() []Format {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	return append([]Format(nil), registry.formats...)
+}
+
+// LookupFormat returns the format registered for a file type
+func LookupFormat(fileType string) (Format, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	for _, f := range registry.formats {
+		if f.FileType == fileType {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// markdownParser is the generic heading-based parser used by documentation formats
+var markdownParser = ParserFunc(parseMarkdown)
+
+// detectClaudeTranscript keeps other *.jsonl files (data, logs) from being
+// imported as transcripts
+func detectClaudeTranscript(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	return parsers.IsClaudeTranscript(f)
+}
+
+func init() {
+	builtin := []Format{
+		{
+			FileType:    "conversation-log",
+			Description: "Session logs with timestamped milestones",
+			Types:       []string{"logs", "conversation-logs"},
+			Patterns:    []string{"session-*.md"},
+			Parser:      parsers.NewConversationLogParser(),
+			Chunker:     ChunkerFunc(chunkConversationLog),
+		},
+		{
+			FileType:    "spec",
+			Description: "Specifications, one block per section",
+			Types:       []string{"specs", "specifications"},
+			Patterns:    []string{"*-spec.md", "*-specification.md"},
+			Parser:      markdownParser,
+			Chunker:     ChunkerFunc(chunkDocumentation),
+		},
+		{
+			FileType:    "readme",
+			Description: "Project READMEs, one block per section",
+			Types:       []string{"docs", "documentation"},
+			Patterns:    []string{"README.md"},
+			Parser:      markdownParser,
+			Chunker:     ChunkerFunc(chunkDocumentation),
+		},
+		{
+			FileType:    "mission",
+			Description: "Mission statements, one block per section",
+			Types:       []string{"docs", "documentation"},
+			Patterns:    []string{"MISSION.md"},
+			Parser:      markdownParser,
+			Chunker:     ChunkerFunc(chunkDocumentation),
+		},
+		{
+			FileType:    "working-file",
+			Description: "Agent .working.md scratch files",
+			Types:       []string{"working"},
+			Patterns:    []string{".working.md"},
+			Parser:      markdownParser,
+			Chunker:     ChunkerFunc(chunkWorkingFile),
+		},
+		{
+			FileType:    "doc",
+			Description: "Any other markdown, one block per section",
+			Types:       []string{"docs", "documentation"},
+			Patterns:    []string{"*.md"},
+			Parser:      markdownParser,
+			Chunker:     ChunkerFunc(chunkDocumentation),
+		},
+		{
+			FileType:    "chatgpt-export",
+			Description: "ChatGPT data exports, chunked per conversation",
+			Types:       []string{"chatgpt"},
+			Patterns:    []string{"conversations.json"},
+			Parser:      parsers.NewChatGPTExportParser(),
+			Chunker:     ChunkerFunc(chunkConversations),
+		},
+		{
+			FileType:    "claude-transcript",
+			Description: "Claude JSONL session transcripts, chunked per session",
+			Types:       []string{"transcripts", "claude"},
+			Patterns:    []string{"*.jsonl"},
+			Parser:      parsers.NewClaudeTranscriptParser(),
+			Chunker:     ChunkerFunc(chunkConversations),
+			Detect:      detectClaudeTranscript,
+		},
+		{
+			FileType:    "git-history",
+			Description: "Git commit history, grouped by topic and time",
+			Types:       []string{"git"},
+			Patterns:    []string{".git"},
+			Parser:      parsers.NewGitHistoryParser(),
+			Chunker:     ChunkerFunc(chunkGitHistory),
+			OptIn:       true,
+		},
+	}
+
+	for _, f := range builtin {
+		if err := RegisterFormat(f); err != nil {
+			panic(err)
+		}
+	}
+}
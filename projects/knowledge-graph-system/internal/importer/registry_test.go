@@ -0,0 +1,164 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withFormat registers a format for the duration of a test
+func withFormat(t *testing.T, f Format) {
+	t.Helper()
+	saved := Formats()
+	t.Cleanup(func() {
+		registry.mu.Lock()
+		registry.formats = saved
+		registry.mu.Unlock()
+	})
+	require.NoError(t, RegisterFormat(f))
+}
+
+// TestGetFilePatterns_BuiltinOrder lists specific patterns before the catch-all *.md
+func TestGetFilePatterns_BuiltinOrder(t *testing.T) {
+	var got []string
+	for _, p := range getFilePatterns([]string{"all"}) {
+		got = append(got, p.Type+":"+p.Pattern)
+	}
+
+	assert.Equal(t, []string{
+		"conversation-log:session-*.md",
+		"spec:*-spec.md",
+		"spec:*-specification.md",
+		"readme:README.md",
+		"mission:MISSION.md",
+		"working-file:.working.md",
+		"doc:*.md",
+		"chatgpt-export:conversations.json",
+		"claude-transcript:*.jsonl",
+	}, got, "opt-in formats (git history) are not part of all")
+
+	git := getFilePatterns([]string{"git"})
+	assert.Equal(t, []FilePattern{{Pattern: ".git", Type: "git-history", Directory: true}}, git)
+
+	docs := getFilePatterns([]string{"docs", "documentation", "readme"})
+	assert.Len(t, docs, 3, "a format selected by several types is listed once")
+}
+
+// TestDiscover_MostSpecificPatternWins keeps .working.md and README.md from
+// being discovered as generic docs under the default "all"
+func TestDiscover_MostSpecificPatternWins(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{".working.md", "README.md", "session-2025-01-01.md", "api-spec.md", "notes.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("# Title\n\nBody\n"), 0644))
+	}
+
+	opts := DefaultImportOptions()
+	opts.FileTypes = []string{"all"}
+	opts.RootDir = dir
+	sources, err := Discover(opts)
+	require.NoError(t, err)
+
+	types := make(map[string]string)
+	for _, source := range sources {
+		types[filepath.Base(source.FilePath)] = source.FileType
+	}
+	assert.Equal(t, map[string]string{
+		".working.md":           "working-file",
+		"README.md":             "readme",
+		"session-2025-01-01.md": "conversation-log",
+		"api-spec.md":           "spec",
+		"notes.md":              "doc",
+	}, types)
+
+	source, err := DiscoverFile(opts, filepath.Join(dir, ".working.md"))
+	require.NoError(t, err)
+	require.NotNil(t, source)
+	assert.Equal(t, "working-file", source.FileType)
+
+	// A format registered after doc still wins for the files it names exactly
+	withFormat(t, Format{
+		FileType: "changelog",
+		Patterns: []string{"CHANGELOG.md"},
+		Parser:   markdownParser,
+		Chunker:  ChunkerFunc(chunkDocumentation),
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "CHANGELOG.md"), []byte("# Changes\n"), 0644))
+	source, err = DiscoverFile(opts, filepath.Join(dir, "CHANGELOG.md"))
+	require.NoError(t, err)
+	require.NotNil(t, source)
+	assert.Equal(t, "changelog", source.FileType)
+}
+
+// TestDiscover_OnlyClaudeTranscriptsAsTranscripts skips other JSONL files quietly
+func TestDiscover_OnlyClaudeTranscriptsAsTranscripts(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "s-1.jsonl"),
+		[]byte(`{"type":"user","sessionId":"s-1","message":{"role":"user","content":"hi"}}`+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "requests.jsonl"),
+		[]byte(`{"request_id":"user-001","title":"Add search","body":"..."}`+"\n"), 0644))
+
+	opts := DefaultImportOptions()
+	opts.FileTypes = []string{"all"}
+	opts.RootDir = dir
+	sources, err := Discover(opts)
+	require.NoError(t, err)
+	require.Len(t, sources, 1)
+	assert.Equal(t, "claude-transcript", sources[0].FileType)
+	assert.Equal(t, "s-1.jsonl", filepath.Base(sources[0].FilePath))
+
+	source, err := DiscoverFile(opts, filepath.Join(dir, "requests.jsonl"))
+	require.NoError(t, err)
+	assert.Nil(t, source)
+}
+
+// TestRegisterFormat_Validation rejects incomplete and duplicate formats
+func TestRegisterFormat_Validation(t *testing.T) {
+	assert.Error(t, RegisterFormat(Format{Parser: markdownParser, Chunker: ChunkerFunc(chunkDocumentation)}))
+	assert.Error(t, RegisterFormat(Format{FileType: "no-chunker", Parser: markdownParser}))
+	assert.Error(t, RegisterFormat(Format{
+		FileType: "spec",
+		Parser:   markdownParser,
+		Chunker:  ChunkerFunc(chunkDocumentation),
+	}))
+}
+
+// TestRegisterFormat_CustomFormatIsDiscoveredParsedAndChunked runs a third-party format through the pipeline stages
+func TestRegisterFormat_CustomFormatIsDiscoveredParsedAndChunked(t *testing.T) {
+	var parsed, chunked bool
+	withFormat(t, Format{
+		FileType: "notes",
+		Types:    []string{"notes"},
+		Patterns: []string{"*.notes"},
+		Parser: ParserFunc(func(source ImportSource, content string) (*ParsedDocument, error) {
+			parsed = true
+			return parseMarkdown(source, content)
+		}),
+		Chunker: ChunkerFunc(func(doc *ParsedDocument, opts ChunkOptions) ([]PreBlock, error) {
+			chunked = true
+			return chunkDocumentation(doc, opts)
+		}),
+	})
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "team.notes")
+	require.NoError(t, os.WriteFile(path, []byte("# Notes\n\n## Decision\n\nUse the registry.\n"), 0644))
+
+	opts := DefaultImportOptions()
+	opts.FileTypes = []string{"notes"}
+	opts.RootDir = dir
+	sources, err := Discover(opts)
+	require.NoError(t, err)
+	require.Len(t, sources, 1)
+	assert.Equal(t, "notes", sources[0].FileType)
+
+	doc, err := parseSource(sources[0])
+	require.NoError(t, err)
+	_, err = ChunkDocument(doc, DefaultChunkOptions())
+	require.NoError(t, err)
+
+	assert.True(t, parsed, "registered parser should be used")
+	assert.True(t, chunked, "registered chunker should be used")
+}
@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/db"
+	"github.com/google/uuid"
+)
+
+// resumeStore is the subset of PostgresDB needed to recover interrupted imports
+type resumeStore interface {
+	QueryImportHistory(ctx context.Context, sourceFile, fileHash string) (*db.ImportHistoryRecord, error)
+	QueryInterruptedImports(ctx context.Context) ([]db.ImportHistoryRecord, error)
+	QueryBatchBlocks(ctx context.Context, batchID uuid.UUID) ([]db.BlockSourceRecord, error)
+	RollbackImportBatch(ctx context.Context, batchID uuid.UUID, reason string) error
+}
+
+// prepareResume drops sources whose current version already completed, rolls back
+// interrupted batches whose file changed or disappeared, and returns the
+// interrupted batches that can be picked up again, keyed by source file.
+func prepareResume(ctx context.Context, store resumeStore, sources []ImportSource, report *ImportReport) ([]ImportSource, map[string]db.ImportHistoryRecord, error) {
+	interrupted, err := store.QueryInterruptedImports(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	currentHash := make(map[string]string, len(sources))
+	remaining := make([]ImportSource, 0, len(sources))
+	for _, source := range sources {
+		currentHash[source.FilePath] = source.FileHash
+
+		record, err := store.QueryImportHistory(ctx, source.FilePath, source.FileHash)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, nil, err
+		}
+		if err == nil && record.Status == "completed" {
+			report.SourcesSkipped++
+			continue
+		}
+		remaining = append(remaining, source)
+	}
+
+	resumable := make(map[string]db.ImportHistoryRecord)
+	for _, record := range interrupted {
+		hash, discovered := currentHash[record.SourceFile]
+
+		var reason string
+		switch {
+		case discovered && hash == record.FileHash:
+			resumable[record.SourceFile] = record
+			continue
+		case discovered:
+			reason = "source file changed since the interrupted import"
+		default:
+			// Files outside this run's scope are left for the run that covers them
+			if _, err := os.Stat(record.SourceFile); !os.IsNotExist(err) {
+				continue
+			}
+			reason = "source file no longer exists"
+		}
+
+		if err := store.RollbackImportBatch(ctx, record.ID, "interrupted import rolled back: "+reason); err != nil {
+			return nil, nil, err
+		}
+		report.Recovered = append(report.Recovered, RecoveredBatch{
+			SourceFile:      record.SourceFile,
+			BatchID:         record.ID,
+			Action:          "rolled-back",
+			BlocksDiscarded: record.BlockCount,
+			Reason:          reason,
+		})
+	}
+
+	return remaining, resumable, nil
+}
+
+// resumeSource finishes an interrupted insert batch. Blocks are written in chunk
+// order, so if the stored blocks are a prefix of the file's PreBlocks only the
+// rest is imported; otherwise the batch is reset and the file imported from scratch.
+func resumeSource(ctx context.Context, store sourceImporter, source ImportSource, preBlocks []PreBlock, record db.ImportHistoryRecord) (int, *RecoveredBatch, []ImportError) {
+	recovered := &RecoveredBatch{SourceFile: source.FilePath, BatchID: record.ID}
+
+	var existing []db.BlockSourceRecord
+	resumer, ok := store.(interface {
+		QueryBatchBlocks(ctx context.Context, batchID uuid.UUID) ([]db.BlockSourceRecord, error)
+	})
+	if ok {
+		var err error
+		existing, err = resumer.QueryBatchBlocks(ctx, record.ID)
+		if err != nil {
+			return 0, nil, []ImportError{{Source: source, Stage: "resume", Message: "Failed to read interrupted batch", Error: err}}
+		}
+	}
+
+	if !ok || !isBlockPrefix(existing, preBlocks) {
+		imported, errs := importSource(ctx, store, source, preBlocks)
+		recovered.Action = "restarted"
+		recovered.BlocksDiscarded = len(existing)
+		recovered.BlocksAdded = imported
+		recovered.Reason = "blocks written before the interruption do not match the file"
+		return imported, recovered, errs
+	}
+
+	added, errs := importBatchBlocks(ctx, store, source, preBlocks[len(existing):], record.ID)
+	recovered.Action = "finished"
+	recovered.BlocksKept = len(existing)
+	recovered.BlocksAdded = added
+	recovered.Reason = fmt.Sprintf("%d of %d blocks were written before the interruption", len(existing), len(preBlocks))
+
+	return len(existing) + added, recovered, errs
+}
+
+// isBlockPrefix reports whether stored blocks match the leading PreBlocks one-for-one
+func isBlockPrefix(existing []db.BlockSourceRecord, preBlocks []PreBlock) bool {
+	if len(existing) > len(preBlocks) {
+		return false
+	}
+	for i, record := range existing {
+		if record.Topic != preBlocks[i].Topic || record.SourceHash != preBlocks[i].SourceHash {
+			return false
+		}
+	}
+	return true
+}
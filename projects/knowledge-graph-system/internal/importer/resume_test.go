@@ -0,0 +1,126 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/db"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResumeStore adds interrupted-batch bookkeeping to fakeImportStore
+type fakeResumeStore struct {
+	*fakeImportStore
+
+	completedHashes map[string]string // source file → hash of its completed batch
+	interrupted     []db.ImportHistoryRecord
+	batchBlocks     map[uuid.UUID][]db.BlockSourceRecord
+	rolledBack      []uuid.UUID
+}
+
+func (f *fakeResumeStore) QueryImportHistory(ctx context.Context, sourceFile, fileHash string) (*db.ImportHistoryRecord, error) {
+	if f.completedHashes[sourceFile] == fileHash {
+		return &db.ImportHistoryRecord{SourceFile: sourceFile, FileHash: fileHash, Status: "completed"}, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeResumeStore) QueryInterruptedImports(ctx context.Context) ([]db.ImportHistoryRecord, error) {
+	return f.interrupted, nil
+}
+
+func (f *fakeResumeStore) QueryBatchBlocks(ctx context.Context, batchID uuid.UUID) ([]db.BlockSourceRecord, error) {
+	return f.batchBlocks[batchID], nil
+}
+
+func (f *fakeResumeStore) RollbackImportBatch(ctx context.Context, batchID uuid.UUID, reason string) error {
+	f.rolledBack = append(f.rolledBack, batchID)
+	return nil
+}
+
+// TestPrepareResume_SkipsCompletedAndRollsBackStale covers the three kinds of source on resume
+func TestPrepareResume_SkipsCompletedAndRollsBackStale(t *testing.T) {
+	sources := writeTestDocs(t, 3)
+	changedBatch, resumableBatch, goneBatch := uuid.New(), uuid.New(), uuid.New()
+
+	store := &fakeResumeStore{
+		fakeImportStore: newFakeImportStore(),
+		completedHashes: map[string]string{sources[0].FilePath: sources[0].FileHash},
+		interrupted: []db.ImportHistoryRecord{
+			{ID: changedBatch, SourceFile: sources[1].FilePath, FileHash: "stale-hash", BlockCount: 2, Status: "in-progress"},
+			{ID: resumableBatch, SourceFile: sources[2].FilePath, FileHash: sources[2].FileHash, BlockCount: 1, Status: "in-progress"},
+			{ID: goneBatch, SourceFile: "/nonexistent/deleted.md", FileHash: "x", BlockCount: 4, Status: "in-progress"},
+		},
+	}
+	report := &ImportReport{}
+
+	remaining, resumable, err := prepareResume(context.Background(), store, sources, report)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.SourcesSkipped)
+	require.Len(t, remaining, 2)
+	assert.Equal(t, sources[1].FilePath, remaining[0].FilePath)
+
+	require.Contains(t, resumable, sources[2].FilePath)
+	assert.Equal(t, resumableBatch, resumable[sources[2].FilePath].ID)
+
+	assert.ElementsMatch(t, []uuid.UUID{changedBatch, goneBatch}, store.rolledBack)
+	require.Len(t, report.Recovered, 2)
+	assert.Equal(t, "rolled-back", report.Recovered[0].Action)
+	assert.Equal(t, 2, report.Recovered[0].BlocksDiscarded)
+}
+
+// TestResumeSource_FinishesOrRestarts checks prefix detection for interrupted batches
+func TestResumeSource_FinishesOrRestarts(t *testing.T) {
+	source := ImportSource{FilePath: "/docs/guide.md", FileType: "doc", FileHash: "h1"}
+	preBlocks := []PreBlock{
+		{Topic: "Intro", SourceFile: source.FilePath, SourceHash: "h1", SourceType: "doc"},
+		{Topic: "Setup", SourceFile: source.FilePath, SourceHash: "h1", SourceType: "doc"},
+		{Topic: "Usage", SourceFile: source.FilePath, SourceHash: "h1", SourceType: "doc"},
+	}
+
+	t.Run("finishes matching prefix", func(t *testing.T) {
+		batchID := uuid.New()
+		store := &fakeResumeStore{
+			fakeImportStore: newFakeImportStore(),
+			batchBlocks: map[uuid.UUID][]db.BlockSourceRecord{
+				batchID: {{Topic: "Intro", SourceHash: "h1"}, {Topic: "Setup", SourceHash: "h1"}},
+			},
+		}
+		store.batches[batchID] = source.FilePath
+
+		imported, recovered, errs := resumeSource(context.Background(), store, source, preBlocks,
+			db.ImportHistoryRecord{ID: batchID, SourceFile: source.FilePath, FileHash: "h1", BlockCount: 2})
+
+		require.Empty(t, errs)
+		assert.Equal(t, 3, imported)
+		assert.Equal(t, "finished", recovered.Action)
+		assert.Equal(t, 2, recovered.BlocksKept)
+		assert.Equal(t, 1, recovered.BlocksAdded)
+		require.Len(t, store.written[source.FilePath], 1)
+		assert.Equal(t, "Usage", store.written[source.FilePath][0].Topic)
+		assert.Equal(t, []string{source.FilePath}, store.completed)
+	})
+
+	t.Run("restarts on mismatch", func(t *testing.T) {
+		batchID := uuid.New()
+		store := &fakeResumeStore{
+			fakeImportStore: newFakeImportStore(),
+			batchBlocks: map[uuid.UUID][]db.BlockSourceRecord{
+				batchID: {{Topic: "Old intro", SourceHash: "h1"}},
+			},
+		}
+
+		imported, recovered, errs := resumeSource(context.Background(), store, source, preBlocks,
+			db.ImportHistoryRecord{ID: batchID, SourceFile: source.FilePath, FileHash: "h1", BlockCount: 1})
+
+		require.Empty(t, errs)
+		assert.Equal(t, 3, imported)
+		assert.Equal(t, "restarted", recovered.Action)
+		assert.Equal(t, 1, recovered.BlocksDiscarded)
+		assert.Len(t, store.written[source.FilePath], 3)
+	})
+}
@@ -0,0 +1,427 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/core"
+	"github.com/TheGenXCoder/knowledge-graph/internal/db"
+)
+
+// sourceJob carries one source file through the concurrent import stages.
+// Each stage fills in its own fields; the report is assembled from jobs in
+// discovery order so output is deterministic regardless of scheduling.
+type sourceJob struct {
+	index  int
+	source ImportSource
+
+	doc       *ParsedDocument  // parse stage
+	blocks    []PreBlock       // chunk stage
+	decisions []ImportDecision // chunk stage (dedup runs right after chunking)
+	replace   bool             // chunk stage: the file changed, so its stale blocks are replaced (even by none)
+	embedded  bool             // embed stage
+
+	imported  int // write stage
+	replaced  int
+	failed    int
+	recovered *RecoveredBatch
+	errors    []ImportError
+}
+
+// pending returns the PreBlocks that still have to be written (inserts, updates and appends)
+func (j *sourceJob) pending() []*PreBlock {
+	var blocks []*PreBlock
+	for _, d := range j.decisions {
+		if d.Action != "skip" {
+			blocks = append(blocks, d.PreBlock)
+		}
+	}
+	return blocks
+}
+
+// stageRunner wires the parse → chunk → embed → write stages together.
+// Channels between stages are bounded, so a slow stage (usually embedding)
+// applies backpressure instead of letting parsed documents pile up in memory.
+type stageRunner struct {
+	kg       core.KnowledgeGraph
+	history  importHistoryStore
+	writer   sourceImporter // nil in dry-run
+	embedder core.Embedder  // nil when the store embeds at insert time
+	opts     ImportOptions
+
+	redaction *RedactionPolicy // Redaction rules for public and anonymized blocks (nil: personal data only)
+
+	// Interrupted batches to pick up again (--resume), keyed by source file
+	resumable map[string]db.ImportHistoryRecord
+
+	cancel   context.CancelFunc
+	failOnce sync.Once
+	fatalErr error
+}
+
+// fail aborts the whole pipeline with a fatal error (the first one wins)
+func (r *stageRunner) fail(err error) {
+	r.failOnce.Do(func() {
+		r.fatalErr = err
+		r.cancel()
+	})
+}
+
+// runStages pushes every source through the stage workers and returns the
+// finished jobs in discovery order. resumable holds interrupted batches to
+// finish (may be nil). onDone is called from the calling goroutine each time a
+// job leaves the last stage.
+func runStages(ctx context.Context, kg core.KnowledgeGraph, sources []ImportSource, resumable map[string]db.ImportHistoryRecord, opts ImportOptions, onDone func(done int)) ([]*sourceJob, error) {
+	history, ok := kg.(importHistoryStore)
+	if !ok {
+		return nil, fmt.Errorf("knowledge graph does not support import history queries")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	r := &stageRunner{kg: kg, history: history, opts: opts, resumable: resumable, cancel: cancel}
+	r.redaction = activeRedactionPolicy()
+	if !opts.DryRun {
+		if r.writer, ok = kg.(sourceImporter); !ok {
+			return nil, fmt.Errorf("knowledge graph does not support batched imports")
+		}
+		if e, ok := kg.(interface{ Embedder() core.Embedder }); ok {
+			r.embedder = e.Embedder()
+		}
+	}
+
+	jobs := make([]*sourceJob, len(sources))
+	for i := range sources {
+		jobs[i] = &sourceJob{index: i, source: sources[i]}
+	}
+
+	parseWorkers := max(opts.ParseWorkers, 1)
+	chunkWorkers := max(opts.ChunkWorkers, 1)
+	embedWorkers := max(opts.EmbedWorkers, 1)
+
+	parseIn := make(chan *sourceJob)
+	chunkIn := make(chan *sourceJob, chunkWorkers)
+	embedIn := make(chan *sourceJob, embedWorkers)
+	writeIn := make(chan *sourceJob, embedWorkers)
+	done := make(chan *sourceJob)
+
+	go func() {
+		defer close(parseIn)
+		for _, job := range jobs {
+			select {
+			case parseIn <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	runStage(ctx, parseWorkers, parseIn, chunkIn, r.parse)
+	runStage(ctx, chunkWorkers, chunkIn, embedIn, r.chunk)
+	runStage(ctx, embedWorkers, embedIn, writeIn, r.embed)
+	runStage(ctx, 1, writeIn, done, r.write)
+
+	finished := 0
+	for range done {
+		finished++
+		if onDone != nil {
+			onDone(finished)
+		}
+	}
+
+	if r.fatalErr != nil {
+		return nil, r.fatalErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("import cancelled: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// runStage starts n workers applying fn to each job from in and forwarding it
+// to out. out is closed once every worker has exited; workers stop early when
+// ctx is cancelled.
+func runStage(ctx context.Context, n int, in <-chan *sourceJob, out chan<- *sourceJob, fn func(context.Context, *sourceJob)) {
+	var wg sync.WaitGroup
+	for w := 0; w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range in {
+				if ctx.Err() != nil {
+					return
+				}
+				fn(ctx, job)
+				select {
+				case out <- job:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+}
+
+// parse reads and parses the job's source file
+func (r *stageRunner) parse(ctx context.Context, job *sourceJob) {
+	doc, err := parseSource(job.source)
+	if err != nil {
+		job.errors = append(job.errors, ImportError{
+			Source:  job.source,
+			Stage:   "parse",
+			Message: "Failed to parse source",
+			Error:   err,
+		})
+		job.failed++
+		return
+	}
+	job.doc = doc
+}
+
+// chunk splits the parsed document into PreBlocks and decides what to do with each
+func (r *stageRunner) chunk(ctx context.Context, job *sourceJob) {
+	if job.doc == nil {
+		return
+	}
+
+	// A session log that only grew since its last import gets its new milestones
+	// appended (anonymized logs hold two copies of each chunk, so are re-chunked)
+	if job.source.FileType == "conversation-log" && job.source.Visibility != "anonymized" {
+		blocks, decisions, ok, err := planAppend(ctx, r.history, job.doc)
+		if err != nil {
+			r.fail(fmt.Errorf("deduplication failed: failed to check %s for appended milestones: %w", job.source.FilePath, err))
+			return
+		}
+		if ok {
+			job.blocks = blocks
+			job.decisions = decisions
+			r.addAttribution(job)
+			r.protectPII(job)
+			redactForPublic(job, r.redaction, r.opts.Redact)
+			return
+		}
+	}
+
+	blocks, err := ChunkDocument(job.doc, DefaultChunkOptions())
+	if err != nil {
+		job.errors = append(job.errors, ImportError{
+			Source:  job.source,
+			Stage:   "chunk",
+			Message: "Failed to chunk document",
+			Error:   err,
+		})
+		job.failed++
+		return
+	}
+	job.blocks = blocks
+	r.addAttribution(job)
+	r.protectPII(job)
+	redactForPublic(job, r.redaction, r.opts.Redact)
+
+	for i := range job.blocks {
+		decision, err := deduplicateBlock(ctx, r.history, &job.blocks[i])
+		if err != nil {
+			r.fail(fmt.Errorf("deduplication failed: failed to deduplicate block from %s: %w", job.source.FilePath, err))
+			return
+		}
+		job.decisions = append(job.decisions, decision)
+	}
+	job.replace = hasAction(job.decisions, "update")
+
+	// A changed file that no longer yields any block (its sections were all
+	// removed, a working file was cleared) still has its stale blocks replaced
+	if len(job.blocks) == 0 {
+		probe := PreBlock{SourceFile: job.source.FilePath, SourceType: job.source.FileType, SourceHash: job.source.FileHash}
+		decision, err := deduplicateBlock(ctx, r.history, &probe)
+		if err != nil {
+			r.fail(fmt.Errorf("deduplication failed: failed to check %s for changes: %w", job.source.FilePath, err))
+			return
+		}
+		job.replace = decision.Action == "update"
+	}
+}
+
+// addAttribution copies the source URL found during classification onto the
+// job's blocks and records the importing user as their owner
+func (r *stageRunner) addAttribution(job *sourceJob) {
+	for i := range job.blocks {
+		AddAttributionToPreBlock(&job.blocks[i], &job.source)
+		job.blocks[i].OwnerID = r.opts.Owner
+	}
+}
+
+// protectPII flags the job's blocks when its source holds sensitive data and,
+// with --redact-pii, redacts that data before the blocks are embedded
+func (r *stageRunner) protectPII(job *sourceJob) {
+	for i := range job.blocks {
+		markPII(&job.blocks[i], &job.source, r.opts.RedactPII)
+	}
+}
+
+// embed generates block and exchange embeddings in batches of opts.BatchSize.
+// A failed batch fails the whole file so it is retried on the next run.
+func (r *stageRunner) embed(ctx context.Context, job *sourceJob) {
+	if r.embedder == nil {
+		return
+	}
+
+	pending := job.pending()
+	if len(pending) == 0 {
+		job.embedded = true
+		return
+	}
+
+	var texts []string
+	var targets []*[]float64
+	for _, pb := range pending {
+		texts = append(texts, blockEmbeddingText(pb))
+		targets = append(targets, &pb.Embedding)
+		for i := range pb.Exchanges {
+			ex := &pb.Exchanges[i]
+			texts = append(texts, ex.Question+" "+ex.Answer)
+			targets = append(targets, &ex.Embedding)
+		}
+	}
+
+	batchSize := max(r.opts.BatchSize, 1)
+	for start := 0; start < len(texts); start += batchSize {
+		end := min(start+batchSize, len(texts))
+
+		vectors, err := r.embedder.EmbedBatch(ctx, texts[start:end])
+		if err == nil && len(vectors) != end-start {
+			err = fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), end-start)
+		}
+		if err != nil {
+			job.errors = append(job.errors, ImportError{
+				Source:  job.source,
+				Stage:   "embed",
+				Message: fmt.Sprintf("Failed to embed %d blocks", len(pending)),
+				Error:   err,
+			})
+			job.failed += len(pending)
+			return
+		}
+
+		for i, vector := range vectors {
+			*targets[start+i] = vector
+		}
+	}
+
+	job.embedded = true
+}
+
+// write persists the job's blocks; it runs on a single goroutine so import
+// batches are never written concurrently
+func (r *stageRunner) write(ctx context.Context, job *sourceJob) {
+	if r.writer == nil {
+		return
+	}
+	if r.embedder != nil && !job.embedded {
+		return
+	}
+
+	pending := job.pending()
+	if len(pending) == 0 && !job.replace {
+		return
+	}
+
+	preBlocks := make([]PreBlock, len(pending))
+	for i, pb := range pending {
+		preBlocks[i] = *pb
+	}
+
+	interrupted, resuming := r.resumable[job.source.FilePath]
+
+	// Dedup decides per file hash, so every block of a file shares one action
+	if job.replace {
+		if resuming {
+			// Replacements are transactional, so an interrupted one left nothing behind
+			job.recovered = &RecoveredBatch{
+				SourceFile: job.source.FilePath,
+				BatchID:    interrupted.ID,
+				Action:     "restarted",
+				Reason:     "interrupted replacement was never committed",
+			}
+		}
+
+		result, err := replaceSource(ctx, r.writer, job.source, preBlocks)
+		if err != nil {
+			job.errors = append(job.errors, ImportError{
+				Source:  job.source,
+				Stage:   "import",
+				Message: fmt.Sprintf("Failed to replace %d blocks from changed file", len(preBlocks)),
+				Error:   err,
+			})
+			job.failed += len(preBlocks)
+			return
+		}
+		job.replaced = result.Replaced
+		job.imported = len(result.Blocks)
+		return
+	}
+
+	var count int
+	var errs []ImportError
+	if resuming {
+		count, job.recovered, errs = resumeSource(ctx, r.writer, job.source, preBlocks, interrupted)
+	} else {
+		count, errs = importSource(ctx, r.writer, job.source, preBlocks)
+	}
+	job.errors = append(job.errors, errs...)
+	job.failed += len(preBlocks) - count
+	job.imported = count
+
+	// Appended chunks are a new batch; the earlier blocks still carry the old total
+	if count == len(preBlocks) && hasAction(job.decisions, "append") {
+		r.updateChunkTotal(ctx, job, preBlocks)
+	}
+}
+
+// updateChunkTotal brings total_chunks on the blocks imported earlier from a grown
+// session log in line with the chunks just appended to it
+func (r *stageRunner) updateChunkTotal(ctx context.Context, job *sourceJob, preBlocks []PreBlock) {
+	updater, ok := r.writer.(interface {
+		UpdateSessionChunkTotal(ctx context.Context, sourceFile string, totalChunks int) error
+	})
+	if !ok {
+		return
+	}
+
+	totalChunks, _ := preBlocks[len(preBlocks)-1].Metadata["total_chunks"].(int)
+	if err := updater.UpdateSessionChunkTotal(ctx, job.source.FilePath, totalChunks); err != nil {
+		job.errors = append(job.errors, ImportError{
+			Source:  job.source,
+			Stage:   "import",
+			Message: "Failed to update total_chunks on earlier chunks of the session",
+			Error:   err,
+		})
+	}
+}
+
+// hasAction reports whether any decision has the given action
+func hasAction(decisions []ImportDecision, action string) bool {
+	for _, d := range decisions {
+		if d.Action == action {
+			return true
+		}
+	}
+	return false
+}
+
+// blockEmbeddingText is the text embedded for a block: its topic plus every question
+// (kept in step with PostgresDB.ImportBlock, which embeds the same text on its own)
+func blockEmbeddingText(pb *PreBlock) string {
+	text := pb.Topic
+	for _, ex := range pb.Exchanges {
+		text += " " + ex.Question
+	}
+	return text
+}
@@ -0,0 +1,289 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/core"
+	"github.com/TheGenXCoder/knowledge-graph/internal/db"
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeImportStore records batches and blocks written by the import stages
+type fakeImportStore struct {
+	core.KnowledgeGraph // unused methods panic
+
+	mu          sync.Mutex
+	batches     map[uuid.UUID]string // batch → source file
+	written     map[string][]PreBlock
+	completed   []string
+	embedCalls  []int
+	historyErr  error
+	failEmbedOn string
+	previous    map[string]string // source file → hash of its last completed import
+	replaced    map[string][]PreBlock
+}
+
+func newFakeImportStore() *fakeImportStore {
+	return &fakeImportStore{
+		batches:  make(map[uuid.UUID]string),
+		written:  make(map[string][]PreBlock),
+		previous: make(map[string]string),
+		replaced: make(map[string][]PreBlock),
+	}
+}
+
+func (f *fakeImportStore) QueryImportHistory(ctx context.Context, sourceFile, fileHash string) (*db.ImportHistoryRecord, error) {
+	if f.historyErr != nil {
+		return nil, f.historyErr
+	}
+	if hash, ok := f.previous[sourceFile]; ok && hash == fileHash {
+		return &db.ImportHistoryRecord{SourceFile: sourceFile, FileHash: hash, Status: "completed"}, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeImportStore) QueryLatestImport(ctx context.Context, sourceFile string) (*db.ImportHistoryRecord, error) {
+	if hash, ok := f.previous[sourceFile]; ok {
+		return &db.ImportHistoryRecord{SourceFile: sourceFile, FileHash: hash, Status: "completed"}, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeImportStore) QueryBlocksBySource(ctx context.Context, sourceFile string) ([]db.BlockSourceRecord, error) {
+	return nil, nil
+}
+
+func (f *fakeImportStore) CreateImportBatch(ctx context.Context, sourceFile, fileHash string, fileSize int64, importType, visibility, sourceClass string, orgID *uuid.UUID) (uuid.UUID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := uuid.New()
+	f.batches[id] = sourceFile
+	return id, nil
+}
+
+func (f *fakeImportStore) ImportBlock(ctx context.Context, preBlock interface{}, batchID uuid.UUID) (*types.Block, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pb := preBlock.(*PreBlock)
+	f.written[f.batches[batchID]] = append(f.written[f.batches[batchID]], *pb)
+	return &types.Block{ID: uuid.New(), Topic: pb.Topic}, nil
+}
+
+func (f *fakeImportStore) CompleteImportBatch(ctx context.Context, batchID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.completed = append(f.completed, f.batches[batchID])
+	return nil
+}
+
+func (f *fakeImportStore) FailImportBatch(ctx context.Context, batchID uuid.UUID, errorMessage string) error {
+	return nil
+}
+
+func (f *fakeImportStore) ReplaceSourceBlocks(ctx context.Context, sourceFile string, preBlocks []interface{}, batchID uuid.UUID) (*db.ReplaceResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := &db.ReplaceResult{Replaced: 2}
+	f.replaced[sourceFile] = []PreBlock{}
+	for _, item := range preBlocks {
+		pb := item.(*PreBlock)
+		f.replaced[sourceFile] = append(f.replaced[sourceFile], *pb)
+		result.Blocks = append(result.Blocks, &types.Block{ID: uuid.New(), Topic: pb.Topic})
+	}
+	return result, nil
+}
+
+func (f *fakeImportStore) Embedder() core.Embedder { return f }
+
+func (f *fakeImportStore) Embed(ctx context.Context, text string) ([]float64, error) {
+	return []float64{1}, nil
+}
+
+func (f *fakeImportStore) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	f.mu.Lock()
+	f.embedCalls = append(f.embedCalls, len(texts))
+	f.mu.Unlock()
+
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		if f.failEmbedOn != "" && text == f.failEmbedOn {
+			return nil, fmt.Errorf("embedding service unavailable")
+		}
+		vectors[i] = []float64{float64(len(text))}
+	}
+	return vectors, nil
+}
+
+// writeTestDocs creates n markdown docs and returns them as classified sources
+func writeTestDocs(t *testing.T, n int) []ImportSource {
+	dir := t.TempDir()
+	sources := make([]ImportSource, n)
+	for i := 0; i < n; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("doc-%02d.md", i))
+		content := fmt.Sprintf("# Document %d\n\n## Overview\n\nDetails for document %d.\n\n## Usage\n\nHow to use document %d.\n", i, i, i)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		sources[i] = ImportSource{
+			FilePath:   path,
+			FileType:   "doc",
+			FileHash:   fmt.Sprintf("hash-%02d", i),
+			Visibility: "org-private",
+		}
+	}
+	return sources
+}
+
+// TestRunStages_DeterministicOrder ensures jobs come back in discovery order with every file written once
+func TestRunStages_DeterministicOrder(t *testing.T) {
+	sources := writeTestDocs(t, 20)
+	store := newFakeImportStore()
+
+	opts := DefaultImportOptions()
+	opts.ParseWorkers, opts.ChunkWorkers, opts.EmbedWorkers = 4, 3, 5
+	opts.BatchSize = 3
+
+	jobs, err := runStages(context.Background(), store, sources, nil, opts, nil)
+
+	require.NoError(t, err)
+	require.Len(t, jobs, len(sources))
+	for i, job := range jobs {
+		assert.Equal(t, sources[i].FilePath, job.source.FilePath)
+		assert.NotEmpty(t, job.decisions)
+		assert.Equal(t, len(job.blocks), job.imported)
+		assert.Empty(t, job.errors)
+	}
+
+	assert.Len(t, store.completed, len(sources))
+	for _, source := range sources {
+		for _, pb := range store.written[source.FilePath] {
+			assert.NotEmpty(t, pb.Embedding, "blocks should be embedded before they reach the writer")
+		}
+	}
+	for _, size := range store.embedCalls {
+		assert.LessOrEqual(t, size, opts.BatchSize)
+	}
+}
+
+// TestRunStages_DryRunWritesNothing ensures dry-run stops after deduplication
+func TestRunStages_DryRunWritesNothing(t *testing.T) {
+	sources := writeTestDocs(t, 5)
+	store := newFakeImportStore()
+
+	opts := DefaultImportOptions()
+	opts.DryRun = true
+
+	jobs, err := runStages(context.Background(), store, sources, nil, opts, nil)
+
+	require.NoError(t, err)
+	require.Len(t, jobs, len(sources))
+	assert.Empty(t, store.written)
+	assert.Empty(t, store.embedCalls)
+	assert.Equal(t, "insert", jobs[0].decisions[0].Action)
+}
+
+// TestRunStages_EmbedFailureSkipsFile ensures a file whose embeddings fail is not written
+func TestRunStages_EmbedFailureSkipsFile(t *testing.T) {
+	sources := writeTestDocs(t, 3)
+	store := newFakeImportStore()
+
+	opts := DefaultImportOptions()
+	opts.BatchSize = 100
+
+	// Learn the first block text of file 1 so only that file fails
+	dryOpts := opts
+	dryOpts.DryRun = true
+	dryJobs, err := runStages(context.Background(), store, sources, nil, dryOpts, nil)
+	require.NoError(t, err)
+	store.failEmbedOn = blockEmbeddingText(&dryJobs[1].blocks[0])
+
+	jobs, err := runStages(context.Background(), store, sources, nil, opts, nil)
+
+	require.NoError(t, err)
+	assert.Len(t, jobs[1].errors, 1)
+	assert.Equal(t, "embed", jobs[1].errors[0].Stage)
+	assert.Equal(t, len(jobs[1].blocks), jobs[1].failed)
+	assert.NotContains(t, store.written, sources[1].FilePath)
+	assert.Contains(t, store.written, sources[0].FilePath)
+	assert.Contains(t, store.written, sources[2].FilePath)
+}
+
+// TestRunStages_FatalAndCancelled covers dedup failures and caller cancellation
+func TestRunStages_FatalAndCancelled(t *testing.T) {
+	sources := writeTestDocs(t, 10)
+
+	t.Run("dedup failure aborts", func(t *testing.T) {
+		store := newFakeImportStore()
+		store.historyErr = fmt.Errorf("connection refused")
+
+		_, err := runStages(context.Background(), store, sources, nil, DefaultImportOptions(), nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "deduplication failed")
+		assert.Empty(t, store.written)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		store := newFakeImportStore()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := runStages(ctx, store, sources, nil, DefaultImportOptions(), nil)
+
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// TestStageRunner_ChunkFailureCounted ensures a file that fails to chunk counts as failed, like a parse failure
+func TestStageRunner_ChunkFailureCounted(t *testing.T) {
+	source := ImportSource{FilePath: "notes.bin", FileType: "unknown"}
+	job := &sourceJob{source: source, doc: &ParsedDocument{Source: source}}
+
+	(&stageRunner{}).chunk(context.Background(), job)
+
+	require.Len(t, job.errors, 1)
+	assert.Equal(t, "chunk", job.errors[0].Stage)
+	assert.Equal(t, 1, job.failed)
+}
+
+// TestRunStages_ChangedFileReplaced ensures a changed file replaces its stale
+// blocks, even when it no longer yields any
+func TestRunStages_ChangedFileReplaced(t *testing.T) {
+	sources := writeTestDocs(t, 2)
+	require.NoError(t, os.WriteFile(sources[1].FilePath, nil, 0644))
+	sources[1].FileHash = "hash-emptied"
+
+	store := newFakeImportStore()
+	store.previous[sources[0].FilePath] = "hash-old"
+	store.previous[sources[1].FilePath] = "hash-old"
+
+	jobs, err := runStages(context.Background(), store, sources, nil, DefaultImportOptions(), nil)
+
+	require.NoError(t, err)
+	assert.True(t, jobs[0].replace)
+	assert.NotEmpty(t, store.replaced[sources[0].FilePath])
+	assert.Equal(t, len(jobs[0].blocks), jobs[0].imported)
+
+	require.Empty(t, jobs[1].blocks)
+	assert.True(t, jobs[1].replace)
+	assert.Contains(t, store.replaced, sources[1].FilePath, "an emptied file still replaces its stale blocks")
+	assert.Empty(t, store.replaced[sources[1].FilePath])
+	assert.Equal(t, 2, jobs[1].replaced)
+	assert.Empty(t, jobs[1].errors)
+	assert.Empty(t, store.written)
+
+	// An unchanged empty file is left alone
+	store = newFakeImportStore()
+	store.previous[sources[1].FilePath] = sources[1].FileHash
+	jobs, err = runStages(context.Background(), store, sources[1:], nil, DefaultImportOptions(), nil)
+	require.NoError(t, err)
+	assert.False(t, jobs[0].replace)
+	assert.Empty(t, store.replaced)
+}
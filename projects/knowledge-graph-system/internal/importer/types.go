@@ -8,11 +8,14 @@ import (
 type ImportSource = importtypes.ImportSource
 type ParsedDocument = importtypes.ParsedDocument
 type Section = importtypes.Section
+type Conversation = importtypes.Conversation
+type Commit = importtypes.Commit
 type PreBlock = importtypes.PreBlock
 type PreExchange = importtypes.PreExchange
 type ImportDecision = importtypes.ImportDecision
 type ImportReport = importtypes.ImportReport
 type ImportError = importtypes.ImportError
+type RecoveredBatch = importtypes.RecoveredBatch
 type ImportOptions = importtypes.ImportOptions
 
 // Re-export default options function
@@ -0,0 +1,307 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/core"
+)
+
+// WatchOptions configures Watch
+type WatchOptions struct {
+	Import ImportOptions
+
+	// Debounce is how long a file must be quiet before it is imported
+	Debounce time.Duration
+	// PollInterval is the scan interval of the polling fallback
+	PollInterval time.Duration
+	// FinalizeAfter is how long a conversation log without a closing
+	// "Session Summary" / "**Outcome:**" must be idle before it is imported
+	FinalizeAfter time.Duration
+	// ForcePolling skips inotify even where it is available
+	ForcePolling bool
+
+	// OnReport is called after every import round
+	OnReport func(report *ImportReport)
+	// OnPending is called when a conversation log is held back because it is still growing
+	OnPending func(source ImportSource)
+}
+
+// DefaultWatchOptions returns sensible defaults for watching a directory
+func DefaultWatchOptions() WatchOptions {
+	opts := DefaultImportOptions()
+	opts.ShowProgress = false
+
+	return WatchOptions{
+		Import:        opts,
+		Debounce:      2 * time.Second,
+		PollInterval:  5 * time.Second,
+		FinalizeAfter: 10 * time.Minute,
+	}
+}
+
+// changeNotifier reports paths under the watched root that may have changed
+type changeNotifier interface {
+	Changes() <-chan string
+	Errors() <-chan error
+	Close() error
+}
+
+// errInotifyUnavailable is returned where inotify cannot be used
+var errInotifyUnavailable = errors.New("inotify not available on this platform")
+
+// Watch imports matching files under opts.Import.RootDir as they change, until
+// ctx is cancelled. Existing files are imported once on start (unchanged ones
+// are skipped by dedup). Only whole milestones appended to an imported conversation
+// log are picked up later, so a log still being written is held back until it is
+// finalized.
+func Watch(ctx context.Context, kg core.KnowledgeGraph, opts WatchOptions) error {
+	if opts.Debounce <= 0 {
+		opts.Debounce = 2 * time.Second
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	if opts.FinalizeAfter <= 0 {
+		opts.FinalizeAfter = 10 * time.Minute
+	}
+	opts.Import.DryRun = false
+	opts.Import.Resume = false
+
+	notifier, err := newChangeNotifier(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer notifier.Close()
+
+	w := &watcher{
+		kg:      kg,
+		opts:    opts,
+		pending: make(map[string]time.Time),
+		growing: make(map[string]ImportSource),
+	}
+
+	// Initial pass: everything currently on disk counts as changed
+	initial, err := Discover(opts.Import)
+	if err != nil {
+		return fmt.Errorf("initial discovery failed: %w", err)
+	}
+	for _, source := range initial {
+		w.pending[source.FilePath] = time.Time{}
+	}
+
+	tick := time.NewTicker(max(opts.Debounce/2, 100*time.Millisecond))
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case path, ok := <-notifier.Changes():
+			if !ok {
+				return fmt.Errorf("file watcher stopped unexpectedly")
+			}
+			w.pending[path] = time.Now()
+		case err := <-notifier.Errors():
+			log.Printf("[WATCH] Watcher error: %v", err)
+		case now := <-tick.C:
+			if err := w.flush(ctx, now); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// watcher holds the debounce state of a Watch loop
+type watcher struct {
+	kg   core.KnowledgeGraph
+	opts WatchOptions
+
+	pending map[string]time.Time    // path → last change event
+	growing map[string]ImportSource // conversation logs waiting to be finalized
+}
+
+// flush imports every pending file that has been quiet for the debounce period,
+// plus any held-back conversation log that is now finalized
+func (w *watcher) flush(ctx context.Context, now time.Time) error {
+	var ready []ImportSource
+
+	for path, changedAt := range w.pending {
+		if now.Sub(changedAt) < w.opts.Debounce {
+			continue
+		}
+		delete(w.pending, path)
+		delete(w.growing, path)
+
+		source, err := DiscoverFile(w.opts.Import, path)
+		if err != nil {
+			log.Printf("[WATCH] Skipping %s: %v", path, err)
+			continue
+		}
+		if source == nil {
+			continue
+		}
+
+		if source.FileType == "conversation-log" && !isLogFinalized(*source, now, w.opts.FinalizeAfter) {
+			w.growing[path] = *source
+			if w.opts.OnPending != nil {
+				w.opts.OnPending(*source)
+			}
+			continue
+		}
+		ready = append(ready, *source)
+	}
+
+	for path, source := range w.growing {
+		if now.Sub(source.LastModified) >= w.opts.FinalizeAfter {
+			delete(w.growing, path)
+			w.pending[path] = time.Time{} // re-discovered on the next tick
+		}
+	}
+
+	if len(ready) == 0 {
+		return nil
+	}
+
+	report, err := ImportSources(ctx, w.kg, ready, w.opts.Import)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	if w.opts.OnReport != nil {
+		w.opts.OnReport(report)
+	}
+
+	return nil
+}
+
+// isLogFinalized reports whether a conversation log is done being written: it has
+// a closing summary/outcome, or it has not been modified for finalizeAfter
+func isLogFinalized(source ImportSource, now time.Time, finalizeAfter time.Duration) bool {
+	if now.Sub(source.LastModified) >= finalizeAfter {
+		return true
+	}
+
+	content, err := os.ReadFile(source.FilePath)
+	if err != nil {
+		return false
+	}
+	text := string(content)
+
+	return strings.Contains(text, "## Session Summary") || strings.Contains(text, "**Outcome:**")
+}
+
+// newChangeNotifier prefers inotify and falls back to polling
+func newChangeNotifier(ctx context.Context, opts WatchOptions) (changeNotifier, error) {
+	if !opts.ForcePolling {
+		notifier, err := newInotifyNotifier(opts.Import)
+		if err == nil {
+			return notifier, nil
+		}
+		log.Printf("[WATCH] inotify unavailable (%v), polling every %s", err, opts.PollInterval)
+	}
+
+	return newPollingNotifier(ctx, opts.Import, opts.PollInterval)
+}
+
+// pollingNotifier detects changes by periodically comparing size and mtime
+type pollingNotifier struct {
+	changes chan string
+	errs    chan error
+	cancel  context.CancelFunc
+}
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+func newPollingNotifier(ctx context.Context, opts ImportOptions, interval time.Duration) (*pollingNotifier, error) {
+	seen, err := scanStamps(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	p := &pollingNotifier{
+		changes: make(chan string),
+		errs:    make(chan error, 1),
+		cancel:  cancel,
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := scanStamps(opts)
+			if err != nil {
+				select {
+				case p.errs <- err:
+				default:
+				}
+				continue
+			}
+
+			for path, stamp := range current {
+				if old, ok := seen[path]; ok && old == stamp {
+					continue
+				}
+				select {
+				case p.changes <- path:
+				case <-ctx.Done():
+					return
+				}
+			}
+			seen = current
+		}
+	}()
+
+	return p, nil
+}
+
+// scanStamps records size and mtime of every file Discover would consider
+func scanStamps(opts ImportOptions) (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp)
+	err := filepath.Walk(opts.RootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, _ := filepath.Rel(opts.RootDir, path)
+		if info.IsDir() {
+			if relPath != "." && shouldExclude(relPath, opts.ExcludePattern) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !shouldExclude(relPath, opts.ExcludePattern) {
+			stamps[path] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", opts.RootDir, err)
+	}
+	return stamps, nil
+}
+
+func (p *pollingNotifier) Changes() <-chan string { return p.changes }
+func (p *pollingNotifier) Errors() <-chan error   { return p.errs }
+func (p *pollingNotifier) Close() error {
+	p.cancel()
+	return nil
+}
@@ -0,0 +1,192 @@
+//go:build linux
+
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// inotifyMask covers writes finishing, files appearing and directories being created
+const inotifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY | syscall.IN_CREATE |
+	syscall.IN_MOVED_TO | syscall.IN_DELETE_SELF
+
+// inotifyNotifier watches every non-excluded directory under the root with inotify
+type inotifyNotifier struct {
+	opts    ImportOptions
+	fd      int // raw fd for inotify_add_watch (file.Fd() would make it blocking)
+	file    *os.File
+	changes chan string
+	errs    chan error
+	done    chan struct{}
+
+	mu   sync.Mutex
+	dirs map[int32]string // watch descriptor → directory
+}
+
+func newInotifyNotifier(opts ImportOptions) (changeNotifier, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify_init1: %w", err)
+	}
+
+	n := &inotifyNotifier{
+		opts: opts,
+		fd:   fd,
+		// A non-blocking fd lets the runtime poller wake Read up when Close is called
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		changes: make(chan string),
+		errs:    make(chan error, 1),
+		done:    make(chan struct{}),
+		dirs:    make(map[int32]string),
+	}
+
+	if _, err := n.addTree(opts.RootDir); err != nil {
+		n.file.Close()
+		return nil, err
+	}
+
+	go n.readLoop()
+	return n, nil
+}
+
+// addTree adds a watch for dir and every non-excluded directory below it, and
+// returns the non-excluded files already inside. Re-adding a watched directory
+// keeps its watch.
+func (n *inotifyNotifier) addTree(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, _ := filepath.Rel(n.opts.RootDir, path)
+		if !info.IsDir() {
+			if !shouldExclude(relPath, n.opts.ExcludePattern) {
+				files = append(files, path)
+			}
+			return nil
+		}
+		if relPath != "." && shouldExclude(relPath, n.opts.ExcludePattern) {
+			return filepath.SkipDir
+		}
+
+		wd, err := syscall.InotifyAddWatch(n.fd, path, inotifyMask)
+		if err != nil {
+			// ENOSPC means fs.inotify.max_user_watches is exhausted
+			return fmt.Errorf("inotify_add_watch %s: %w", path, err)
+		}
+
+		n.mu.Lock()
+		n.dirs[int32(wd)] = path
+		n.mu.Unlock()
+		return nil
+	})
+	return files, err
+}
+
+// readLoop decodes inotify events into changed paths until the fd is closed
+func (n *inotifyNotifier) readLoop() {
+	defer close(n.changes)
+
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		count, err := n.file.Read(buf)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				n.reportError(fmt.Errorf("inotify read: %w", err))
+			}
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= count; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameBytes := buf[offset+syscall.SizeofInotifyEvent : offset+syscall.SizeofInotifyEvent+int(event.Len)]
+			offset += syscall.SizeofInotifyEvent + int(event.Len)
+
+			// The kernel dropped events: rescan the whole tree, like the polling
+			// notifier would, and let dedup skip the files that did not change
+			if event.Mask&syscall.IN_Q_OVERFLOW != 0 {
+				n.reportError(fmt.Errorf("inotify event queue overflowed, rescanning %s", n.opts.RootDir))
+				files, err := n.addTree(n.opts.RootDir)
+				if err != nil {
+					n.reportError(err)
+				}
+				if !n.send(files...) {
+					return
+				}
+				continue
+			}
+
+			n.mu.Lock()
+			dir, ok := n.dirs[event.Wd]
+			if event.Mask&syscall.IN_IGNORED != 0 {
+				delete(n.dirs, event.Wd)
+			}
+			n.mu.Unlock()
+			if !ok || event.Len == 0 {
+				continue
+			}
+
+			path := filepath.Join(dir, string(trimNul(nameBytes)))
+			if event.Mask&syscall.IN_ISDIR != 0 {
+				if event.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+					// A directory moved in (or filled before its watch was added)
+					// already holds files that will never get events of their own
+					files, err := n.addTree(path)
+					if err != nil {
+						n.reportError(err)
+					}
+					if !n.send(files...) {
+						return
+					}
+				}
+				continue
+			}
+
+			if !n.send(path) {
+				return
+			}
+		}
+	}
+}
+
+// send reports changed paths, returning false once the notifier is closed
+func (n *inotifyNotifier) send(paths ...string) bool {
+	for _, path := range paths {
+		select {
+		case n.changes <- path:
+		case <-n.done:
+			return false
+		}
+	}
+	return true
+}
+
+func (n *inotifyNotifier) reportError(err error) {
+	select {
+	case n.errs <- err:
+	default:
+	}
+}
+
+// trimNul strips the NUL padding inotify appends to names
+func trimNul(b []byte) []byte {
+	for i, c := range b {
+		if c == 0 {
+			return b[:i]
+		}
+	}
+	return b
+}
+
+func (n *inotifyNotifier) Changes() <-chan string { return n.changes }
+func (n *inotifyNotifier) Errors() <-chan error   { return n.errs }
+func (n *inotifyNotifier) Close() error {
+	close(n.done)
+	return n.file.Close()
+}
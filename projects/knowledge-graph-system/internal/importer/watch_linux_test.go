@@ -0,0 +1,101 @@
+//go:build linux
+
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInotifyNotifier_ReportsWritesInNewDirectories ensures directories created after start are watched
+func TestInotifyNotifier_ReportsWritesInNewDirectories(t *testing.T) {
+	dir := t.TempDir()
+	opts := DefaultImportOptions()
+	opts.RootDir = dir
+
+	notifier, err := newInotifyNotifier(opts)
+	if err != nil {
+		t.Skipf("inotify unavailable: %v", err)
+	}
+	defer notifier.Close()
+
+	subdir := filepath.Join(dir, "conversation-logs")
+	require.NoError(t, os.Mkdir(subdir, 0755))
+	time.Sleep(50 * time.Millisecond) // let the watcher add the new directory
+
+	logPath := filepath.Join(subdir, "session-2025-10-15-1600.md")
+	require.NoError(t, os.WriteFile(logPath, []byte("# Session\n"), 0644))
+
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case path := <-notifier.Changes():
+			if path == logPath {
+				return
+			}
+		case <-deadline:
+			t.Fatal("inotify notifier did not report the write")
+		}
+	}
+}
+
+// TestInotifyNotifier_ReportsFilesInMovedDirectories ensures files inside a
+// directory moved into the tree are reported, though they get no events of their own
+func TestInotifyNotifier_ReportsFilesInMovedDirectories(t *testing.T) {
+	dir := t.TempDir()
+	opts := DefaultImportOptions()
+	opts.RootDir = filepath.Join(dir, "watched")
+	require.NoError(t, os.Mkdir(opts.RootDir, 0755))
+
+	sessions := filepath.Join(dir, "sessions")
+	require.NoError(t, os.MkdirAll(filepath.Join(sessions, "older"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sessions, "session-2025-10-15-1600.md"), []byte("# Session\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sessions, "older", "session-2025-10-14-0900.md"), []byte("# Session\n"), 0644))
+
+	notifier, err := newInotifyNotifier(opts)
+	if err != nil {
+		t.Skipf("inotify unavailable: %v", err)
+	}
+	defer notifier.Close()
+
+	moved := filepath.Join(opts.RootDir, "sessions")
+	require.NoError(t, os.Rename(sessions, moved))
+
+	want := map[string]bool{
+		filepath.Join(moved, "session-2025-10-15-1600.md"):          true,
+		filepath.Join(moved, "older", "session-2025-10-14-0900.md"): true,
+	}
+	deadline := time.After(2 * time.Second)
+	for len(want) > 0 {
+		select {
+		case path := <-notifier.Changes():
+			delete(want, path)
+		case <-deadline:
+			t.Fatalf("inotify notifier did not report %v", want)
+		}
+	}
+}
+
+// TestInotifyNotifier_CloseStopsReader ensures Close unblocks the reader goroutine
+func TestInotifyNotifier_CloseStopsReader(t *testing.T) {
+	opts := DefaultImportOptions()
+	opts.RootDir = t.TempDir()
+
+	notifier, err := newInotifyNotifier(opts)
+	if err != nil {
+		t.Skipf("inotify unavailable: %v", err)
+	}
+	require.NoError(t, notifier.Close())
+
+	select {
+	case _, ok := <-notifier.Changes():
+		assert.False(t, ok)
+	case <-time.After(2 * time.Second):
+		t.Fatal("reader goroutine still running after Close")
+	}
+}
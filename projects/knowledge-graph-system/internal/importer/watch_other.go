@@ -0,0 +1,8 @@
+//go:build !linux
+
+package importer
+
+// newInotifyNotifier is only implemented on Linux; Watch falls back to polling
+func newInotifyNotifier(opts ImportOptions) (changeNotifier, error) {
+	return nil, errInotifyUnavailable
+}
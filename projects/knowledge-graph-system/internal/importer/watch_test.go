@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIsLogFinalized covers the summary marker and the idle timeout
+func TestIsLogFinalized(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	growing := filepath.Join(dir, "session-growing.md")
+	require.NoError(t, os.WriteFile(growing, []byte("# Session Log: 2025-10-15 - Work\n\n## 16:00 - Milestone 1\n"), 0644))
+
+	finished := filepath.Join(dir, "session-finished.md")
+	require.NoError(t, os.WriteFile(finished, []byte("# Session Log\n\n## 16:00 - Milestone 1\n\n## Session Summary\n\nDone.\n"), 0644))
+
+	assert.False(t, isLogFinalized(ImportSource{FilePath: growing, LastModified: now}, now, 10*time.Minute))
+	assert.True(t, isLogFinalized(ImportSource{FilePath: finished, LastModified: now}, now, 10*time.Minute))
+	assert.True(t, isLogFinalized(ImportSource{FilePath: growing, LastModified: now.Add(-11 * time.Minute)}, now, 10*time.Minute))
+}
+
+// TestWatcherFlush_HoldsGrowingLogs ensures unfinished conversation logs are not imported
+func TestWatcherFlush_HoldsGrowingLogs(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "session-2025-10-15-1600.md")
+	require.NoError(t, os.WriteFile(logPath, []byte("# Session Log: 2025-10-15 - Work\n\n## 16:00 - Milestone 1\n"), 0644))
+
+	opts := DefaultWatchOptions()
+	opts.Import.RootDir = dir
+	opts.Debounce = time.Second
+	opts.FinalizeAfter = time.Hour
+
+	var held []string
+	opts.OnPending = func(source ImportSource) { held = append(held, source.FilePath) }
+	opts.OnReport = func(report *ImportReport) { t.Fatalf("unexpected import of %d sources", report.SourcesFound) }
+
+	w := &watcher{
+		kg:      newFakeImportStore(),
+		opts:    opts,
+		pending: map[string]time.Time{logPath: time.Now()},
+		growing: make(map[string]ImportSource),
+	}
+
+	// Still inside the debounce window: nothing happens
+	require.NoError(t, w.flush(context.Background(), time.Now()))
+	assert.Empty(t, held)
+	assert.Contains(t, w.pending, logPath)
+
+	// Debounced but the log is still growing: held back
+	require.NoError(t, w.flush(context.Background(), time.Now().Add(2*time.Second)))
+	assert.Equal(t, []string{logPath}, held)
+	assert.Contains(t, w.growing, logPath)
+	assert.NotContains(t, w.pending, logPath)
+
+	// Idle past FinalizeAfter: queued for import on the next tick
+	require.NoError(t, w.flush(context.Background(), time.Now().Add(2*time.Hour)))
+	assert.NotContains(t, w.growing, logPath)
+	assert.Contains(t, w.pending, logPath)
+}
+
+// TestPollingNotifier_ReportsChanges ensures the polling fallback sees new and modified files
+func TestPollingNotifier_ReportsChanges(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "notes.md")
+	require.NoError(t, os.WriteFile(existing, []byte("# Notes\n"), 0644))
+
+	opts := DefaultImportOptions()
+	opts.RootDir = dir
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notifier, err := newPollingNotifier(ctx, opts, 20*time.Millisecond)
+	require.NoError(t, err)
+	defer notifier.Close()
+
+	created := filepath.Join(dir, "session-new.md")
+	require.NoError(t, os.WriteFile(created, []byte("# Session\n"), 0644))
+
+	select {
+	case path := <-notifier.Changes():
+		assert.Equal(t, created, path)
+	case <-time.After(2 * time.Second):
+		t.Fatal("polling notifier did not report the new file")
+	}
+}
@@ -1,6 +1,7 @@
 package importtypes
 
 import (
+	"runtime"
 	"time"
 
 	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
@@ -16,7 +17,7 @@ type ImportSource struct {
 	FileSize     int64
 
 	// Visibility and organization (Week 1.5+)
-	Visibility         string // "public", "org-private", "individual", "auto"
+	Visibility         string // "public", "anonymized", "org-private", "individual", "auto"
 	SourceClass        string // "public-web", "private-repo", "client-data", "personal"
 	OrganizationID     *uuid.UUID
 	ContainsPII        bool
@@ -31,6 +32,33 @@ type ParsedDocument struct {
 	Source   ImportSource
 	Metadata map[string]interface{}
 	Sections []Section
+
+	// Transcript parsers (chat exports, session transcripts) fill this instead of Sections
+	Conversations []Conversation
+
+	// The git history parser fills this instead of Sections, oldest commit first
+	Commits []Commit
+}
+
+// Commit is one non-merge commit read from a repository's history
+type Commit struct {
+	Hash        string
+	Author      string
+	AuthorEmail string
+	Time        time.Time // Author date
+	Subject     string
+	Body        string
+	Files       []string // Paths touched, relative to the repository root
+}
+
+// Conversation is one chat recovered from a transcript, with its turns already
+// paired into exchanges
+type Conversation struct {
+	ID        string // Stable across re-exports; becomes the blocks' session_id
+	Title     string
+	StartedAt time.Time
+	Exchanges []PreExchange
+	Metadata  map[string]interface{}
 }
 
 // Section represents a hierarchical section in a document
@@ -56,10 +84,14 @@ type PreBlock struct {
 	CompletedAt *time.Time
 
 	// Visibility and attribution (Week 1.5+)
-	Visibility        string // "public", "org-private", "individual"
+	Visibility        string // "public", "anonymized" (redacted copy of an org-private block), "org-private", "individual"
 	OrganizationID    *uuid.UUID
 	SourceURL         string // For attribution (web sources)
 	SourceAttribution string // Citation text
+	OwnerID           string // Importing user; the only one who sees individual blocks
+
+	// Embedding is precomputed by the embed stage; nil means embed at insert time
+	Embedding []float64
 }
 
 // Getter methods for PreBlock (for interface compatibility with ImportBlock)
@@ -76,6 +108,8 @@ func (p *PreBlock) GetVisibility() string               { return p.Visibility }
 func (p *PreBlock) GetOrganizationID() *uuid.UUID       { return p.OrganizationID }
 func (p *PreBlock) GetSourceURL() string                { return p.SourceURL }
 func (p *PreBlock) GetSourceAttribution() string        { return p.SourceAttribution }
+func (p *PreBlock) GetOwnerID() string                  { return p.OwnerID }
+func (p *PreBlock) GetEmbedding() []float64             { return p.Embedding }
 func (p *PreBlock) GetExchanges() []interface{} {
 	exchanges := make([]interface{}, len(p.Exchanges))
 	for i := range p.Exchanges {
@@ -90,6 +124,7 @@ type PreExchange struct {
 	Answer    string
 	Timestamp time.Time
 	ModelUsed string
+	Embedding []float64 // Precomputed by the embed stage; nil means embed at insert time
 }
 
 // Getter methods for PreExchange (for interface compatibility)
@@ -97,10 +132,11 @@ func (p *PreExchange) GetQuestion() string     { return p.Question }
 func (p *PreExchange) GetAnswer() string       { return p.Answer }
 func (p *PreExchange) GetTimestamp() time.Time { return p.Timestamp }
 func (p *PreExchange) GetModelUsed() string    { return p.ModelUsed }
+func (p *PreExchange) GetEmbedding() []float64 { return p.Embedding }
 
 // ImportDecision represents what to do with a PreBlock
 type ImportDecision struct {
-	Action      string // "insert", "update", "skip"
+	Action      string // "insert", "update", "append", "skip"
 	PreBlock    *PreBlock
 	ExistingID  *uuid.UUID
 	Reason      string
@@ -117,9 +153,28 @@ type ImportReport struct {
 	Decisions     []ImportDecision
 	Inserted      int
 	Updated       int
+	Appended      int // New chunks imported from session logs that grew since their last import
+	Replaced      int // Stale blocks removed when changed files were re-imported
+	Linked        int // Relationships saved between git history and session log blocks
+	Inferred      int // Relationships inferred from the imported blocks to other blocks
 	Skipped       int
 	Failed        int
 	Errors        []ImportError
+
+	// Resume mode (--resume)
+	SourcesSkipped int              // Files skipped because their import batch already completed
+	Recovered      []RecoveredBatch // Batches found in-progress from an interrupted run
+}
+
+// RecoveredBatch records how a resumed import handled a batch left "in-progress"
+type RecoveredBatch struct {
+	SourceFile      string
+	BatchID         uuid.UUID
+	Action          string // "finished", "restarted", "rolled-back"
+	BlocksKept      int    // Blocks written before the interruption and kept
+	BlocksAdded     int    // Blocks written while finishing the batch
+	BlocksDiscarded int    // Partial blocks deleted
+	Reason          string
 }
 
 // ImportError represents an error during import
@@ -134,15 +189,30 @@ type ImportError struct {
 type ImportOptions struct {
 	// File discovery
 	RootDir        string
-	FileTypes      []string // "logs", "specs", "docs", "working", "all"
+	FileTypes      []string // "logs", "specs", "docs", "working", "all", or any registered format's types
 	ExcludePattern []string
 	Recursive      bool
 
+	// Classification
+	Visibility string // "auto", "public", "anonymized", "org-private", "individual"
+	RedactPII  bool   // Replace detected personal data and secrets before embedding
+	Redact     bool   // Apply the policy's redaction rules to public blocks
+	Owner      string // User recorded as the owner of imported blocks
+
 	// Processing
 	DryRun         bool
+	Resume         bool // Skip completed files and recover batches left in-progress
 	SkipDuplicates bool
 	UpdateOnly     bool
-	BatchSize      int
+	BatchSize      int // Texts per embedding request
+
+	// Relationships inferred between the imported blocks and existing ones
+	Inference types.InferenceOptions
+
+	// Concurrency (each stage runs this many workers; the DB writer is always one)
+	ParseWorkers int
+	ChunkWorkers int
+	EmbedWorkers int
 
 	// Output
 	ShowProgress bool
@@ -156,10 +226,14 @@ func DefaultImportOptions() ImportOptions {
 		FileTypes:      []string{"all"},
 		ExcludePattern: []string{".git/*", "node_modules/*", "*.test.md"},
 		Recursive:      true,
+		Visibility:     "auto",
 		DryRun:         false,
 		SkipDuplicates: false,
 		UpdateOnly:     false,
 		BatchSize:      10,
+		ParseWorkers:   runtime.NumCPU(),
+		ChunkWorkers:   runtime.NumCPU(),
+		EmbedWorkers:   4,
 		ShowProgress:   true,
 		Verbose:        false,
 		PreviewCount:   5,
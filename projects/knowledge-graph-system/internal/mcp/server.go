@@ -17,15 +17,17 @@ import (
 // Server implements an MCP server for the Knowledge Graph
 type Server struct {
 	kg     core.KnowledgeGraph
+	caller types.Caller // Whose blocks searches and context lookups may return
 	stdin  io.Reader
 	stdout io.Writer
 	stderr io.Writer
 }
 
-// NewServer creates a new MCP server
-func NewServer(kg core.KnowledgeGraph) *Server {
+// NewServer creates a new MCP server answering on behalf of caller
+func NewServer(kg core.KnowledgeGraph, caller types.Caller) *Server {
 	return &Server{
 		kg:     kg,
+		caller: caller,
 		stdin:  os.Stdin,
 		stdout: os.Stdout,
 		stderr: os.Stderr,
@@ -183,6 +185,20 @@ func (s *Server) handleToolsList(ctx context.Context) interface{} {
 							"description": "Include N+1 related blocks",
 							"default":     false,
 						},
+						"ranking": map[string]interface{}{
+							"type":        "string",
+							"description": "How semantic and keyword matches are fused: rrf (default) or linear",
+							"enum":        []string{types.RankingRRF, types.RankingLinear},
+						},
+						"recency_half_life_days": map[string]interface{}{
+							"type":        "number",
+							"description": "Halve the relevance of blocks every this many days since creation (0 = no decay)",
+						},
+						"tags": map[string]interface{}{
+							"type":        "array",
+							"description": "Only return blocks with one of these tags, an alias of one or a narrower tag",
+							"items":       map[string]interface{}{"type": "string"},
+						},
 					},
 					"required": []string{"query"},
 				},
@@ -201,6 +217,107 @@ func (s *Server) handleToolsList(ctx context.Context) interface{} {
 					"required": []string{"block_id"},
 				},
 			},
+			{
+				"name":        "kg_traverse",
+				"description": "Walk the relationship graph several hops from a block (N+k context). Returns every block reached, nearest first, with the chain of relationships explaining how it was reached.",
+				"inputSchema": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"block_id": map[string]interface{}{
+							"type":        "string",
+							"description": "UUID of the block to start from",
+						},
+						"max_hops": map[string]interface{}{
+							"type":        "integer",
+							"description": "How many relationships away to go (default 2, at most 5)",
+							"default":     2,
+						},
+						"max_nodes": map[string]interface{}{
+							"type":        "integer",
+							"description": "Maximum number of blocks returned (default 50)",
+							"default":     50,
+						},
+						"direction": map[string]interface{}{
+							"type":        "string",
+							"description": "Which way to follow relationships",
+							"enum":        []string{types.DirectionBoth, types.DirectionOutgoing, types.DirectionIncoming},
+						},
+						"relationship_types": map[string]interface{}{
+							"type":        "array",
+							"description": "Only follow these relationship types",
+							"items":       map[string]interface{}{"type": "string", "enum": types.RelationshipTypes},
+						},
+						"tags": map[string]interface{}{
+							"type":        "array",
+							"description": "Only return blocks with one of these tags, an alias of one or a narrower tag",
+							"items":       map[string]interface{}{"type": "string"},
+						},
+					},
+					"required": []string{"block_id"},
+				},
+			},
+			{
+				"name":        "kg_list_tags",
+				"description": "List tags, most used first, with their parent (broader) tag, children (narrower tags), aliases and how many blocks use them. Pass name to look up one tag by name or alias.",
+				"inputSchema": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"name": map[string]interface{}{
+							"type":        "string",
+							"description": "Only return this tag (by name or alias)",
+						},
+						"prefix": map[string]interface{}{
+							"type":        "string",
+							"description": "Only return tags starting with this",
+						},
+						"limit": map[string]interface{}{
+							"type":        "integer",
+							"description": "Maximum number of tags (default 100)",
+							"default":     100,
+						},
+					},
+				},
+			},
+			{
+				"name":        "kg_manage_tags",
+				"description": "Clean up the tag taxonomy: rename a tag (the old name becomes an alias), merge tags into one (moving their blocks), make another name an alias of a tag, remove an alias, or place a tag under a broader parent tag. Searching by a tag includes its aliases and narrower tags.",
+				"inputSchema": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"action": map[string]interface{}{
+							"type":        "string",
+							"description": "rename (tag, new_name), merge (tags, into), alias (alias, tag), unalias (alias) or set_parent (tag, parent; no parent makes it a root)",
+							"enum":        []string{"rename", "merge", "alias", "unalias", "set_parent"},
+						},
+						"tag": map[string]interface{}{
+							"type":        "string",
+							"description": "Tag to rename, alias or place under a parent",
+						},
+						"new_name": map[string]interface{}{
+							"type":        "string",
+							"description": "New name for rename",
+						},
+						"tags": map[string]interface{}{
+							"type":        "array",
+							"description": "Tags to merge",
+							"items":       map[string]interface{}{"type": "string"},
+						},
+						"into": map[string]interface{}{
+							"type":        "string",
+							"description": "Tag to merge into (created if missing)",
+						},
+						"alias": map[string]interface{}{
+							"type":        "string",
+							"description": "Alias to add or remove",
+						},
+						"parent": map[string]interface{}{
+							"type":        "string",
+							"description": "Broader tag for set_parent",
+						},
+					},
+					"required": []string{"action"},
+				},
+			},
 		},
 	}
 }
@@ -222,6 +339,12 @@ func (s *Server) handleToolsCall(ctx context.Context, params json.RawMessage) (i
 		return s.toolSearch(ctx, callParams.Arguments)
 	case "kg_get_context":
 		return s.toolGetContext(ctx, callParams.Arguments)
+	case "kg_traverse":
+		return s.toolTraverse(ctx, callParams.Arguments)
+	case "kg_list_tags":
+		return s.toolListTags(ctx, callParams.Arguments)
+	case "kg_manage_tags":
+		return s.toolManageTags(ctx, callParams.Arguments)
 	default:
 		return nil, fmt.Errorf("unknown tool: %s", callParams.Name)
 	}
@@ -251,7 +374,7 @@ func (s *Server) toolSaveBlock(ctx context.Context, args map[string]interface{})
 		return nil, fmt.Errorf("failed to get/create project: %w", err)
 	}
 
-	// Build block
+	// Build block, owned by the caller and their organization
 	block := &types.Block{
 		ProjectID:     project.ID,
 		Topic:         topic,
@@ -259,6 +382,9 @@ func (s *Server) toolSaveBlock(ctx context.Context, args map[string]interface{})
 		CompletedAt:   timePtr(time.Now()),
 		ExchangeCount: len(exchangesRaw),
 		Metadata:      make(map[string]interface{}),
+
+		OrganizationID: s.caller.OrganizationID,
+		OwnerID:        s.caller.UserID,
 	}
 
 	// Parse exchanges
@@ -289,11 +415,17 @@ func (s *Server) toolSaveBlock(ctx context.Context, args map[string]interface{})
 		return nil, fmt.Errorf("failed to save block: %w", err)
 	}
 
+	tags := make([]string, len(block.Tags))
+	for i, tag := range block.Tags {
+		tags[i] = tag.Name
+	}
+
 	return map[string]interface{}{
 		"success":   true,
 		"block_id":  block.ID.String(),
 		"project":   project.Name,
 		"exchanges": len(block.Exchanges),
+		"tags":      tags,
 	}, nil
 }
 
@@ -306,6 +438,7 @@ func (s *Server) toolSearch(ctx context.Context, args map[string]interface{}) (i
 	opts := types.SearchOptions{
 		Limit:        10,
 		IncludeNPlus: false,
+		Caller:       s.caller,
 	}
 
 	if limit, ok := args["limit"].(float64); ok {
@@ -316,6 +449,16 @@ func (s *Server) toolSearch(ctx context.Context, args map[string]interface{}) (i
 		opts.IncludeNPlus = includeNPlus
 	}
 
+	if ranking, ok := args["ranking"].(string); ok {
+		opts.Ranking.Strategy = ranking
+	}
+
+	if halfLife, ok := args["recency_half_life_days"].(float64); ok {
+		opts.Ranking.RecencyHalfLifeDays = halfLife
+	}
+
+	opts.Tags = stringList(args["tags"])
+
 	results, err := s.kg.Search(ctx, query, opts)
 	if err != nil {
 		return nil, fmt.Errorf("search failed: %w", err)
@@ -334,10 +477,21 @@ func (s *Server) toolSearch(ctx context.Context, args map[string]interface{}) (i
 			}
 		}
 
+		matches := make([]map[string]interface{}, len(result.Matches))
+		for j, m := range result.Matches {
+			matches[j] = map[string]interface{}{
+				"sequence":         m.Sequence,
+				"question_snippet": m.QuestionSnippet,
+				"answer_snippet":   m.AnswerSnippet,
+			}
+		}
+
 		formattedResults[i] = map[string]interface{}{
 			"block_id":  result.Block.ID.String(),
 			"topic":     result.Block.Topic,
 			"relevance": result.Relevance,
+			"scores":    result.Scores,
+			"matches":   matches,
 			"created":   result.Block.CreatedAt.Format(time.RFC3339),
 			"exchanges": exchanges,
 		}
@@ -361,19 +515,209 @@ func (s *Server) toolGetContext(ctx context.Context, args map[string]interface{}
 		return nil, fmt.Errorf("invalid block_id: %w", err)
 	}
 
-	bundle, err := s.kg.GetContextNPlusOne(ctx, blockID)
+	bundle, err := s.kg.GetContextNPlusOne(ctx, blockID, s.caller)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get context: %w", err)
 	}
 
+	edges := make([]map[string]interface{}, len(bundle.Edges))
+	for i, edge := range bundle.Edges {
+		edges[i] = map[string]interface{}{
+			"type":       edge.RelationshipType,
+			"direction":  edge.Direction,
+			"confidence": edge.Confidence,
+			"block_id":   edge.Block.ID.String(),
+			"topic":      edge.Block.Topic,
+		}
+	}
+
 	// Format response
 	return map[string]interface{}{
-		"primary_block": formatBlock(bundle.PrimaryBlock),
+		"primary_block":  formatBlock(bundle.PrimaryBlock),
 		"related_blocks": formatBlocks(bundle.RelatedBlocks),
-		"tags":          formatTags(bundle.Tags),
+		"relationships":  edges,
+		"tags":           formatTags(bundle.Tags),
+	}, nil
+}
+
+func (s *Server) toolTraverse(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	blockIDStr, ok := args["block_id"].(string)
+	if !ok {
+		return nil, fmt.Errorf("block_id is required")
+	}
+
+	blockID, err := uuid.Parse(blockIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block_id: %w", err)
+	}
+
+	opts := types.TraversalOptions{
+		RelationshipTypes: stringList(args["relationship_types"]),
+		Tags:              stringList(args["tags"]),
+		Caller:            s.caller,
+	}
+	if hops, ok := args["max_hops"].(float64); ok {
+		opts.MaxHops = int(hops)
+	}
+	if nodes, ok := args["max_nodes"].(float64); ok {
+		opts.MaxNodes = int(nodes)
+	}
+	if direction, ok := args["direction"].(string); ok {
+		opts.Direction = direction
+	}
+
+	result, err := s.kg.GetContextNPlusK(ctx, blockID, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to traverse: %w", err)
+	}
+
+	nodes := make([]map[string]interface{}, len(result.Nodes))
+	for i, node := range result.Nodes {
+		path := make([]map[string]interface{}, len(node.Path))
+		for j, rel := range node.Path {
+			path[j] = map[string]interface{}{
+				"from_block_id": rel.FromBlockID.String(),
+				"to_block_id":   rel.ToBlockID.String(),
+				"type":          rel.RelationshipType,
+				"confidence":    rel.Confidence,
+			}
+		}
+
+		nodes[i] = map[string]interface{}{
+			"block_id":   node.Block.ID.String(),
+			"topic":      node.Block.Topic,
+			"depth":      node.Depth,
+			"confidence": node.Confidence,
+			"path":       path,
+		}
+	}
+
+	return map[string]interface{}{
+		"root":      formatBlock(result.Root),
+		"nodes":     nodes,
+		"truncated": result.Truncated,
 	}, nil
 }
 
+func (s *Server) toolListTags(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	if name, ok := args["name"].(string); ok && name != "" {
+		tag, err := s.kg.GetTag(ctx, name, s.caller)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tag: %w", err)
+		}
+		return map[string]interface{}{
+			"tags": []map[string]interface{}{formatTagInfo(*tag)},
+		}, nil
+	}
+
+	opts := types.TagListOptions{Caller: s.caller}
+	if prefix, ok := args["prefix"].(string); ok {
+		opts.Prefix = prefix
+	}
+	if limit, ok := args["limit"].(float64); ok {
+		opts.Limit = int(limit)
+	}
+
+	tags, err := s.kg.ListTags(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	formatted := make([]map[string]interface{}, len(tags))
+	for i, tag := range tags {
+		formatted[i] = formatTagInfo(tag)
+	}
+
+	return map[string]interface{}{
+		"tags": formatted,
+	}, nil
+}
+
+func (s *Server) toolManageTags(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	action, ok := args["action"].(string)
+	if !ok {
+		return nil, fmt.Errorf("action is required")
+	}
+	arg := func(name string) string {
+		value, _ := args[name].(string)
+		return value
+	}
+
+	// The tag whose resulting state is returned
+	var tag string
+	result := map[string]interface{}{"action": action}
+
+	switch action {
+	case "rename":
+		if arg("tag") == "" || arg("new_name") == "" {
+			return nil, fmt.Errorf("rename needs tag and new_name")
+		}
+		if err := s.kg.RenameTag(ctx, arg("tag"), arg("new_name"), s.caller); err != nil {
+			return nil, fmt.Errorf("failed to rename tag: %w", err)
+		}
+		tag = arg("new_name")
+	case "merge":
+		tags := stringList(args["tags"])
+		if len(tags) == 0 || arg("into") == "" {
+			return nil, fmt.Errorf("merge needs tags and into")
+		}
+		merge, err := s.kg.MergeTags(ctx, tags, arg("into"), s.caller)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge tags: %w", err)
+		}
+		result["merged"] = merge.Merged
+		result["blocks_relinked"] = merge.BlocksRelinked
+		tag = merge.Target.Name
+	case "alias":
+		if arg("alias") == "" || arg("tag") == "" {
+			return nil, fmt.Errorf("alias needs alias and tag")
+		}
+		if err := s.kg.AddTagAlias(ctx, arg("alias"), arg("tag"), s.caller); err != nil {
+			return nil, fmt.Errorf("failed to add alias: %w", err)
+		}
+		tag = arg("tag")
+	case "unalias":
+		if arg("alias") == "" {
+			return nil, fmt.Errorf("unalias needs alias")
+		}
+		if err := s.kg.RemoveTagAlias(ctx, arg("alias"), s.caller); err != nil {
+			return nil, fmt.Errorf("failed to remove alias: %w", err)
+		}
+		result["removed"] = arg("alias")
+		return result, nil
+	case "set_parent":
+		if arg("tag") == "" {
+			return nil, fmt.Errorf("set_parent needs tag")
+		}
+		if err := s.kg.SetTagParent(ctx, arg("tag"), arg("parent"), s.caller); err != nil {
+			return nil, fmt.Errorf("failed to set parent: %w", err)
+		}
+		tag = arg("tag")
+	default:
+		return nil, fmt.Errorf("unknown action: %s", action)
+	}
+
+	info, err := s.kg.GetTag(ctx, tag, s.caller)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	result["tag"] = formatTagInfo(*info)
+
+	return result, nil
+}
+
+// stringList converts a JSON array argument to strings, skipping non-strings
+func stringList(value interface{}) []string {
+	items, _ := value.([]interface{})
+	var list []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
 func formatBlock(block *types.Block) map[string]interface{} {
 	if block == nil {
 		return nil
@@ -390,9 +734,9 @@ func formatBlock(block *types.Block) map[string]interface{} {
 	}
 
 	return map[string]interface{}{
-		"id":       block.ID.String(),
-		"topic":    block.Topic,
-		"created":  block.CreatedAt.Format(time.RFC3339),
+		"id":        block.ID.String(),
+		"topic":     block.Topic,
+		"created":   block.CreatedAt.Format(time.RFC3339),
 		"exchanges": exchanges,
 	}
 }
@@ -416,6 +760,16 @@ func formatTags(tags []types.Tag) []map[string]interface{} {
 	return result
 }
 
+func formatTagInfo(tag types.TagInfo) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        tag.Name,
+		"parent":      tag.Parent,
+		"children":    tag.Children,
+		"aliases":     tag.Aliases,
+		"usage_count": tag.UsageCount,
+	}
+}
+
 func (s *Server) logError(format string, args ...interface{}) {
 	fmt.Fprintf(s.stderr, "[ERROR] "+format+"\n", args...)
 }
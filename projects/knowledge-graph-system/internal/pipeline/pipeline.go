@@ -184,23 +184,23 @@ func (p *Pipeline) GetImportHistory(ctx context.Context, limit int) ([]ImportHis
 
 // ImportStats represents import statistics
 type ImportStats struct {
-	TotalImports  int
-	TotalBlocks   int
-	LastImport    time.Time
-	ByType        map[string]int
-	ByVisibility  map[string]int
+	TotalImports int            `json:"total_imports"`
+	TotalBlocks  int            `json:"total_blocks"`
+	LastImport   time.Time      `json:"last_import"`
+	ByType       map[string]int `json:"by_type"`
+	ByVisibility map[string]int `json:"by_visibility"`
 }
 
 // ImportHistoryEntry represents a single import record
 type ImportHistoryEntry struct {
-	ID                  uuid.UUID
-	SourceFile          string
-	FileHash            string
-	ImportedAt          time.Time
-	BlockCount          int
-	ImportType          string
-	Status              string
-	Visibility          string
-	SourceClassification string
-	OrganizationID      *uuid.UUID
+	ID                   uuid.UUID  `json:"id"`
+	SourceFile           string     `json:"source_file"`
+	FileHash             string     `json:"file_hash"`
+	ImportedAt           time.Time  `json:"imported_at"`
+	BlockCount           int        `json:"block_count"`
+	ImportType           string     `json:"import_type"`
+	Status               string     `json:"status"`
+	Visibility           string     `json:"visibility"`
+	SourceClassification string     `json:"source_classification,omitempty"`
+	OrganizationID       *uuid.UUID `json:"organization_id,omitempty"`
 }
@@ -0,0 +1,162 @@
+package tagging
+
+import (
+	"context"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+)
+
+// technicalTerms are always worth a tag when content mentions them
+var technicalTerms = []string{
+	"api", "database", "frontend", "backend", "deployment", "testing",
+	"authentication", "authorization", "cache", "queue", "migration",
+	"docker", "kubernetes", "postgres", "redis", "nginx", "react",
+	"golang", "python", "typescript", "javascript", "sql",
+}
+
+// Keywords does simple keyword extraction from text: the technical terms it mentions
+func Keywords(text string) []string {
+	text = strings.ToLower(text)
+
+	keywords := []string{}
+	for _, term := range technicalTerms {
+		if strings.Contains(text, term) {
+			keywords = append(keywords, term)
+		}
+	}
+
+	return keywords
+}
+
+// Corpus reports how many stored documents contain each term, so terms common
+// to everything weigh less (IDF)
+type Corpus interface {
+	DocumentFrequencies(ctx context.Context, terms []string) (frequencies map[string]int, documents int, err error)
+}
+
+// Keyword scoring
+const (
+	technicalTermBoost   = 2   // Score multiplier for Keywords matches
+	minTermCount         = 2   // Other words must occur this often to be considered
+	maxKeywordConfidence = 0.8 // Confidence of the best keyword; keywords are never certain
+	minTermLength        = 3
+)
+
+// termPattern matches candidate terms: words with optional inner dots or
+// dashes and trailing + or # (node.js, ci-cd, c++)
+var termPattern = regexp.MustCompile(`[a-z][a-z0-9]*(?:[.-][a-z0-9]+)*[+#]*`)
+
+// stopWords are never tags
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`
+		about above after again against all also and any are because been before being below
+		between both but can could did does doing done down during each few for from further
+		get gets got had has have having her here hers him his how however into its itself
+		just let like make makes made many may more most much must need needs not now off once
+		only other our out over own same should since some such than that the their them then
+		there these they this those through too under until use used uses using very was way
+		well were what when where which while who whom why will with within without would yes
+		you your want wants know think thing things something really actually maybe still even
+		one two three first next last new old good better best right sure okay thanks please
+		question answer example look looks work works working file files line lines code`) {
+		stopWords[w] = true
+	}
+}
+
+// KeywordTagger tags content deterministically by TF-IDF: how often a term
+// occurs in the content, weighted down by how many stored documents contain
+// it. Technical terms found by Keywords are boosted.
+type KeywordTagger struct {
+	Corpus  Corpus // Document frequencies for IDF (nil: term frequency only)
+	MaxTags int    // Tags returned (default DefaultMaxTags)
+}
+
+// ExtractTags implements core.TagExtractor
+func (k *KeywordTagger) ExtractTags(ctx context.Context, content string) ([]types.ExtractedTag, error) {
+	counts, total := termCounts(content)
+	if total == 0 {
+		return nil, nil
+	}
+
+	technical := make(map[string]bool)
+	for _, term := range Keywords(content) {
+		technical[term] = true
+		if counts[term] == 0 {
+			counts[term] = 1 // Matched inside a longer word (postgresql)
+		}
+	}
+
+	var terms []string
+	for term, count := range counts {
+		if technical[term] || count >= minTermCount {
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	sort.Strings(terms)
+
+	var frequencies map[string]int
+	var documents int
+	if k.Corpus != nil {
+		var err error
+		if frequencies, documents, err = k.Corpus.DocumentFrequencies(ctx, terms); err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			log.Printf("[TAGS] scoring keywords without document frequencies: %v", err)
+			frequencies, documents = nil, 0
+		}
+	}
+
+	scores := make([]types.ExtractedTag, 0, len(terms))
+	var best float64
+	for _, term := range terms {
+		score := tfidf(counts[term], total, frequencies[term], documents)
+		if technical[term] {
+			score *= technicalTermBoost
+		}
+		best = max(best, score)
+		scores = append(scores, types.ExtractedTag{Name: term, Confidence: score})
+	}
+	for i := range scores {
+		scores[i].Confidence = math.Round(scores[i].Confidence/best*maxKeywordConfidence*100) / 100
+	}
+
+	maxTags := k.MaxTags
+	if maxTags <= 0 {
+		maxTags = DefaultMaxTags
+	}
+	return Normalize(scores, nil, maxTags), nil
+}
+
+// termCounts counts the candidate terms of content and all terms seen
+func termCounts(content string) (map[string]int, int) {
+	counts := make(map[string]int)
+	total := 0
+	for _, term := range termPattern.FindAllString(strings.ToLower(content), -1) {
+		total++
+		if len(term) < minTermLength || stopWords[term] {
+			continue
+		}
+		counts[term]++
+	}
+	return counts, total
+}
+
+// tfidf scores a term occurring count times among total terms, found in df of
+// documents stored documents (smoothed, so unseen terms weigh most; with no
+// corpus every term has the same IDF)
+func tfidf(count, total, df, documents int) float64 {
+	tf := float64(count) / float64(total)
+	idf := math.Log(float64(1+documents)/float64(1+df)) + 1
+	return tf * idf
+}
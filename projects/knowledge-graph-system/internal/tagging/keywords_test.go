@@ -0,0 +1,60 @@
+package tagging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedCorpus reports fixed document frequencies
+type fixedCorpus struct {
+	frequencies map[string]int
+	documents   int
+}
+
+func (c fixedCorpus) DocumentFrequencies(ctx context.Context, terms []string) (map[string]int, int, error) {
+	return c.frequencies, c.documents, nil
+}
+
+const vacuumNotes = `Autovacuum keeps falling behind on the events table. We tuned autovacuum
+thresholds and moved events to a faster tablespace. Since events are
+append-only, autovacuum mostly freezes rows. Postgres does the rest.`
+
+func TestKeywords(t *testing.T) {
+	assert.Equal(t, []string{"database", "postgres", "sql"}, Keywords("Our PostgreSQL database"))
+	assert.Empty(t, Keywords("nothing technical here"))
+}
+
+func TestKeywordTagger(t *testing.T) {
+	ctx := context.Background()
+
+	tags, err := (&KeywordTagger{}).ExtractTags(ctx, vacuumNotes)
+	require.NoError(t, err)
+	require.NotEmpty(t, tags)
+	assert.Equal(t, "autovacuum", tags[0].Name, "most frequent term first")
+	assert.Equal(t, 0.8, tags[0].Confidence)
+
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+		assert.LessOrEqual(t, tag.Confidence, 0.8)
+	}
+	assert.Contains(t, names, "postgres", "technical terms are kept even when mentioned once")
+	assert.NotContains(t, names, "the", "stop words are never tags")
+	assert.NotContains(t, names, "table", "other words must occur more than once")
+
+	t.Run("common terms weigh less", func(t *testing.T) {
+		corpus := fixedCorpus{frequencies: map[string]int{"autovacuum": 900, "events": 1}, documents: 1000}
+		tags, err := (&KeywordTagger{Corpus: corpus}).ExtractTags(ctx, vacuumNotes)
+		require.NoError(t, err)
+		assert.Equal(t, "events", tags[0].Name)
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		again, err := (&KeywordTagger{}).ExtractTags(ctx, vacuumNotes)
+		require.NoError(t, err)
+		assert.Equal(t, tags, again)
+	})
+}
@@ -0,0 +1,161 @@
+package tagging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+)
+
+// maxPromptContent bounds the content sent to the model (in bytes); tags
+// depend on what a text is about, which its start shows
+const maxPromptContent = 6000
+
+// defaultLLMConfidence is given to tags the model lists without a confidence
+const defaultLLMConfidence = 0.8
+
+// tagPrompt instructs the model to answer with a JSON tag list
+const tagPrompt = `You tag technical notes for a knowledge base.
+Reply with JSON only, in the form {"tags": [{"name": "postgres", "confidence": 0.9}]}.
+Give 3 to 8 short lowercase tags for the technologies, components, concepts and
+activities the text is about. Confidence (0 to 1) is how central the tag is to
+the text. Do not use generic tags such as "code", "question" or "discussion".`
+
+// OllamaTagger asks an Ollama chat model for tags
+type OllamaTagger struct {
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+// NewOllamaTagger creates a tagger using an Ollama chat model
+func NewOllamaTagger(baseURL, model string) *OllamaTagger {
+	if baseURL == "" {
+		baseURL = "http://localhost:11434"
+	}
+	if model == "" {
+		model = "llama3.2"
+	}
+
+	return &OllamaTagger{
+		baseURL: baseURL,
+		model:   model,
+		client:  &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model    string                 `json:"model"`
+	Messages []chatMessage          `json:"messages"`
+	Format   string                 `json:"format"`
+	Stream   bool                   `json:"stream"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+}
+
+type chatResponse struct {
+	Message chatMessage `json:"message"`
+}
+
+// ExtractTags implements core.TagExtractor. Tags are returned as the model
+// gave them; Extractor normalizes them.
+func (o *OllamaTagger) ExtractTags(ctx context.Context, content string) ([]types.ExtractedTag, error) {
+	if len(content) > maxPromptContent {
+		content = strings.ToValidUTF8(content[:maxPromptContent], "")
+	}
+
+	req := chatRequest{
+		Model: o.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: tagPrompt},
+			{Role: "user", Content: content},
+		},
+		Format:  "json",
+		Stream:  false,
+		Options: map[string]interface{}{"temperature": 0},
+	}
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", o.baseURL+"/api/chat", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var chatResp chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return parseTagList(chatResp.Message.Content)
+}
+
+// parseTagList reads the model's answer: {"tags": [...]} or a bare list, whose
+// items are {"name", "confidence"} objects or plain strings
+func parseTagList(answer string) ([]types.ExtractedTag, error) {
+	var wrapped struct {
+		Tags json.RawMessage `json:"tags"`
+	}
+	list := json.RawMessage(answer)
+	if err := json.Unmarshal(list, &wrapped); err == nil && wrapped.Tags != nil {
+		list = wrapped.Tags
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(list, &items); err != nil {
+		return nil, fmt.Errorf("model did not return a tag list: %q", answer)
+	}
+
+	var tags []types.ExtractedTag
+	for _, item := range items {
+		var name string
+		if err := json.Unmarshal(item, &name); err == nil {
+			tags = append(tags, types.ExtractedTag{Name: name, Confidence: defaultLLMConfidence})
+			continue
+		}
+
+		var tag struct {
+			Name       string   `json:"name"`
+			Tag        string   `json:"tag"`
+			Confidence *float64 `json:"confidence"`
+		}
+		if err := json.Unmarshal(item, &tag); err != nil {
+			continue
+		}
+		if tag.Name == "" {
+			tag.Name = tag.Tag
+		}
+		confidence := defaultLLMConfidence
+		if tag.Confidence != nil {
+			confidence = *tag.Confidence
+		}
+		tags = append(tags, types.ExtractedTag{Name: tag.Name, Confidence: confidence})
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,62 @@
+package tagging
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOllamaTagger(t *testing.T) {
+	var received chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		json.NewEncoder(w).Encode(chatResponse{Message: chatMessage{
+			Role:    "assistant",
+			Content: `{"tags": [{"name": "pgvector", "confidence": 0.95}, {"name": "HNSW index", "confidence": 0.7}]}`,
+		}})
+	}))
+	defer server.Close()
+
+	tags, err := NewOllamaTagger(server.URL, "llama3.2").ExtractTags(context.Background(), "Which pgvector index should I use?")
+	require.NoError(t, err)
+	assert.Equal(t, []types.ExtractedTag{
+		{Name: "pgvector", Confidence: 0.95},
+		{Name: "HNSW index", Confidence: 0.7},
+	}, tags)
+
+	assert.Equal(t, "llama3.2", received.Model)
+	assert.Equal(t, "json", received.Format)
+	assert.False(t, received.Stream)
+	require.Len(t, received.Messages, 2)
+	assert.Equal(t, "Which pgvector index should I use?", received.Messages[1].Content)
+}
+
+func TestOllamaTagger_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `model "llama3.2" not found`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := NewOllamaTagger(server.URL, "").ExtractTags(context.Background(), "anything")
+	assert.ErrorContains(t, err, "status 404")
+}
+
+func TestParseTagList(t *testing.T) {
+	tags, err := parseTagList(`["redis", {"tag": "eviction"}, {"name": "lru", "confidence": 0.4}, 7]`)
+	require.NoError(t, err)
+	assert.Equal(t, []types.ExtractedTag{
+		{Name: "redis", Confidence: defaultLLMConfidence},
+		{Name: "eviction", Confidence: defaultLLMConfidence},
+		{Name: "lru", Confidence: 0.4},
+	}, tags)
+
+	_, err = parseTagList(`Sure! Here are some tags: redis, lru`)
+	assert.Error(t, err)
+}
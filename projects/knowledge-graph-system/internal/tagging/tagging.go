@@ -0,0 +1,166 @@
+package tagging
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/TheGenXCoder/knowledge-graph/internal/core"
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+)
+
+// DefaultMaxTags caps the tags kept per piece of content
+const DefaultMaxTags = 8
+
+// maxTagLength drops "tags" that are really sentences
+const maxTagLength = 50
+
+// DefaultSynonyms maps common variants to the tag they are merged into
+var DefaultSynonyms = map[string]string{
+	"postgresql":  "postgres",
+	"pg":          "postgres",
+	"psql":        "postgres",
+	"k8s":         "kubernetes",
+	"kube":        "kubernetes",
+	"js":          "javascript",
+	"ts":          "typescript",
+	"go":          "golang",
+	"py":          "python",
+	"db":          "database",
+	"databases":   "database",
+	"test":        "testing",
+	"tests":       "testing",
+	"unit-tests":  "testing",
+	"auth":        "authentication",
+	"authn":       "authentication",
+	"authz":       "authorization",
+	"deploy":      "deployment",
+	"deployments": "deployment",
+	"migrations":  "migration",
+	"caching":     "cache",
+	"apis":        "api",
+	"reactjs":     "react",
+	"react.js":    "react",
+	"node":        "nodejs",
+	"node.js":     "nodejs",
+	"embedding":   "embeddings",
+	"llms":        "llm",
+}
+
+// Extractor tags content with Primary, falling back to Fallback when Primary
+// fails or finds nothing, then normalizes and merges the tags
+type Extractor struct {
+	Primary  core.TagExtractor // Usually an LLM (nil: Fallback only)
+	Fallback core.TagExtractor // Deterministic extractor, used when Primary cannot help
+	Synonyms map[string]string // Variant -> canonical tag (nil: DefaultSynonyms)
+	MaxTags  int               // Tags kept (default DefaultMaxTags)
+}
+
+// NewExtractor tags content with an Ollama chat model, falling back to keyword
+// extraction weighted by corpus (may be nil). model "none" skips the LLM.
+func NewExtractor(ollamaURL, model string, corpus Corpus) *Extractor {
+	e := &Extractor{Fallback: &KeywordTagger{Corpus: corpus}}
+	if model != "none" {
+		e.Primary = NewOllamaTagger(ollamaURL, model)
+	}
+	return e
+}
+
+// ExtractTags implements core.TagExtractor
+func (e *Extractor) ExtractTags(ctx context.Context, content string) ([]types.ExtractedTag, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, nil
+	}
+
+	synonyms := e.Synonyms
+	if synonyms == nil {
+		synonyms = DefaultSynonyms
+	}
+	maxTags := e.MaxTags
+	if maxTags <= 0 {
+		maxTags = DefaultMaxTags
+	}
+
+	if e.Primary != nil {
+		tags, err := e.Primary.ExtractTags(ctx, content)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			log.Printf("[TAGS] falling back to keywords: %v", err)
+		}
+		if tags = Normalize(tags, synonyms, maxTags); len(tags) > 0 {
+			return tags, nil
+		}
+	}
+
+	if e.Fallback == nil {
+		return nil, nil
+	}
+	tags, err := e.Fallback.ExtractTags(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+	return Normalize(tags, synonyms, maxTags), nil
+}
+
+var (
+	tagSeparators = regexp.MustCompile(`[\s_/]+`)
+	tagInvalid    = regexp.MustCompile(`[^a-z0-9+#.-]`)
+	tagDashes     = regexp.MustCompile(`-{2,}`)
+)
+
+// NormalizeTag turns a free-form tag into its canonical form: lowercase words
+// joined by dashes, without punctuation other than + # . (c++, c#, node.js),
+// mapped through synonyms. It returns "" for a tag that should be dropped.
+func NormalizeTag(tag string, synonyms map[string]string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	tag = strings.TrimLeft(tag, "#")
+	tag = tagSeparators.ReplaceAllString(tag, "-")
+	tag = tagInvalid.ReplaceAllString(tag, "")
+	tag = tagDashes.ReplaceAllString(tag, "-")
+	tag = strings.Trim(tag, "-.")
+
+	if canonical, ok := synonyms[tag]; ok {
+		tag = canonical
+	}
+	if tag == "" || len(tag) > maxTagLength {
+		return ""
+	}
+	return tag
+}
+
+// Normalize canonicalizes tags, merges duplicates (keeping the highest
+// confidence, clamped to 0-1) and returns the maxTags most confident, ties
+// broken by name
+func Normalize(tags []types.ExtractedTag, synonyms map[string]string, maxTags int) []types.ExtractedTag {
+	index := make(map[string]int)
+	var merged []types.ExtractedTag
+	for _, tag := range tags {
+		name := NormalizeTag(tag.Name, synonyms)
+		if name == "" {
+			continue
+		}
+		confidence := min(max(tag.Confidence, 0), 1)
+
+		if i, ok := index[name]; ok {
+			merged[i].Confidence = max(merged[i].Confidence, confidence)
+			continue
+		}
+		index[name] = len(merged)
+		merged = append(merged, types.ExtractedTag{Name: name, Confidence: confidence})
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Confidence != merged[j].Confidence {
+			return merged[i].Confidence > merged[j].Confidence
+		}
+		return merged[i].Name < merged[j].Name
+	})
+	if maxTags > 0 && len(merged) > maxTags {
+		merged = merged[:maxTags]
+	}
+	return merged
+}
@@ -0,0 +1,101 @@
+package tagging
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TheGenXCoder/knowledge-graph/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubTagger returns fixed tags or an error
+type stubTagger struct {
+	tags  []types.ExtractedTag
+	err   error
+	calls int
+}
+
+func (s *stubTagger) ExtractTags(ctx context.Context, content string) ([]types.ExtractedTag, error) {
+	s.calls++
+	return s.tags, s.err
+}
+
+func TestNormalizeTag(t *testing.T) {
+	cases := map[string]string{
+		"PostgreSQL":       "postgres",
+		"  #Kubernetes ":   "kubernetes",
+		"k8s":              "kubernetes",
+		"Vector Search":    "vector-search",
+		"ci/cd":            "ci-cd",
+		"rate_limiting":    "rate-limiting",
+		"Node.js":          "nodejs",
+		"C++":              "c++",
+		"error handling!!": "error-handling",
+		"--":               "",
+		"this is not a tag but a whole sentence the model wrote": "",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, NormalizeTag(in, DefaultSynonyms), in)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tags := Normalize([]types.ExtractedTag{
+		{Name: "PostgreSQL", Confidence: 0.6},
+		{Name: "postgres", Confidence: 0.9},
+		{Name: "pg", Confidence: 0.3},
+		{Name: "Docker", Confidence: 1.4},
+		{Name: "", Confidence: 0.9},
+		{Name: "backups", Confidence: 0.6},
+		{Name: "api", Confidence: 0.6},
+	}, DefaultSynonyms, 3)
+
+	assert.Equal(t, []types.ExtractedTag{
+		{Name: "docker", Confidence: 1},
+		{Name: "postgres", Confidence: 0.9},
+		{Name: "api", Confidence: 0.6},
+	}, tags, "synonyms merged keeping the best confidence, clamped, capped, ties by name")
+}
+
+func TestExtractor(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("primary tags are normalized", func(t *testing.T) {
+		primary := &stubTagger{tags: []types.ExtractedTag{{Name: "PostgreSQL", Confidence: 0.9}}}
+		fallback := &stubTagger{tags: []types.ExtractedTag{{Name: "keyword", Confidence: 0.5}}}
+
+		tags, err := (&Extractor{Primary: primary, Fallback: fallback}).ExtractTags(ctx, "How do I tune PostgreSQL?")
+		require.NoError(t, err)
+		assert.Equal(t, []types.ExtractedTag{{Name: "postgres", Confidence: 0.9}}, tags)
+		assert.Zero(t, fallback.calls)
+	})
+
+	t.Run("falls back when the primary fails or finds nothing", func(t *testing.T) {
+		fallback := &stubTagger{tags: []types.ExtractedTag{{Name: "redis", Confidence: 0.5}}}
+		for _, primary := range []*stubTagger{
+			{err: errors.New("model not found")},
+			{tags: []types.ExtractedTag{{Name: "!!!", Confidence: 0.9}}},
+		} {
+			tags, err := (&Extractor{Primary: primary, Fallback: fallback}).ExtractTags(ctx, "redis eviction")
+			require.NoError(t, err)
+			assert.Equal(t, []types.ExtractedTag{{Name: "redis", Confidence: 0.5}}, tags)
+		}
+	})
+
+	t.Run("custom synonyms", func(t *testing.T) {
+		primary := &stubTagger{tags: []types.ExtractedTag{{Name: "pg", Confidence: 0.7}}}
+		tags, err := (&Extractor{Primary: primary, Synonyms: map[string]string{}}).ExtractTags(ctx, "pg")
+		require.NoError(t, err)
+		assert.Equal(t, "pg", tags[0].Name)
+	})
+
+	t.Run("empty content", func(t *testing.T) {
+		primary := &stubTagger{}
+		tags, err := (&Extractor{Primary: primary}).ExtractTags(ctx, "   ")
+		require.NoError(t, err)
+		assert.Empty(t, tags)
+		assert.Zero(t, primary.calls)
+	})
+}
@@ -0,0 +1,63 @@
+package types
+
+import "fmt"
+
+// Inference signals for InferenceOptions.Signals
+const (
+	InferSimilarity   = "similarity"   // Embedding similarity to other blocks (related-to)
+	InferSharedFiles  = "shared-files" // Overlap of the files two sessions modified (related-to)
+	InferContinuation = "continuation" // The next chunk of the same session (continues)
+	InferReferences   = "references"   // Block IDs mentioned in a block's text (references)
+)
+
+// InferenceSignals lists the valid inference signals
+var InferenceSignals = []string{InferSimilarity, InferSharedFiles, InferContinuation, InferReferences}
+
+// InferenceOptions configures how relationships are inferred for new blocks.
+// The zero value uses every signal with the default thresholds.
+type InferenceOptions struct {
+	Disabled             bool     `json:"disabled,omitempty"`               // Infer nothing
+	Signals              []string `json:"signals,omitempty"`                // Signals to use (empty = all)
+	SimilarityThreshold  float64  `json:"similarity_threshold,omitempty"`   // Minimum cosine similarity (default 0.85)
+	MaxSimilar           int      `json:"max_similar,omitempty"`            // Similar blocks linked per new block (default 5)
+	FileOverlapThreshold float64  `json:"file_overlap_threshold,omitempty"` // Minimum share of files in common (default 0.3)
+}
+
+// Uses reports whether the options enable a signal
+func (o InferenceOptions) Uses(signal string) bool {
+	if o.Disabled {
+		return false
+	}
+	if len(o.Signals) == 0 {
+		return true
+	}
+	for _, s := range o.Signals {
+		if s == signal {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate reports invalid inference options
+func (o InferenceOptions) Validate() error {
+	for _, s := range o.Signals {
+		valid := false
+		for _, known := range InferenceSignals {
+			valid = valid || s == known
+		}
+		if !valid {
+			return fmt.Errorf("unknown inference signal %q (want one of %v)", s, InferenceSignals)
+		}
+	}
+	if o.SimilarityThreshold < 0 || o.SimilarityThreshold > 1 {
+		return fmt.Errorf("similarity_threshold must be between 0 and 1")
+	}
+	if o.FileOverlapThreshold < 0 || o.FileOverlapThreshold > 1 {
+		return fmt.Errorf("file_overlap_threshold must be between 0 and 1")
+	}
+	if o.MaxSimilar < 0 {
+		return fmt.Errorf("max_similar must not be negative")
+	}
+	return nil
+}
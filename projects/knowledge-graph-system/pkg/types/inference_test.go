@@ -0,0 +1,30 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInferenceOptions_Uses(t *testing.T) {
+	assert.True(t, InferenceOptions{}.Uses(InferSimilarity))
+	assert.False(t, InferenceOptions{Disabled: true}.Uses(InferSimilarity))
+
+	only := InferenceOptions{Signals: []string{InferContinuation}}
+	assert.True(t, only.Uses(InferContinuation))
+	assert.False(t, only.Uses(InferReferences))
+}
+
+func TestInferenceOptions_Validate(t *testing.T) {
+	assert.NoError(t, InferenceOptions{}.Validate())
+	assert.NoError(t, InferenceOptions{Signals: InferenceSignals, SimilarityThreshold: 0.9, MaxSimilar: 3}.Validate())
+
+	for _, bad := range []InferenceOptions{
+		{Signals: []string{"vibes"}},
+		{SimilarityThreshold: 1.5},
+		{FileOverlapThreshold: -0.1},
+		{MaxSimilar: -1},
+	} {
+		assert.Error(t, bad.Validate(), "%+v", bad)
+	}
+}
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Ranking strategies for RankingOptions.Strategy
+const (
+	RankingRRF    = "rrf"    // Reciprocal rank fusion of the per-signal rankings (default)
+	RankingLinear = "linear" // Weighted sum of the per-signal scores
+)
+
+// DefaultRRFK is the rank offset used by reciprocal rank fusion unless
+// RankingOptions.RRFK is set
+const DefaultRRFK = 60
+
+// RankingOptions configures how Search combines its signals (see SearchScores)
+// into a result's relevance. The zero value fuses all signals equally with RRF.
+//
+// Relevance is normalized to 0-1 (1 = ranked first by every signal for RRF, a
+// perfect score on every signal for linear) before recency decay and project
+// boosts are applied, so a boosted result can exceed 1. MinRelevance is
+// compared against the final value.
+type RankingOptions struct {
+	Strategy            string                `json:"strategy,omitempty"`               // RankingRRF or RankingLinear
+	RRFK                float64               `json:"rrf_k,omitempty"`                  // RRF rank offset (default 60)
+	Weights             SignalWeights         `json:"weights"`                          // All zero = every signal weighs 1
+	RecencyHalfLifeDays float64               `json:"recency_half_life_days,omitempty"` // Relevance halves every this many days since creation (0 = no decay)
+	ProjectBoosts       map[uuid.UUID]float64 `json:"project_boosts,omitempty"`         // Relevance multiplier per project
+}
+
+// SignalWeights weighs each search signal in the fused relevance. A zero
+// weight ignores the signal.
+type SignalWeights struct {
+	BlockSimilarity    float64 `json:"block_similarity"`
+	TopicRank          float64 `json:"topic_rank"`
+	ExchangeSimilarity float64 `json:"exchange_similarity"`
+	ExchangeRank       float64 `json:"exchange_rank"`
+}
+
+// IsZero reports whether no weight is set
+func (w SignalWeights) IsZero() bool {
+	return w == SignalWeights{}
+}
+
+// Validate reports invalid ranking options
+func (r RankingOptions) Validate() error {
+	switch r.Strategy {
+	case "", RankingRRF, RankingLinear:
+	default:
+		return fmt.Errorf("unknown ranking strategy %q (want %s or %s)", r.Strategy, RankingRRF, RankingLinear)
+	}
+	if r.RRFK < 0 {
+		return fmt.Errorf("rrf_k must not be negative")
+	}
+	w := r.Weights
+	if w.BlockSimilarity < 0 || w.TopicRank < 0 || w.ExchangeSimilarity < 0 || w.ExchangeRank < 0 {
+		return fmt.Errorf("signal weights must not be negative")
+	}
+	if r.RecencyHalfLifeDays < 0 {
+		return fmt.Errorf("recency_half_life_days must not be negative")
+	}
+	for project, boost := range r.ProjectBoosts {
+		if boost <= 0 {
+			return fmt.Errorf("boost for project %s must be positive", project)
+		}
+	}
+	return nil
+}
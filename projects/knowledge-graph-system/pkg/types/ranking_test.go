@@ -0,0 +1,23 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankingOptions_Validate(t *testing.T) {
+	assert.NoError(t, RankingOptions{}.Validate())
+	assert.NoError(t, RankingOptions{Strategy: RankingLinear, RecencyHalfLifeDays: 90}.Validate())
+
+	for _, bad := range []RankingOptions{
+		{Strategy: "bm25"},
+		{RRFK: -1},
+		{Weights: SignalWeights{TopicRank: -1}},
+		{RecencyHalfLifeDays: -7},
+		{ProjectBoosts: map[uuid.UUID]float64{uuid.New(): 0}},
+	} {
+		assert.Error(t, bad.Validate(), "%+v", bad)
+	}
+}
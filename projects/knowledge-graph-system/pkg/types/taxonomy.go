@@ -0,0 +1,24 @@
+package types
+
+// TagInfo is a tag with its place in the tag taxonomy and how often it is used
+type TagInfo struct {
+	Tag
+	Parent     string   `json:"parent,omitempty"`   // Broader tag this one narrows
+	Children   []string `json:"children,omitempty"` // Narrower tags
+	Aliases    []string `json:"aliases,omitempty"`  // Other names that resolve to this tag
+	UsageCount int      `json:"usage_count"`        // Blocks with the tag, among those the caller may see
+}
+
+// TagListOptions selects the tags listed by ListTags
+type TagListOptions struct {
+	Prefix string `json:"prefix,omitempty"` // Only tags whose name starts with this
+	Limit  int    `json:"limit,omitempty"`  // Default 100
+	Caller Caller `json:"-"`                // Only tags and usage on blocks the caller may see
+}
+
+// TagMergeResult describes the outcome of merging tags into one
+type TagMergeResult struct {
+	Target         Tag      `json:"target"`
+	Merged         []string `json:"merged"`          // Tags merged into Target, now its aliases
+	BlocksRelinked int      `json:"blocks_relinked"` // Block tags moved to Target
+}
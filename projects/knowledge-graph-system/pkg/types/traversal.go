@@ -0,0 +1,61 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Traversal directions for TraversalOptions.Direction
+const (
+	DirectionBoth     = "both"     // Follow edges either way (default)
+	DirectionOutgoing = "outgoing" // Only from a block to the blocks it points at
+	DirectionIncoming = "incoming" // Only from a block to the blocks pointing at it
+)
+
+// TraversalOptions configures a multi-hop walk of the relationship graph. The
+// filters select which reached blocks are returned; the walk passes through
+// other blocks, but never through blocks the caller may not see.
+type TraversalOptions struct {
+	MaxHops           int        `json:"max_hops,omitempty"`           // Default 2, at most 5
+	MaxNodes          int        `json:"max_nodes,omitempty"`          // Node budget (default 50, at most 500)
+	Direction         string     `json:"direction,omitempty"`          // DirectionBoth, DirectionOutgoing or DirectionIncoming
+	RelationshipTypes []string   `json:"relationship_types,omitempty"` // Only follow these edge types (empty = all)
+	Tags              []string   `json:"tags,omitempty"`               // Only return blocks with one of these tags (or an alias or narrower tag)
+	ProjectID         *uuid.UUID `json:"project_id,omitempty"`         // Only return blocks in this project
+	Visibility        []string   `json:"visibility,omitempty"`         // Only return blocks with one of these visibilities
+	Caller            Caller     `json:"-"`                            // Who is traversing; only blocks they may see are visited
+}
+
+// Validate reports invalid traversal options
+func (o TraversalOptions) Validate() error {
+	if o.MaxHops < 0 || o.MaxNodes < 0 {
+		return fmt.Errorf("max_hops and max_nodes must not be negative")
+	}
+	switch o.Direction {
+	case "", DirectionBoth, DirectionOutgoing, DirectionIncoming:
+	default:
+		return fmt.Errorf("unknown direction %q (want %s, %s or %s)", o.Direction, DirectionBoth, DirectionOutgoing, DirectionIncoming)
+	}
+	for _, t := range o.RelationshipTypes {
+		if !ValidRelationshipType(t) {
+			return fmt.Errorf("unknown relationship type %q (want one of %v)", t, RelationshipTypes)
+		}
+	}
+	return nil
+}
+
+// TraversalNode is a block reached by a traversal, with the shortest path to it
+type TraversalNode struct {
+	Block      *Block         `json:"block"`
+	Depth      int            `json:"depth"`      // Hops from the root
+	Confidence float64        `json:"confidence"` // Product of the edge confidences along the path
+	Path       []Relationship `json:"path"`       // Edges from the root to the block, in order
+}
+
+// TraversalResult is the N+k context of a block
+type TraversalResult struct {
+	Root      *Block          `json:"root"`
+	Nodes     []TraversalNode `json:"nodes"`     // Nearest first, then most confident
+	Truncated bool            `json:"truncated"` // The node budget was reached
+}
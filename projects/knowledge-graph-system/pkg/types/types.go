@@ -18,25 +18,27 @@ type Project struct {
 
 // Block represents a conversation block (topic + 3-5 exchanges)
 type Block struct {
-	ID            uuid.UUID      `json:"id"`
-	ProjectID     uuid.UUID      `json:"project_id"`
-	Topic         string         `json:"topic"`
-	StartedAt     time.Time      `json:"started_at"`
-	CompletedAt   *time.Time     `json:"completed_at,omitempty"`
-	ExchangeCount int            `json:"exchange_count"`
-	Embedding     pgvector.Vector `json:"-"` // 384-dim vector from nomic-embed-text
+	ID            uuid.UUID              `json:"id"`
+	ProjectID     uuid.UUID              `json:"project_id"`
+	Topic         string                 `json:"topic"`
+	StartedAt     time.Time              `json:"started_at"`
+	CompletedAt   *time.Time             `json:"completed_at,omitempty"`
+	ExchangeCount int                    `json:"exchange_count"`
+	Embedding     pgvector.Vector        `json:"-"` // 384-dim vector from nomic-embed-text
 	Metadata      map[string]interface{} `json:"metadata,omitempty"`
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
 
 	// Visibility and attribution (Week 1.5+)
-	Visibility        string     `json:"visibility,omitempty"`        // "public", "org-private", "individual"
+	Visibility        string     `json:"visibility,omitempty"` // "public", "anonymized", "org-private", "individual"
 	OrganizationID    *uuid.UUID `json:"organization_id,omitempty"`
-	SourceURL         string     `json:"source_url,omitempty"`        // For attribution (web sources)
+	SourceURL         string     `json:"source_url,omitempty"`         // For attribution (web sources)
 	SourceAttribution string     `json:"source_attribution,omitempty"` // Citation text
-	SourceFile        string     `json:"source_file,omitempty"`       // Import source file
-	SourceType        string     `json:"source_type,omitempty"`       // "conversation-log", "spec", etc.
-	SourceHash        string     `json:"source_hash,omitempty"`       // File hash for deduplication
+	SourceFile        string     `json:"source_file,omitempty"`        // Import source file
+	SourceType        string     `json:"source_type,omitempty"`        // "conversation-log", "spec", etc.
+	SourceHash        string     `json:"source_hash,omitempty"`        // File hash for deduplication
+	OwnerID           string     `json:"owner_id,omitempty"`           // User who imported the block (sees it when individual)
+	Team              string     `json:"team,omitempty"`               // Narrows org-private to one team ("" = whole organization)
 
 	// Relations (not stored in DB, populated by queries)
 	Exchanges     []Exchange     `json:"exchanges,omitempty"`
@@ -63,6 +65,12 @@ type Tag struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// ExtractedTag is a tag proposed for content, with how confident the extractor is
+type ExtractedTag struct {
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"` // 0-1, stored as block_tags.confidence
+}
+
 // BlockTag represents the many-to-many relationship between blocks and tags
 type BlockTag struct {
 	BlockID    uuid.UUID `json:"block_id"`
@@ -80,19 +88,77 @@ type Relationship struct {
 	CreatedAt        time.Time `json:"created_at"`
 }
 
+// Relationship types
+const (
+	RelationshipDerivedFrom = "derived-from" // From was derived from To
+	RelationshipImplements  = "implements"   // From implements what To describes (e.g. a commit and its session)
+	RelationshipSupersedes  = "supersedes"   // From replaces To
+	RelationshipRelatedTo   = "related-to"   // Untyped association
+	RelationshipContinues   = "continues"    // From is the next chunk of the session To belongs to
+	RelationshipReferences  = "references"   // From mentions To by its block ID
+)
+
+// RelationshipTypes lists the valid relationship types
+var RelationshipTypes = []string{
+	RelationshipDerivedFrom, RelationshipImplements, RelationshipSupersedes,
+	RelationshipRelatedTo, RelationshipContinues, RelationshipReferences,
+}
+
+// ValidRelationshipType reports whether t is one of RelationshipTypes
+func ValidRelationshipType(t string) bool {
+	for _, valid := range RelationshipTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Edge is a relationship of a block together with the block at its other end
+type Edge struct {
+	Relationship
+	Direction string `json:"direction"` // "outgoing" (the block is From) or "incoming"
+	Block     *Block `json:"block"`     // The other end
+}
+
 // SearchOptions configures search behavior
 type SearchOptions struct {
-	ProjectID    *uuid.UUID `json:"project_id,omitempty"`    // Filter to specific project
-	Limit        int        `json:"limit"`                    // Max results (default 10)
-	MinRelevance float64    `json:"min_relevance,omitempty"` // Minimum relevance score (0-1)
-	IncludeNPlus bool       `json:"include_n_plus"`          // Include N+1 relationships
+	ProjectID    *uuid.UUID     `json:"project_id,omitempty"`    // Filter to specific project
+	Limit        int            `json:"limit"`                   // Max results (default 10)
+	MinRelevance float64        `json:"min_relevance,omitempty"` // Minimum relevance score (0-1)
+	IncludeNPlus bool           `json:"include_n_plus"`          // Include N+1 relationships
+	Ranking      RankingOptions `json:"ranking"`                 // How signals are combined into relevance
+	Tags         []string       `json:"tags,omitempty"`          // Only blocks with one of these tags (or an alias or narrower tag)
+	Caller       Caller         `json:"-"`                       // Who is searching; only blocks they may see are returned
 }
 
 // SearchResult represents a single search result with relevance
 type SearchResult struct {
-	Block     *Block    `json:"block"`
-	Relevance float64   `json:"relevance"` // Similarity score (0-1, higher is better)
-	Related   []*Block  `json:"related,omitempty"` // N+1: one hop away
+	Block     *Block          `json:"block"`
+	Relevance float64         `json:"relevance"`         // Combined score (higher is better)
+	Scores    SearchScores    `json:"scores"`            // Relevance broken down by signal
+	Matches   []ExchangeMatch `json:"matches,omitempty"` // Exchanges that matched, best first
+	Related   []*Block        `json:"related,omitempty"` // N+1: one hop away
+}
+
+// SearchScores are the per-signal scores behind a result's relevance (0 when
+// the signal did not match the block)
+type SearchScores struct {
+	BlockSimilarity    float64 `json:"block_similarity"`    // Query vs block embedding (topic + first question)
+	TopicRank          float64 `json:"topic_rank"`          // Full-text rank of the topic
+	ExchangeSimilarity float64 `json:"exchange_similarity"` // Best query vs exchange embedding
+	ExchangeRank       float64 `json:"exchange_rank"`       // Best full-text rank of an exchange's question and answer
+}
+
+// ExchangeMatch is an exchange that matched a search, with the matched query
+// terms highlighted (**like this**) in snippets of its question and answer
+type ExchangeMatch struct {
+	ExchangeID      uuid.UUID `json:"exchange_id"`
+	Sequence        int       `json:"sequence"`
+	Similarity      float64   `json:"similarity"`
+	Rank            float64   `json:"rank"`
+	QuestionSnippet string    `json:"question_snippet,omitempty"`
+	AnswerSnippet   string    `json:"answer_snippet,omitempty"`
 }
 
 // SearchResults represents the complete search response
@@ -104,7 +170,8 @@ type SearchResults struct {
 
 // ContextBundle represents N+1 context for a block
 type ContextBundle struct {
-	PrimaryBlock *Block   `json:"primary_block"`
-	RelatedBlocks []*Block `json:"related_blocks"` // One hop away via tags/relationships
-	Tags         []Tag    `json:"tags"`
+	PrimaryBlock  *Block   `json:"primary_block"`
+	RelatedBlocks []*Block `json:"related_blocks"` // One hop away via shared tags
+	Edges         []Edge   `json:"edges"`          // One hop away via relationships, most confident first
+	Tags          []Tag    `json:"tags"`
 }
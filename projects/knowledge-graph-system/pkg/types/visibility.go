@@ -0,0 +1,45 @@
+package types
+
+import "github.com/google/uuid"
+
+// Caller identifies who is reading the knowledge graph. Search and block
+// lookups only return the blocks the caller may see (see CanView).
+type Caller struct {
+	UserID         string     `json:"user_id,omitempty"`
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
+	Teams          []string   `json:"teams,omitempty"`
+}
+
+// CanView reports whether the caller may read block:
+//   - public and anonymized blocks are visible to everyone
+//   - org-private blocks to members of the block's organization, and of its
+//     team when it has one
+//   - individual blocks only to their owner, within the block's organization
+//
+// Blocks without a visibility are org-private (the column default). Any other
+// visibility is visible to no one.
+func (c Caller) CanView(block *Block) bool {
+	switch block.Visibility {
+	case "public", "anonymized":
+		return true
+	case "", "org-private":
+		return c.inOrganization(block) && (block.Team == "" || c.inTeam(block.Team))
+	case "individual":
+		return c.inOrganization(block) && c.UserID != "" && block.OwnerID == c.UserID
+	default:
+		return false
+	}
+}
+
+func (c Caller) inOrganization(block *Block) bool {
+	return c.OrganizationID != nil && block.OrganizationID != nil && *c.OrganizationID == *block.OrganizationID
+}
+
+func (c Caller) inTeam(team string) bool {
+	for _, t := range c.Teams {
+		if t == team {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallerCanView(t *testing.T) {
+	acme, globex := uuid.New(), uuid.New()
+
+	block := func(visibility string, org uuid.UUID, owner, team string) *Block {
+		return &Block{Visibility: visibility, OrganizationID: &org, OwnerID: owner, Team: team}
+	}
+
+	alice := Caller{UserID: "alice", OrganizationID: &acme, Teams: []string{"platform"}}
+	bob := Caller{UserID: "bob", OrganizationID: &acme}
+	mallory := Caller{UserID: "alice", OrganizationID: &globex, Teams: []string{"platform"}} // Same names, other org
+	anonymous := Caller{}
+
+	tests := []struct {
+		name    string
+		block   *Block
+		allowed []Caller
+		denied  []Caller
+	}{
+		{"public", block("public", acme, "alice", ""), []Caller{alice, bob, mallory, anonymous}, nil},
+		{"anonymized", block("anonymized", acme, "alice", ""), []Caller{alice, bob, mallory, anonymous}, nil},
+		{"org-private", block("org-private", acme, "alice", ""), []Caller{alice, bob}, []Caller{mallory, anonymous}},
+		{"default visibility is org-private", block("", acme, "", ""), []Caller{alice, bob}, []Caller{mallory, anonymous}},
+		{"team", block("org-private", acme, "", "platform"), []Caller{alice}, []Caller{bob, mallory, anonymous}},
+		{"individual", block("individual", acme, "alice", ""), []Caller{alice}, []Caller{bob, mallory, anonymous}},
+		{"individual without owner", block("individual", acme, "", ""), nil, []Caller{alice, bob, mallory, anonymous}},
+		{"unknown visibility", block("secret", acme, "alice", ""), nil, []Caller{alice, bob, mallory, anonymous}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, c := range tt.allowed {
+				assert.True(t, c.CanView(tt.block), "%+v should see the block", c)
+			}
+			for _, c := range tt.denied {
+				assert.False(t, c.CanView(tt.block), "%+v should not see the block", c)
+			}
+		})
+	}
+
+	assert.False(t, alice.CanView(&Block{Visibility: "org-private"}), "a block without an organization is not org-visible")
+	assert.False(t, Caller{}.CanView(&Block{Visibility: "individual"}), "an empty user owns nothing")
+}
@@ -56,6 +56,16 @@ CREATE TABLE IF NOT EXISTS block_tags (
     PRIMARY KEY (block_id, tag_id)
 );
 
+-- Tag taxonomy: a tag may narrow a broader parent tag (postgres-indexing under
+-- postgres), and aliases resolve other names (postgresql, pg) to a tag
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES tags(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS tag_aliases (
+    alias VARCHAR(100) PRIMARY KEY,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Block-to-block relationships (graph)
 CREATE TABLE IF NOT EXISTS block_relationships (
     from_block_id UUID REFERENCES blocks(id) ON DELETE CASCADE,
@@ -74,8 +84,11 @@ CREATE INDEX IF NOT EXISTS idx_blocks_embedding ON blocks USING hnsw (embedding
 
 CREATE INDEX IF NOT EXISTS idx_exchanges_block ON exchanges(block_id, sequence);
 CREATE INDEX IF NOT EXISTS idx_exchanges_timestamp ON exchanges(timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_exchanges_embedding ON exchanges USING hnsw (embedding vector_cosine_ops);
 
 CREATE INDEX IF NOT EXISTS idx_tags_name ON tags(name);
+CREATE INDEX IF NOT EXISTS idx_tags_parent ON tags(parent_id);
+CREATE INDEX IF NOT EXISTS idx_tag_aliases_tag ON tag_aliases(tag_id);
 
 CREATE INDEX IF NOT EXISTS idx_block_tags_block ON block_tags(block_id);
 CREATE INDEX IF NOT EXISTS idx_block_tags_tag ON block_tags(tag_id);
@@ -83,6 +96,10 @@ CREATE INDEX IF NOT EXISTS idx_block_tags_tag ON block_tags(tag_id);
 CREATE INDEX IF NOT EXISTS idx_block_relationships_from ON block_relationships(from_block_id);
 CREATE INDEX IF NOT EXISTS idx_block_relationships_to ON block_relationships(to_block_id);
 
+-- Relationship inference looks up the chunks of a session and the sessions that modified a file
+CREATE INDEX IF NOT EXISTS idx_blocks_session ON blocks((metadata->>'session_id'));
+CREATE INDEX IF NOT EXISTS idx_blocks_files_modified ON blocks USING gin((metadata->'files_modified'));
+
 -- Full-text search support
 CREATE INDEX IF NOT EXISTS idx_exchanges_fts ON exchanges
     USING gin(to_tsvector('english', question || ' ' || answer));
@@ -126,6 +143,11 @@ ALTER TABLE blocks ADD COLUMN IF NOT EXISTS visibility TEXT DEFAULT 'org-private
 
 ALTER TABLE blocks ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES organizations(id) DEFAULT (SELECT id FROM organizations WHERE name = 'personal');
 
+-- Who may read non-public blocks: individual blocks only their owner (the user
+-- who imported them), org-private blocks with a team only that team's members
+ALTER TABLE blocks ADD COLUMN IF NOT EXISTS owner_id TEXT;
+ALTER TABLE blocks ADD COLUMN IF NOT EXISTS team TEXT;
+
 -- Source attribution for blocks (public knowledge)
 ALTER TABLE blocks ADD COLUMN IF NOT EXISTS source_url TEXT; -- Original source URL
 ALTER TABLE blocks ADD COLUMN IF NOT EXISTS source_attribution TEXT; -- Citation text
@@ -139,7 +161,7 @@ CREATE TABLE IF NOT EXISTS import_history (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     block_count INT NOT NULL,
     import_type TEXT NOT NULL, -- "conversation-log", "spec", "doc", "working-file"
-    status TEXT DEFAULT 'completed', -- "in-progress", "completed", "failed"
+    status TEXT DEFAULT 'completed', -- "in-progress", "completed", "failed", "superseded"
     error_message TEXT,
 
     -- Visibility and organization tracking
@@ -156,6 +178,9 @@ ALTER TABLE blocks ADD COLUMN IF NOT EXISTS source_type TEXT; -- "conversation-l
 ALTER TABLE blocks ADD COLUMN IF NOT EXISTS source_hash TEXT;
 ALTER TABLE blocks ADD COLUMN IF NOT EXISTS import_batch_id UUID REFERENCES import_history(id) ON DELETE SET NULL;
 
+-- Bytes of the file covered by an import (lets growing conversation logs be detected as appends)
+ALTER TABLE import_history ADD COLUMN IF NOT EXISTS file_size BIGINT DEFAULT 0;
+
 -- Public knowledge pool (future - Week 2+)
 CREATE TABLE IF NOT EXISTS public_knowledge (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),